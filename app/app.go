@@ -130,7 +130,7 @@ func RunService() {
 		AllowMethods:    []string{"GET", "POST", "PUT", "OPTIONS", "DELETE"},
 		AllowHeaders: []string{
 			"Origin", "Content-Type",
-			"Authorization"},
+			"Authorization", "Idempotency-Key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           120 * time.Second,
@@ -1,5 +1,17 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+CREATE TABLE account_tiers (
+    name VARCHAR(20) PRIMARY KEY,
+    max_balance DECIMAL(15, 2),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO account_tiers (name, max_balance)
+VALUES
+    ('unverified', 2000000),
+    ('verified', 20000000);
+
 CREATE TABLE users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     fullname VARCHAR(50) NOT NULL,
@@ -12,23 +24,63 @@ CREATE TABLE users (
     phone_number VARCHAR(17) NOT NULL UNIQUE,
     roles VARCHAR(25) NOT NULL,
     status VARCHAR(20) NOT NULL DEFAULT 'inactive',
+    tier VARCHAR(20) NOT NULL DEFAULT 'unverified' REFERENCES account_tiers(name),
+    pin_failed_attempts INT NOT NULL DEFAULT 0,
+    pin_locked_until TIMESTAMP WITHOUT TIME ZONE,
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITHOUT TIME ZONE
 );
 
+-- A user's wallets are their pockets. The main pocket is the one transfers,
+-- top-ups and payments use; the others are named sub-wallets the user moves
+-- money into. Closing a pocket sets deleted_at.
+-- Amounts are in the smallest unit of the wallet's currency: whole rupiah for
+-- IDR, cents for USD and SGD. Main pockets are always IDR.
+-- status is set by compliance: frozen_debit wallets can only receive money,
+-- frozen_all wallets can neither send nor receive. status_reason explains the
+-- latest change; wallet_status_changes keeps the full history.
 CREATE TABLE wallets (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID REFERENCES users(id),
+    name VARCHAR(50) NOT NULL DEFAULT 'Main',
+    is_main BOOLEAN NOT NULL DEFAULT TRUE,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR' CHECK (currency IN ('IDR', 'USD', 'SGD')),
     balance DECIMAL(15, 2) DEFAULT 0.00 CHECK (balance >= 0),
+    status VARCHAR(15) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'frozen_debit', 'frozen_all')),
+    status_reason VARCHAR(255),
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP WITHOUT TIME ZONE
+    deleted_at TIMESTAMP WITHOUT TIME ZONE,
+    CHECK (NOT is_main OR currency = 'IDR')
+);
+
+CREATE TABLE wallet_status_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    from_status VARCHAR(15) NOT NULL,
+    to_status VARCHAR(15) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    changed_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- gateway_code is the method's identifier at the payment gateway.
+-- gateway_config holds each payment gateway's settings for the method,
+-- keyed by gateway name, e.g. {"midtrans": {"snap_path": "#/other-qris"}}.
+-- Top-ups outside min_amount..max_amount are refused; NULL means no bound.
 CREATE TABLE payment_method (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     payment_name VARCHAR(50) NOT NULL,
+    gateway_code VARCHAR(50) NOT NULL DEFAULT '',
+    channel_type VARCHAR(10) CHECK (channel_type IN ('va', 'ewallet', 'retail', 'card', 'paylater')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    min_amount DECIMAL(15, 2) CHECK (min_amount > 0),
+    max_amount DECIMAL(15, 2) CHECK (max_amount >= min_amount),
+    expiry_minutes INT NOT NULL DEFAULT 1440 CHECK (expiry_minutes > 0),
+    display_order INT NOT NULL DEFAULT 0,
+    icon_url VARCHAR(255),
+    gateway_config JSONB NOT NULL DEFAULT '{}',
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITHOUT TIME ZONE
@@ -37,6 +89,8 @@ CREATE TABLE payment_method (
 CREATE TABLE merchant (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     merchant_name VARCHAR(50) NOT NULL,
+    owner_id UUID UNIQUE REFERENCES users(id),
+    wallet_id UUID UNIQUE REFERENCES wallets(id),
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITHOUT TIME ZONE
@@ -46,9 +100,14 @@ CREATE TABLE transactions (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL REFERENCES users(id),
     transaction_type VARCHAR(10) NOT NULL CHECK (transaction_type IN ('debit', 'credit')),
+    currency CHAR(3) NOT NULL DEFAULT 'IDR' CHECK (currency IN ('IDR', 'USD', 'SGD')),
     amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
     description VARCHAR(100),
     status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    reversal_of UUID REFERENCES transactions(id),
+    reversal_reason VARCHAR(255),
+    reversed_by UUID REFERENCES users(id),
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -65,36 +124,310 @@ CREATE TABLE topup_transactions (
     transaction_id UUID REFERENCES transactions(id),
     payment_method_id UUID REFERENCES payment_method(id),
     payment_url VARCHAR(255),
+    status_checked_at TIMESTAMP WITHOUT TIME ZONE,
+    settled_at TIMESTAMP WITHOUT TIME ZONE,
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE bank_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    bank_code VARCHAR(20) NOT NULL,
+    account_number VARCHAR(30) NOT NULL,
+    account_name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+-- The amount and fee sit in the withdrawal's hold account until the
+-- provider reports the payout completed or failed.
+CREATE TABLE withdrawals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    bank_account_id UUID NOT NULL REFERENCES bank_accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    provider VARCHAR(20) NOT NULL,
+    reference_no VARCHAR(64),
+    status VARCHAR(15) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    failure_reason VARCHAR(255),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One batch per merchant and business day. Refunds are reversals booked on
+-- that day; fee is the merchant discount rate taken from the payout.
+CREATE TABLE settlement_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchant(id),
+    business_date DATE NOT NULL,
+    transaction_count INT NOT NULL,
+    gross_amount DECIMAL(15, 2) NOT NULL,
+    refund_amount DECIMAL(15, 2) NOT NULL,
+    fee_amount DECIMAL(15, 2) NOT NULL,
+    net_amount DECIMAL(15, 2) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved')),
+    transaction_id UUID REFERENCES transactions(id),
+    approved_by UUID REFERENCES users(id),
+    approved_at TIMESTAMP WITHOUT TIME ZONE,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, business_date)
+);
+
 CREATE TABLE merchant_transactions (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     transaction_id UUID REFERENCES transactions(id),
     merchant_id UUID REFERENCES merchant(id),
+    bill_reference VARCHAR(25),
+    settlement_batch_id UUID REFERENCES settlement_batches(id),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Keys partner merchants sign their requests with. A rotated-out key keeps
+-- working until expires_at; a revoked key stops at once.
+CREATE TABLE merchant_api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchant(id),
+    key_id VARCHAR(40) NOT NULL UNIQUE,
+    secret VARCHAR(80) NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE merchant_charges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchant(id),
+    api_key_id UUID NOT NULL REFERENCES merchant_api_keys(id),
+    customer_id UUID NOT NULL REFERENCES users(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    reference VARCHAR(50) NOT NULL,
+    description VARCHAR(100),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'declined')),
+    transaction_id UUID REFERENCES transactions(id),
+    callback_url VARCHAR(255),
+    callback_status VARCHAR(10) CHECK (callback_status IN ('pending', 'delivered', 'failed')),
+    callback_attempts INT NOT NULL DEFAULT 0,
+    callback_next_at TIMESTAMP WITHOUT TIME ZONE,
+    callback_error VARCHAR(255),
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITHOUT TIME ZONE,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, reference)
+);
+
+-- One upload of the gateway's settlement report. Items are the lines that
+-- did not match a local top-up, kept until an admin resolves them.
+CREATE TABLE reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name VARCHAR(255) NOT NULL,
+    report_date DATE NOT NULL,
+    total_lines INT NOT NULL,
+    matched_count INT NOT NULL,
+    issue_count INT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE reconciliation_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id),
+    order_id VARCHAR(100) NOT NULL,
+    issue_type VARCHAR(20) NOT NULL CHECK (issue_type IN ('missing_locally', 'missing_in_report', 'amount_mismatch', 'status_mismatch')),
+    report_amount DECIMAL(15, 2),
+    local_amount DECIMAL(15, 2),
+    report_status VARCHAR(20),
+    local_status VARCHAR(10),
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP WITHOUT TIME ZONE,
+    resolution_note VARCHAR(255),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- fx_rates is the admin-maintained rate table: one unit of base_currency is
+-- worth rate units of quote_currency. The inverse pair is derived when only
+-- one direction is stored.
+CREATE TABLE fx_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL CHECK (rate > 0),
+    updated_by UUID REFERENCES users(id),
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (base_currency, quote_currency),
+    CHECK (base_currency <> quote_currency)
+);
+
+-- fx_quotes locks a conversion rate for a short window. transaction_id is set
+-- once the quote is confirmed, so each quote converts at most once.
+CREATE TABLE fx_quotes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    from_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    to_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(24, 10) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    converted_amount DECIMAL(15, 2) NOT NULL CHECK (converted_amount > 0),
+    provider VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('wallet', 'merchant', 'payment_gateway', 'fee_revenue', 'settlement', 'hold', 'disbursement', 'fx')),
+    account_id VARCHAR(64) NOT NULL,
+    entry_type VARCHAR(10) NOT NULL CHECK (entry_type IN ('debit', 'credit')),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    balance_after DECIMAL(15, 2),
     created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE transaction_limits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tier VARCHAR(20) NOT NULL REFERENCES account_tiers(name) ON DELETE CASCADE,
+    transaction_type VARCHAR(20) NOT NULL CHECK (transaction_type IN ('topup', 'transfer', 'merchant_payment', 'withdrawal')),
+    per_transaction DECIMAL(15, 2),
+    daily DECIMAL(15, 2),
+    monthly DECIMAL(15, 2),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tier, transaction_type)
+);
+
+CREATE TABLE fee_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_type VARCHAR(20) NOT NULL CHECK (transaction_type IN ('topup', 'transfer', 'merchant_payment', 'merchant_settlement', 'withdrawal')),
+    payment_method_id UUID REFERENCES payment_method(id),
+    fee_type VARCHAR(10) NOT NULL CHECK (fee_type IN ('flat', 'percentage')),
+    flat_amount DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (flat_amount >= 0),
+    percentage_bps INT NOT NULL DEFAULT 0 CHECK (percentage_bps BETWEEN 0 AND 10000),
+    min_fee DECIMAL(15, 2),
+    max_fee DECIMAL(15, 2),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE scheduled_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    recipient_phone_number VARCHAR(17) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    description VARCHAR(100),
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('once', 'weekly', 'monthly')),
+    start_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITHOUT TIME ZONE,
+    next_run_at TIMESTAMP WITHOUT TIME ZONE,
+    retry_at TIMESTAMP WITHOUT TIME ZONE,
+    retry_count INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITHOUT TIME ZONE,
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'completed', 'cancelled')),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE scheduled_transfer_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    scheduled_transfer_id UUID NOT NULL REFERENCES scheduled_transfers(id),
+    transaction_id UUID REFERENCES transactions(id),
+    occurrence_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    attempt INT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('success', 'retrying', 'skipped', 'failed')),
+    message VARCHAR(255),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE split_bills (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organizer_id UUID NOT NULL REFERENCES users(id),
+    title VARCHAR(100) NOT NULL,
+    total_amount DECIMAL(15, 2) NOT NULL CHECK (total_amount > 0),
+    organizer_share DECIMAL(15, 2) NOT NULL CHECK (organizer_share >= 0),
+    split_type VARCHAR(10) NOT NULL CHECK (split_type IN ('equal', 'custom')),
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE payment_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    requester_id UUID NOT NULL REFERENCES users(id),
+    payer_id UUID NOT NULL REFERENCES users(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    note VARCHAR(100),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    transaction_id UUID REFERENCES transactions(id),
+    split_bill_id UUID REFERENCES split_bills(id),
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITHOUT TIME ZONE,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (requester_id <> payer_id)
+);
+
+CREATE TABLE idempotency_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INT,
+    response_body TEXT,
+    created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITHOUT TIME ZONE,
+    UNIQUE (user_id, idempotency_key)
+);
+
 CREATE INDEX idx_user_id ON transactions(user_id);
 CREATE INDEX idx_from_wallet_id ON wallet_transactions(from_wallet_id);
 CREATE INDEX idx_to_wallet_id ON wallet_transactions(to_wallet_id);
+CREATE INDEX idx_ledger_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX idx_ledger_account ON ledger_entries(account_type, account_id, created_at);
+CREATE UNIQUE INDEX idx_transactions_reversal_of ON transactions(reversal_of);
+CREATE INDEX idx_scheduled_transfers_due ON scheduled_transfers(next_run_at) WHERE status = 'active';
+CREATE INDEX idx_scheduled_transfer_runs_schedule ON scheduled_transfer_runs(scheduled_transfer_id);
+-- An occurrence is paid at most once, even if two executors race past the lease.
+CREATE UNIQUE INDEX idx_scheduled_transfer_runs_paid ON scheduled_transfer_runs(scheduled_transfer_id, occurrence_at) WHERE status = 'success';
+CREATE UNIQUE INDEX idx_merchant_transactions_bill ON merchant_transactions(merchant_id, bill_reference) WHERE bill_reference IS NOT NULL;
+CREATE INDEX idx_merchant_transactions_unsettled ON merchant_transactions(created_at) WHERE settlement_batch_id IS NULL;
+CREATE INDEX idx_topup_transactions_transaction ON topup_transactions(transaction_id);
+CREATE INDEX idx_topup_transactions_settled ON topup_transactions(settled_at) WHERE settled_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_bank_accounts_user ON bank_accounts(user_id, bank_code, account_number) WHERE deleted_at IS NULL;
+CREATE INDEX idx_withdrawals_user ON withdrawals(user_id, created_at);
+CREATE UNIQUE INDEX idx_withdrawals_reference ON withdrawals(provider, reference_no) WHERE reference_no IS NOT NULL;
+CREATE INDEX idx_settlement_batches_status ON settlement_batches(status, business_date);
+CREATE INDEX idx_merchant_api_keys_merchant ON merchant_api_keys(merchant_id);
+CREATE INDEX idx_merchant_charges_customer ON merchant_charges(customer_id, status);
+CREATE INDEX idx_merchant_charges_callback ON merchant_charges(callback_next_at) WHERE callback_status = 'pending';
+CREATE INDEX idx_payment_requests_payer ON payment_requests(payer_id, status);
+CREATE INDEX idx_payment_requests_requester ON payment_requests(requester_id, status);
+CREATE INDEX idx_payment_requests_split_bill ON payment_requests(split_bill_id);
+CREATE INDEX idx_split_bills_organizer ON split_bills(organizer_id);
+CREATE INDEX idx_reconciliation_items_run ON reconciliation_items(run_id);
+CREATE INDEX idx_fx_quotes_user ON fx_quotes(user_id, created_at);
+CREATE INDEX idx_wallet_status_changes_wallet ON wallet_status_changes(wallet_id, created_at);
+CREATE UNIQUE INDEX idx_wallets_main ON wallets(user_id) WHERE is_main;
+CREATE UNIQUE INDEX idx_wallets_pocket_name ON wallets(user_id, LOWER(name)) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_fee_schedules_active ON fee_schedules(transaction_type, COALESCE(payment_method_id, '00000000-0000-0000-0000-000000000000')) WHERE active;
 
-INSERT INTO payment_method (id, payment_name)
+INSERT INTO payment_method (id, payment_name, gateway_code, channel_type, display_order, gateway_config)
 VALUES
-    ('087f9751-1dfc-474d-bdee-07ce44b1fe7a', 'Mandiri'),
-    ('089e8004-2428-41f9-bf06-856082bb83d3', 'QRIS'),
-    ('0eaad501-e44d-46e2-902a-9325c6c6c5eb', 'Indomaret'),
-    ('0fafc78f-ebbf-421d-bc89-3246ce6198ad', 'CIMB NIaga'),
-    ('220309af-cd3b-40e5-b353-6754c66f3831', 'Kredivo'),
-    ('29690f9f-c6c4-4fda-acac-be91555b1f94', 'Akulaku'),
-    ('2bed0329-499e-43b5-9b99-583b203ea102', 'BNI'),
-    ('3863b99e-9909-486c-8ec1-b7a3162c9f97', 'BRI'),
-    ('76954351-6cb3-496d-8866-d7f5772a04fe', 'Permata Bank'),
-    ('91b75dee-155e-4ac3-9bfd-f8bed82b6189', 'ShopeePay/SPayLater'),
-    ('9fa520e0-d10b-4be1-a6d7-e8b6fc635c5c', 'Debit/CreditCard'),
-    ('b25a226e-82ab-4d29-a68e-6957fb7e21a9', 'Alfa Group'),
-    ('cf51fa64-1686-4fee-a4e1-ea13c939f99b', 'BCA'),
-    ('f9569b06-a389-4685-b3cc-89b13a111214', 'Gopay/GopayLater');
+    ('087f9751-1dfc-474d-bdee-07ce44b1fe7a', 'Mandiri', 'echannel', 'va', 10, '{"midtrans": {"snap_path": "#/bank-transfer/mandiri-va"}}'),
+    ('089e8004-2428-41f9-bf06-856082bb83d3', 'QRIS', 'other_qris', 'ewallet', 1, '{"midtrans": {"snap_path": "#/other-qris"}}'),
+    ('0eaad501-e44d-46e2-902a-9325c6c6c5eb', 'Indomaret', 'indomaret', 'retail', 30, '{"midtrans": {"snap_path": "#/indomaret"}}'),
+    ('0fafc78f-ebbf-421d-bc89-3246ce6198ad', 'CIMB NIaga', 'cimb_va', 'va', 15, '{"midtrans": {"snap_path": "#/bank-transfer/cimb-va"}}'),
+    ('220309af-cd3b-40e5-b353-6754c66f3831', 'Kredivo', 'kredivo', 'paylater', 50, '{"midtrans": {"snap_path": "#/kredivo"}}'),
+    ('29690f9f-c6c4-4fda-acac-be91555b1f94', 'Akulaku', 'akulaku', 'paylater', 51, '{"midtrans": {"snap_path": "#/akulaku"}}'),
+    ('2bed0329-499e-43b5-9b99-583b203ea102', 'BNI', 'bni_va', 'va', 12, '{"midtrans": {"snap_path": "#/bank-transfer/bni-va"}}'),
+    ('3863b99e-9909-486c-8ec1-b7a3162c9f97', 'BRI', 'bri_va', 'va', 13, '{"midtrans": {"snap_path": "#/bank-transfer/bri-va"}}'),
+    ('76954351-6cb3-496d-8866-d7f5772a04fe', 'Permata Bank', 'permata_va', 'va', 14, '{"midtrans": {"snap_path": "#/bank-transfer/permata-va"}}'),
+    ('91b75dee-155e-4ac3-9bfd-f8bed82b6189', 'ShopeePay/SPayLater', 'shopeepay', 'ewallet', 3, '{"midtrans": {"snap_path": "#/shopeepay-qris"}}'),
+    ('9fa520e0-d10b-4be1-a6d7-e8b6fc635c5c', 'Debit/CreditCard', 'credit_card', 'card', 40, '{"midtrans": {"snap_path": "#/credit-card"}}'),
+    ('b25a226e-82ab-4d29-a68e-6957fb7e21a9', 'Alfa Group', 'alfamart', 'retail', 31, '{"midtrans": {"snap_path": "#/alfamart"}}'),
+    ('cf51fa64-1686-4fee-a4e1-ea13c939f99b', 'BCA', 'bca_va', 'va', 11, '{"midtrans": {"snap_path": "#/bank-transfer/bca-va"}}'),
+    ('f9569b06-a389-4685-b3cc-89b13a111214', 'Gopay/GopayLater', 'gopay', 'ewallet', 2, '{"midtrans": {"snap_path": "#/gopay-qris"}}');
 
 INSERT INTO merchant (id, merchant_name)
 VALUES
@@ -120,4 +453,42 @@ VALUES
 INSERT INTO wallets (user_id)
 VALUES
     ('913a9dcf-28cd-4d2a-991b-60bdb3e57687'),
-    ('113cc083-fea8-4fe7-97ed-739464ebe15b');
\ No newline at end of file
+    ('113cc083-fea8-4fe7-97ed-739464ebe15b');
+
+-- Platform revenue wallet, credited with every fee (see pkg/fees).
+INSERT INTO wallets (id, user_id)
+VALUES
+    ('00000000-0000-0000-0000-00000000fee1', NULL);
+
+-- Merchants are paid into a wallet of their own that belongs to no user. The
+-- seeded merchants reuse their id for it.
+INSERT INTO wallets (id, user_id)
+SELECT id, NULL FROM merchant;
+
+UPDATE merchant SET wallet_id = id;
+
+ALTER TABLE merchant ALTER COLUMN wallet_id SET NOT NULL;
+
+INSERT INTO transaction_limits (tier, transaction_type, per_transaction, daily, monthly)
+VALUES
+    ('unverified', 'topup', 2000000, 2000000, 20000000),
+    ('unverified', 'transfer', 1000000, 2000000, 10000000),
+    ('unverified', 'merchant_payment', 1000000, 2000000, 10000000),
+    ('verified', 'topup', 20000000, 20000000, 40000000),
+    ('verified', 'transfer', 10000000, 20000000, 40000000),
+    ('verified', 'merchant_payment', 10000000, 20000000, 40000000),
+    ('unverified', 'withdrawal', 0, 0, 0),
+    ('verified', 'withdrawal', 10000000, 20000000, 40000000);
+
+INSERT INTO fee_schedules (transaction_type, payment_method_id, fee_type, flat_amount, percentage_bps, min_fee, max_fee)
+VALUES
+    ('topup', NULL, 'flat', 1000, 0, NULL, NULL),
+    ('topup', '089e8004-2428-41f9-bf06-856082bb83d3', 'percentage', 0, 70, NULL, NULL),
+    ('topup', '9fa520e0-d10b-4be1-a6d7-e8b6fc635c5c', 'percentage', 0, 290, 2000, NULL),
+    ('merchant_settlement', NULL, 'percentage', 0, 70, NULL, NULL),
+    ('withdrawal', NULL, 'flat', 2500, 0, NULL, NULL);
+
+INSERT INTO fx_rates (base_currency, quote_currency, rate)
+VALUES
+    ('USD', 'IDR', 16250),
+    ('SGD', 'IDR', 12100);
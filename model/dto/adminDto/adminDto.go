@@ -1,6 +1,10 @@
 package adminDto
 
-import "time"
+import (
+	"encoding/json"
+	"final-project-enigma/pkg/money"
+	"time"
+)
 
 type (
 	GetUserParams struct {
@@ -45,22 +49,31 @@ type (
 		Limit       string `json:"limit"`
 	}
 	PaymentMethod struct {
-		ID          string    `json:"id"`
-		PaymentName string    `json:"payment_name"`
-		CreatedAt   time.Time `json:"createdAt"`
-		UpdatedAt   time.Time `json:"updatedAt"`
+		ID            string          `json:"id"`
+		PaymentName   string          `json:"payment_name"`
+		GatewayCode   string          `json:"gateway_code"`
+		ChannelType   string          `json:"channel_type"`
+		Enabled       bool            `json:"enabled"`
+		MinAmount     *money.Money    `json:"min_amount"`
+		MaxAmount     *money.Money    `json:"max_amount"`
+		ExpiryMinutes int             `json:"expiry_minutes"`
+		DisplayOrder  int             `json:"display_order"`
+		IconURL       string          `json:"icon_url"`
+		GatewayConfig json.RawMessage `json:"gateway_config"`
+		CreatedAt     time.Time       `json:"createdAt"`
+		UpdatedAt     time.Time       `json:"updatedAt"`
 	}
 
 	GetWalletParams struct {
-		ID         string   `json:"id"`
-		User_id    string   `json:"user_id"`
-		Fullname   string   `json:"fullname"`
-		Username   string   `json:"username"`
-		MinBalance *float64 `json:"min_balance"`
-		MaxBalance *float64 `json:"max_balance"`
-		CreatedAt  string   `json:"createdAt"`
-		Page       string   `json:"page"`
-		Limit      string   `json:"limit"`
+		ID         string       `json:"id"`
+		User_id    string       `json:"user_id"`
+		Fullname   string       `json:"fullname"`
+		Username   string       `json:"username"`
+		MinBalance *money.Money `json:"min_balance"`
+		MaxBalance *money.Money `json:"max_balance"`
+		CreatedAt  string       `json:"createdAt"`
+		Page       string       `json:"page"`
+		Limit      string       `json:"limit"`
 	}
 	Wallet struct {
 		ID       string `json:"id"`
@@ -69,17 +82,40 @@ type (
 		Username string `json:"username"`
 
 		Balance   string    `json:"balance"`
+		Status    string    `json:"status"`
 		CreatedAt time.Time `json:"createdAt"`
 		UpdatedAt time.Time `json:"updatedAt"`
 	}
 
+	// CreatePaymentMethod leaves a method enabled, without amount bounds
+	// and payable for a day unless told otherwise.
 	CreatePaymentMethod struct {
-		PaymentName string `json:"payment_name" binding:"required,max=255"`
-	}
-
+		PaymentName   string          `json:"payment_name" binding:"required,max=255"`
+		GatewayCode   string          `json:"gateway_code,omitempty" binding:"max=50"`
+		ChannelType   string          `json:"channel_type,omitempty" binding:"omitempty,oneof=va ewallet retail card paylater"`
+		Enabled       *bool           `json:"enabled,omitempty"`
+		MinAmount     *money.Money    `json:"min_amount,omitempty"`
+		MaxAmount     *money.Money    `json:"max_amount,omitempty"`
+		ExpiryMinutes int             `json:"expiry_minutes,omitempty" binding:"min=0"`
+		DisplayOrder  int             `json:"display_order,omitempty"`
+		IconURL       string          `json:"icon_url,omitempty" binding:"omitempty,url,max=255"`
+		GatewayConfig json.RawMessage `json:"gateway_config,omitempty"`
+	}
+
+	// UpdatePaymentRequest changes only the fields that are sent; the ones
+	// left out keep their stored value.
 	UpdatePaymentRequest struct {
-		ID          string `json:"id"`
-		PaymentName string `json:"payment_name" binding:"required,max=255"`
+		ID            string          `json:"id"`
+		PaymentName   *string         `json:"payment_name,omitempty" binding:"omitempty,min=1,max=255"`
+		GatewayCode   *string         `json:"gateway_code,omitempty" binding:"omitempty,max=50"`
+		ChannelType   *string         `json:"channel_type,omitempty" binding:"omitempty,oneof=va ewallet retail card paylater"`
+		Enabled       *bool           `json:"enabled,omitempty"`
+		MinAmount     *money.Money    `json:"min_amount,omitempty"`
+		MaxAmount     *money.Money    `json:"max_amount,omitempty"`
+		ExpiryMinutes *int            `json:"expiry_minutes,omitempty" binding:"omitempty,min=1"`
+		DisplayOrder  *int            `json:"display_order,omitempty"`
+		IconURL       *string         `json:"icon_url,omitempty" binding:"omitempty,url,max=255"`
+		GatewayConfig json.RawMessage `json:"gateway_config,omitempty"`
 	}
 
 	PaymentResponse struct {
@@ -103,7 +139,7 @@ type (
 		Id                string              `json:"id"`
 		UserId            string              `json:"user_id"`
 		TransactionType   string              `json:"transaction_type"`
-		Amount            float64             `json:"amount"`
+		Amount            money.Money         `json:"amount"`
 		Description       string              `json:"description"`
 		Status            string              `json:"status"`
 		Created_at        time.Time           `json:"created_at"`
@@ -171,4 +207,42 @@ type (
 		MerchantName  string `json:"merchantName,omitempty"`
 		ToWalletId    string `json:"toWalletId,omitempty"`
 	}
+
+	ReverseTransactionRequest struct {
+		TransactionId string `json:"-"`
+		AdminId       string `json:"-"`
+		Reason        string `json:"reason" binding:"required,max=255"`
+	}
+
+	ReverseTransactionResponse struct {
+		TransactionId         string `json:"transactionId"`
+		ReversalTransactionId string `json:"reversalTransactionId"`
+		Reason                string `json:"reason"`
+	}
+
+	FreezeWalletRequest struct {
+		Status string `json:"status" binding:"required,oneof=frozen_debit frozen_all"`
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+
+	UnfreezeWalletRequest struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+
+	// WalletStatusRequest moves a wallet to Status; unfreezing is a move back
+	// to active.
+	WalletStatusRequest struct {
+		WalletId string
+		AdminId  string
+		Status   string
+		Reason   string
+	}
+
+	WalletStatus struct {
+		WalletId       string    `json:"walletId"`
+		Status         string    `json:"status"`
+		PreviousStatus string    `json:"previousStatus"`
+		Reason         string    `json:"reason"`
+		UpdatedAt      time.Time `json:"updatedAt"`
+	}
 )
@@ -0,0 +1,80 @@
+package chargeDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+// Expired is never stored; a pending charge past its expires_at is reported
+// with this status.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusDeclined  = "declined"
+	StatusExpired   = "expired"
+
+	CallbackPending   = "pending"
+	CallbackDelivered = "delivered"
+	CallbackFailed    = "failed"
+)
+
+type (
+	CreateChargeRequest struct {
+		MerchantId          string      `json:"-"`
+		ApiKeyId            string      `json:"-"`
+		CustomerPhoneNumber string      `json:"customerPhoneNumber" binding:"required"`
+		Amount              money.Money `json:"amount" binding:"required,min=5"`
+		Reference           string      `json:"reference" binding:"required,max=50"`
+		Description         string      `json:"description" binding:"max=100"`
+		CallbackUrl         string      `json:"callbackUrl" binding:"omitempty,url,max=255"`
+		ExpiresAt           time.Time   `json:"-"`
+	}
+
+	ApproveChargeRequest struct {
+		Id         string `json:"-"`
+		CustomerId string `json:"-"`
+		PIN        string `json:"pin" binding:"required,pin"`
+	}
+
+	Charge struct {
+		Id             string      `json:"id"`
+		MerchantId     string      `json:"merchantId"`
+		MerchantName   string      `json:"merchantName"`
+		Reference      string      `json:"reference"`
+		Amount         money.Money `json:"amount"`
+		Description    string      `json:"description"`
+		Status         string      `json:"status"`
+		TransactionId  string      `json:"transactionId,omitempty"`
+		CallbackStatus string      `json:"callbackStatus,omitempty"`
+		ExpiresAt      time.Time   `json:"expiresAt"`
+		CompletedAt    *time.Time  `json:"completedAt,omitempty"`
+		CreatedAt      time.Time   `json:"createdAt"`
+	}
+
+	// Callback is a charge whose result still has to be posted to the
+	// merchant, signed with the key the charge was created with.
+	Callback struct {
+		ChargeId string
+		Url      string
+		KeyId    string
+		Secret   string
+		Attempts int
+		Body     CallbackBody
+	}
+
+	CallbackBody struct {
+		ChargeId      string      `json:"chargeId"`
+		Reference     string      `json:"reference"`
+		Status        string      `json:"status"`
+		Amount        money.Money `json:"amount"`
+		TransactionId string      `json:"transactionId,omitempty"`
+		CompletedAt   *time.Time  `json:"completedAt,omitempty"`
+	}
+
+	CallbackResult struct {
+		Status   string
+		Attempts int
+		NextAt   *time.Time
+		Error    string
+	}
+)
@@ -0,0 +1,51 @@
+package fxDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+type (
+	// QuoteRequest asks for the rate to convert Amount, in the smallest unit
+	// of the source pocket's currency, into the destination pocket.
+	QuoteRequest struct {
+		UserId       string      `json:"-"`
+		FromPocketId string      `json:"fromPocketId" binding:"required"`
+		ToPocketId   string      `json:"toPocketId" binding:"required"`
+		Amount       money.Money `json:"amount" binding:"required,gt=0"`
+	}
+
+	// Quote is a rate locked until ExpiresAt. Converting it moves Amount out
+	// of the source pocket and ConvertedAmount into the destination pocket;
+	// TransactionId is set once that happened.
+	Quote struct {
+		Id              string      `json:"id"`
+		FromPocketId    string      `json:"fromPocketId"`
+		ToPocketId      string      `json:"toPocketId"`
+		FromCurrency    string      `json:"fromCurrency"`
+		ToCurrency      string      `json:"toCurrency"`
+		Rate            string      `json:"rate"`
+		Amount          money.Money `json:"amount"`
+		ConvertedAmount money.Money `json:"convertedAmount"`
+		Provider        string      `json:"provider"`
+		ExpiresAt       time.Time   `json:"expiresAt"`
+		TransactionId   string      `json:"transactionId,omitempty"`
+		CreatedAt       time.Time   `json:"createdAt"`
+	}
+
+	// SetRateRequest stores what one unit of BaseCurrency is worth in
+	// QuoteCurrency, as a decimal string such as "16250.5".
+	SetRateRequest struct {
+		BaseCurrency  string `json:"baseCurrency" binding:"required,oneof=IDR USD SGD"`
+		QuoteCurrency string `json:"quoteCurrency" binding:"required,oneof=IDR USD SGD"`
+		Rate          string `json:"rate" binding:"required"`
+		UpdatedBy     string `json:"-"`
+	}
+
+	Rate struct {
+		BaseCurrency  string    `json:"baseCurrency"`
+		QuoteCurrency string    `json:"quoteCurrency"`
+		Rate          string    `json:"rate"`
+		UpdatedAt     time.Time `json:"updatedAt"`
+	}
+)
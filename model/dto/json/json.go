@@ -94,3 +94,17 @@ func NewResponseUnauthorized(c *gin.Context, message, serviceCode, errorCode str
 		Message: message,
 	})
 }
+
+func NewResponseConflict(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusConflict, jsonErrorResponse{
+		Code:    "409" + serviceCode + errorCode,
+		Message: message,
+	})
+}
+
+func NewResponseUnprocessable(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusUnprocessableEntity, jsonErrorResponse{
+		Code:    "422" + serviceCode + errorCode,
+		Message: message,
+	})
+}
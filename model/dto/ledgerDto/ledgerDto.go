@@ -0,0 +1,37 @@
+package ledgerDto
+
+type (
+	GetLedgerEntriesParams struct {
+		TransactionId string
+		AccountType   string
+		AccountId     string
+		DateStart     string
+		DateEnd       string
+		Page          string
+		Limit         string
+	}
+
+	LedgerEntry struct {
+		Id            string `json:"id"`
+		TransactionId string `json:"transactionId"`
+		AccountType   string `json:"accountType"`
+		AccountId     string `json:"accountId"`
+		EntryType     string `json:"entryType"`
+		Amount        string `json:"amount"`
+		BalanceAfter  string `json:"balanceAfter,omitempty"`
+		CreatedAt     string `json:"createdAt"`
+	}
+
+	WalletBalanceCheck struct {
+		WalletId      string `json:"walletId"`
+		CachedBalance string `json:"cachedBalance"`
+		LedgerBalance string `json:"ledgerBalance"`
+		Consistent    bool   `json:"consistent"`
+	}
+
+	TrialBalance struct {
+		AccountType string `json:"accountType"`
+		TotalDebit  string `json:"totalDebit"`
+		TotalCredit string `json:"totalCredit"`
+	}
+)
@@ -0,0 +1,30 @@
+package limitsDto
+
+import "final-project-enigma/pkg/money"
+
+type (
+	// A nil amount means the cap is not enforced.
+	TransactionLimit struct {
+		TransactionType string       `json:"transactionType" binding:"required,oneof=topup transfer merchant_payment withdrawal"`
+		PerTransaction  *money.Money `json:"perTransaction"`
+		Daily           *money.Money `json:"daily"`
+		Monthly         *money.Money `json:"monthly"`
+	}
+
+	TierLimits struct {
+		Tier       string             `json:"tier"`
+		MaxBalance *money.Money       `json:"maxBalance"`
+		Limits     []TransactionLimit `json:"limits"`
+	}
+
+	UpdateTierRequest struct {
+		Tier       string             `json:"-"`
+		MaxBalance *money.Money       `json:"maxBalance"`
+		Limits     []TransactionLimit `json:"limits" binding:"dive"`
+	}
+
+	UpdateUserTierRequest struct {
+		UserId string `json:"-"`
+		Tier   string `json:"tier" binding:"required"`
+	}
+)
@@ -0,0 +1,90 @@
+package merchantDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	QRTypeStatic  = "static"
+	QRTypeDynamic = "dynamic"
+
+	PaymentTypePayment = "payment"
+	PaymentTypeRefund  = "refund"
+
+	ApiKeyStatusActive   = "active"
+	ApiKeyStatusRetiring = "retiring"
+	ApiKeyStatusExpired  = "expired"
+	ApiKeyStatusRevoked  = "revoked"
+)
+
+type (
+	CreateMerchantRequest struct {
+		MerchantName string `json:"merchantName" binding:"required,max=50"`
+		OwnerId      string `json:"ownerId" binding:"required"`
+	}
+
+	Merchant struct {
+		Id           string      `json:"id"`
+		MerchantName string      `json:"merchantName"`
+		OwnerId      string      `json:"ownerId,omitempty"`
+		WalletId     string      `json:"walletId"`
+		Balance      money.Money `json:"balance"`
+		CreatedAt    time.Time   `json:"createdAt"`
+	}
+
+	GetPaymentParams struct {
+		MerchantId string
+		Page       string
+		Limit      string
+	}
+
+	// ApiKey is a merchant's signing key. Secret is only filled in when the
+	// key is created; it cannot be read back afterwards.
+	ApiKey struct {
+		Id        string     `json:"id"`
+		KeyId     string     `json:"keyId"`
+		Secret    string     `json:"secret,omitempty"`
+		Status    string     `json:"status"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+		RevokedAt *time.Time `json:"revokedAt,omitempty"`
+		CreatedAt time.Time  `json:"createdAt"`
+	}
+
+	// ApiKeyAuth identifies the merchant behind a signed request.
+	ApiKeyAuth struct {
+		ApiKeyId   string
+		MerchantId string
+		Secret     string
+	}
+
+	// Payment is a merchant transaction seen from the merchant. Refunds are
+	// the reversals of earlier payments.
+	Payment struct {
+		TransactionId string      `json:"transactionId"`
+		Type          string      `json:"type"`
+		Amount        money.Money `json:"amount"`
+		PayerName     string      `json:"payerName"`
+		Description   string      `json:"description"`
+		BillReference string      `json:"billReference,omitempty"`
+		Status        string      `json:"status"`
+		CreatedAt     time.Time   `json:"createdAt"`
+	}
+
+	// QRParams asks for a static QR when Amount is zero. A dynamic QR needs
+	// both an amount and a bill number.
+	QRParams struct {
+		MerchantId string
+		Amount     money.Money
+		BillNumber string
+	}
+
+	QRResponse struct {
+		MerchantId   string      `json:"merchantId"`
+		MerchantName string      `json:"merchantName"`
+		Type         string      `json:"type"`
+		Amount       money.Money `json:"amount,omitempty"`
+		BillNumber   string      `json:"billNumber,omitempty"`
+		Payload      string      `json:"payload"`
+	}
+)
@@ -0,0 +1,17 @@
+package paymentMethodDto
+
+import "final-project-enigma/pkg/money"
+
+type (
+	// PaymentMethod is a method users can top up with. A nil bound means the
+	// method has none; the account's own limits still apply.
+	PaymentMethod struct {
+		Id            string       `json:"id"`
+		Name          string       `json:"name"`
+		ChannelType   string       `json:"channelType"`
+		MinAmount     *money.Money `json:"minAmount"`
+		MaxAmount     *money.Money `json:"maxAmount"`
+		ExpiryMinutes int          `json:"expiryMinutes"`
+		IconURL       string       `json:"iconUrl,omitempty"`
+	}
+)
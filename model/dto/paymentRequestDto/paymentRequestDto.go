@@ -0,0 +1,60 @@
+package paymentRequestDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+// Expired is never stored; a pending request past its expires_at is reported
+// with this status.
+const (
+	StatusPending   = "pending"
+	StatusAccepted  = "accepted"
+	StatusDeclined  = "declined"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+
+	PayerTypePhone    = "phone"
+	PayerTypeUsername = "username"
+)
+
+type (
+	CreatePaymentRequest struct {
+		RequesterId string      `json:"-"`
+		Payer       string      `json:"payer" binding:"required"`
+		PayerType   string      `json:"payerType" binding:"omitempty,oneof=phone username"`
+		Amount      money.Money `json:"amount" binding:"required,min=5"`
+		Note        string      `json:"note" binding:"max=100"`
+		ExpiresAt   time.Time   `json:"-"`
+	}
+
+	AcceptPaymentRequest struct {
+		Id      string `json:"-"`
+		PayerId string `json:"-"`
+		PIN     string `json:"pin" binding:"required,pin"`
+	}
+
+	GetPaymentRequestParams struct {
+		UserId    string
+		Direction string
+		Status    string
+	}
+
+	PaymentRequest struct {
+		Id             string      `json:"id"`
+		RequesterId    string      `json:"-"`
+		RequesterName  string      `json:"requesterName"`
+		RequesterPhone string      `json:"requesterPhoneNumber"`
+		PayerId        string      `json:"-"`
+		PayerName      string      `json:"payerName"`
+		PayerPhone     string      `json:"payerPhoneNumber"`
+		Amount         money.Money `json:"amount"`
+		Note           string      `json:"note"`
+		Status         string      `json:"status"`
+		TransactionId  string      `json:"transactionId,omitempty"`
+		SplitBillId    string      `json:"splitBillId,omitempty"`
+		ExpiresAt      time.Time   `json:"expiresAt"`
+		RespondedAt    *time.Time  `json:"respondedAt,omitempty"`
+		CreatedAt      time.Time   `json:"createdAt"`
+	}
+)
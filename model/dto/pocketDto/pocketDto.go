@@ -0,0 +1,44 @@
+package pocketDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+type (
+	CreatePocketRequest struct {
+		UserId   string `json:"-"`
+		Name     string `json:"name" binding:"required,max=50"`
+		Currency string `json:"currency" binding:"omitempty,oneof=IDR USD SGD"`
+	}
+
+	TransferRequest struct {
+		UserId       string      `json:"-"`
+		FromPocketId string      `json:"fromPocketId" binding:"required"`
+		ToPocketId   string      `json:"toPocketId" binding:"required,nefield=FromPocketId"`
+		Amount       money.Money `json:"amount" binding:"required,gt=0"`
+	}
+
+	TransferResponse struct {
+		TransactionId string      `json:"transactionId"`
+		FromPocketId  string      `json:"fromPocketId"`
+		ToPocketId    string      `json:"toPocketId"`
+		Amount        money.Money `json:"amount"`
+	}
+
+	Pocket struct {
+		Id        string      `json:"id"`
+		Name      string      `json:"name"`
+		IsMain    bool        `json:"isMain"`
+		Currency  string      `json:"currency"`
+		Balance   money.Money `json:"balance"`
+		CreatedAt time.Time   `json:"createdAt"`
+	}
+
+	// Balance totals the pockets per currency, since amounts in different
+	// currencies cannot be added up.
+	Balance struct {
+		Totals  map[string]money.Money `json:"totals"`
+		Pockets []Pocket               `json:"pockets"`
+	}
+)
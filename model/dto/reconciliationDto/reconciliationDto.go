@@ -0,0 +1,62 @@
+package reconciliationDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	// IssueMissingLocally is a report line whose order id is not a local
+	// top-up.
+	IssueMissingLocally = "missing_locally"
+	// IssueMissingInReport is a top-up settled locally on the report date
+	// that the gateway did not report.
+	IssueMissingInReport = "missing_in_report"
+	IssueAmountMismatch  = "amount_mismatch"
+	IssueStatusMismatch  = "status_mismatch"
+)
+
+type (
+	GetRunParams struct {
+		Page  string
+		Limit string
+	}
+
+	ResolveItemRequest struct {
+		Note string `json:"note" binding:"required,max=255"`
+	}
+
+	// TopUp is the local side of a report line. Amount includes the fee, as
+	// that is the gross amount charged through the gateway.
+	TopUp struct {
+		TransactionId string
+		Amount        money.Money
+		Status        string
+	}
+
+	Run struct {
+		Id           string    `json:"id"`
+		FileName     string    `json:"fileName"`
+		ReportDate   string    `json:"reportDate"`
+		TotalLines   int       `json:"totalLines"`
+		MatchedCount int       `json:"matchedCount"`
+		IssueCount   int       `json:"issueCount"`
+		OpenCount    int       `json:"openCount"`
+		CreatedBy    string    `json:"createdBy"`
+		CreatedAt    time.Time `json:"createdAt"`
+		Items        []Item    `json:"items,omitempty"`
+	}
+
+	Item struct {
+		Id             string       `json:"id"`
+		OrderId        string       `json:"orderId"`
+		IssueType      string       `json:"issueType"`
+		ReportAmount   *money.Money `json:"reportAmount,omitempty"`
+		LocalAmount    *money.Money `json:"localAmount,omitempty"`
+		ReportStatus   string       `json:"reportStatus,omitempty"`
+		LocalStatus    string       `json:"localStatus,omitempty"`
+		ResolvedBy     string       `json:"resolvedBy,omitempty"`
+		ResolvedAt     *time.Time   `json:"resolvedAt,omitempty"`
+		ResolutionNote string       `json:"resolutionNote,omitempty"`
+	}
+)
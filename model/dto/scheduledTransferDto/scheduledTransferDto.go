@@ -0,0 +1,81 @@
+package scheduledTransferDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	FrequencyOnce    = "once"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+
+	RunSuccess  = "success"
+	RunRetrying = "retrying"
+	RunSkipped  = "skipped"
+	RunFailed   = "failed"
+)
+
+type (
+	CreateScheduledTransferRequest struct {
+		UserId               string      `json:"-"`
+		RecipientPhoneNumber string      `json:"recipientPhoneNumber" binding:"required"`
+		Amount               money.Money `json:"amount" binding:"required,min=5"`
+		Description          string      `json:"description" binding:"max=100"`
+		Frequency            string      `json:"frequency" binding:"required,oneof=once weekly monthly"`
+		StartAt              time.Time   `json:"startAt" binding:"required"`
+		EndAt                *time.Time  `json:"endAt"`
+		PIN                  string      `json:"pin" binding:"required,pin"`
+	}
+
+	UpdateScheduledTransferRequest struct {
+		Id          string       `json:"-"`
+		UserId      string       `json:"-"`
+		Amount      *money.Money `json:"amount" binding:"omitempty,min=5"`
+		Description *string      `json:"description" binding:"omitempty,max=100"`
+		EndAt       *time.Time   `json:"endAt"`
+		Status      string       `json:"status" binding:"omitempty,oneof=active paused"`
+		PIN         string       `json:"pin" binding:"required,pin"`
+	}
+
+	ScheduledTransfer struct {
+		Id                   string      `json:"id"`
+		UserId               string      `json:"-"`
+		RecipientPhoneNumber string      `json:"recipientPhoneNumber"`
+		Amount               money.Money `json:"amount"`
+		Description          string      `json:"description"`
+		Frequency            string      `json:"frequency"`
+		StartAt              time.Time   `json:"startAt"`
+		EndAt                *time.Time  `json:"endAt,omitempty"`
+		NextRunAt            *time.Time  `json:"nextRunAt,omitempty"`
+		RetryAt              *time.Time  `json:"retryAt,omitempty"`
+		RetryCount           int         `json:"retryCount"`
+		Status               string      `json:"status"`
+		Runs                 []Run       `json:"runs,omitempty"`
+	}
+
+	Run struct {
+		Id            string    `json:"id"`
+		TransactionId string    `json:"transactionId,omitempty"`
+		OccurrenceAt  time.Time `json:"occurrenceAt"`
+		Attempt       int       `json:"attempt"`
+		Status        string    `json:"status"`
+		Message       string    `json:"message,omitempty"`
+		CreatedAt     time.Time `json:"createdAt"`
+	}
+
+	// RunResult is what the executor stores after one attempt of a due
+	// schedule.
+	RunResult struct {
+		Run        Run
+		NextRunAt  *time.Time
+		RetryAt    *time.Time
+		RetryCount int
+		Status     string
+	}
+)
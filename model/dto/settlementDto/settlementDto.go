@@ -0,0 +1,56 @@
+package settlementDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+
+	EntryTypePayment = "payment"
+	EntryTypeRefund  = "refund"
+)
+
+type (
+	GetBatchParams struct {
+		MerchantId string
+		Status     string
+		Page       string
+		Limit      string
+	}
+
+	ApproveBatchRequest struct {
+		Id      string
+		AdminId string
+	}
+
+	// Batch is one merchant's settlement for one business day. Net is what
+	// is paid out: gross minus refunds minus fee.
+	Batch struct {
+		Id               string      `json:"id"`
+		MerchantId       string      `json:"merchantId"`
+		MerchantName     string      `json:"merchantName"`
+		BusinessDate     string      `json:"businessDate"`
+		TransactionCount int         `json:"transactionCount"`
+		GrossAmount      money.Money `json:"grossAmount"`
+		RefundAmount     money.Money `json:"refundAmount"`
+		FeeAmount        money.Money `json:"feeAmount"`
+		NetAmount        money.Money `json:"netAmount"`
+		Status           string      `json:"status"`
+		TransactionId    string      `json:"transactionId,omitempty"`
+		ApprovedAt       *time.Time  `json:"approvedAt,omitempty"`
+		CreatedAt        time.Time   `json:"createdAt"`
+	}
+
+	// Entry is one merchant transaction settled in a batch.
+	Entry struct {
+		TransactionId string
+		Type          string
+		BillReference string
+		Description   string
+		Amount        money.Money
+		CreatedAt     time.Time
+	}
+)
@@ -0,0 +1,54 @@
+package splitBillDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	SplitTypeEqual  = "equal"
+	SplitTypeCustom = "custom"
+)
+
+type (
+	// Participant is one person asked to pay a share. Amount is only read for
+	// custom splits.
+	Participant struct {
+		PhoneNumber string      `json:"phoneNumber" binding:"required"`
+		Amount      money.Money `json:"amount"`
+	}
+
+	CreateSplitBillRequest struct {
+		OrganizerId    string        `json:"-"`
+		Title          string        `json:"title" binding:"required,max=100"`
+		TotalAmount    money.Money   `json:"totalAmount" binding:"required,min=5"`
+		SplitType      string        `json:"splitType" binding:"required,oneof=equal custom"`
+		Participants   []Participant `json:"participants" binding:"required,min=1,max=20,dive"`
+		OrganizerShare money.Money   `json:"-"`
+		ExpiresAt      time.Time     `json:"-"`
+	}
+
+	Share struct {
+		RequestId       string      `json:"requestId"`
+		ParticipantName string      `json:"participantName"`
+		PhoneNumber     string      `json:"phoneNumber"`
+		Amount          money.Money `json:"amount"`
+		Status          string      `json:"status"`
+		TransactionId   string      `json:"transactionId,omitempty"`
+		RespondedAt     *time.Time  `json:"respondedAt,omitempty"`
+	}
+
+	SplitBill struct {
+		Id             string      `json:"id"`
+		Title          string      `json:"title"`
+		TotalAmount    money.Money `json:"totalAmount"`
+		SplitType      string      `json:"splitType"`
+		OrganizerShare money.Money `json:"organizerShare"`
+		PaidAmount     money.Money `json:"paidAmount"`
+		UnpaidAmount   money.Money `json:"unpaidAmount"`
+		PaidShares     int         `json:"paidShares"`
+		UnpaidShares   int         `json:"unpaidShares"`
+		CreatedAt      time.Time   `json:"createdAt"`
+		Shares         []Share     `json:"shares,omitempty"`
+	}
+)
@@ -0,0 +1,51 @@
+package statementDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	FormatPDF = "pdf"
+	FormatCSV = "csv"
+)
+
+type (
+	GetStatementParams struct {
+		UserId   string
+		PocketId string
+		From     time.Time
+		To       time.Time
+	}
+
+	// Statement is one month of a pocket, the main pocket unless another one
+	// is asked for. Amounts are in the pocket's currency.
+	Statement struct {
+		AccountName    string
+		PhoneNumber    string
+		PocketId       string
+		PocketName     string
+		Currency       string
+		From           time.Time
+		To             time.Time
+		OpeningBalance money.Money
+		TotalCredit    money.Money
+		TotalDebit     money.Money
+		ClosingBalance money.Money
+		Lines          []Line
+		GeneratedAt    time.Time
+	}
+
+	// Line is one ledger entry on the pocket. Counterparty names the other
+	// side: the other user or pocket of a transfer, the merchant paid, the
+	// top-up channel or the bank account withdrawn to.
+	Line struct {
+		TransactionId string
+		Date          time.Time
+		Description   string
+		Counterparty  string
+		EntryType     string
+		Amount        money.Money
+		Balance       money.Money
+	}
+)
@@ -1,7 +1,10 @@
 package userDto
 
 import (
+	"final-project-enigma/model/dto/pocketDto"
+	"final-project-enigma/pkg/money"
 	"mime/multipart"
+	"time"
 )
 
 type (
@@ -91,12 +94,13 @@ type (
 	}
 
 	UserGetDataResponse struct {
-		Fullname     string `json:"fullname,omitempty"`
-		Username     string `json:"username,omitempty"`
-		Email        string `json:"email,omitempty"`
-		ProfilImages string `json:"profilImages,omitempty"`
-		PhoneNumber  string `json:"phoneNumber,omitempty"`
-		Balance      string `json:"balance,omitempty"`
+		Fullname     string             `json:"fullname,omitempty"`
+		Username     string             `json:"username,omitempty"`
+		Email        string             `json:"email,omitempty"`
+		ProfilImages string             `json:"profilImages,omitempty"`
+		PhoneNumber  string             `json:"phoneNumber,omitempty"`
+		Balance      string             `json:"balance,omitempty"`
+		Pockets      []pocketDto.Pocket `json:"pockets,omitempty"`
 	}
 
 	GetTransactionParams struct {
@@ -115,6 +119,7 @@ type (
 		TransactionId   string            `json:"transactionId,omitempty"`
 		TransactionType string            `json:"transactionType,omitempty"`
 		Amount          string            `json:"amount,omitempty"`
+		Currency        string            `json:"currency,omitempty"`
 		Description     string            `json:"description"`
 		TransactionDate string            `json:"transactionDate"`
 		Status          string            `json:"status"`
@@ -123,80 +128,101 @@ type (
 	}
 
 	TransactionDetail struct {
-		SenderName    string `json:"senderName,omitempty"`
-		RecipientName string `json:"recipientName,omitempty"`
-		SenderId      string `json:"-"`
-		RecipientId   string `json:"-"`
-		PaymentMethod string `json:"paymentMethod,omitempty"`
-		PaymentURL    string `json:"paymentURL,omitempty"`
-		FromWalletId  string `json:"fromWalletId,omitempty"`
-		MerchantName  string `json:"merchantName,omitempty"`
-		ToWalletId    string `json:"toWalletId,omitempty"`
+		SenderName     string `json:"senderName,omitempty"`
+		RecipientName  string `json:"recipientName,omitempty"`
+		SenderId       string `json:"-"`
+		RecipientId    string `json:"-"`
+		PaymentMethod  string `json:"paymentMethod,omitempty"`
+		PaymentURL     string `json:"paymentURL,omitempty"`
+		FromWalletId   string `json:"fromWalletId,omitempty"`
+		MerchantName   string `json:"merchantName,omitempty"`
+		ToWalletId     string `json:"toWalletId,omitempty"`
+		ReversalOf     string `json:"reversalOf,omitempty"`
+		ReversedBy     string `json:"reversedBy,omitempty"`
+		ReversalReason string `json:"reversalReason,omitempty"`
 	}
 
 	TopUpTransactionRequest struct {
-		UserId          string  `json:"userId"`
-		Amount          float64 `json:"amount" binding:"required,min=5"`
-		Description     string  `json:"description"`
-		PaymentMethodId string  `json:"paymentMethodId" binding:"required,min=15"`
+		UserId          string      `json:"userId"`
+		Amount          money.Money `json:"amount" binding:"required,min=5"`
+		Description     string      `json:"description"`
+		PaymentMethodId string      `json:"paymentMethodId" binding:"required,min=15"`
 	}
 
 	MerchantTransactionRequest struct {
-		UserId      string  `json:"userId"`
-		Amount      float64 `json:"amount" binding:"required,min=5"`
-		Description string  `json:"description"`
-		MerchantId  string  `json:"merchantId" binding:"required,min=15"`
+		UserId      string      `json:"userId"`
+		Amount      money.Money `json:"amount" binding:"required,min=5"`
+		Description string      `json:"description"`
+		MerchantId  string      `json:"merchantId" binding:"required,min=15"`
+		PIN         string      `json:"pin" binding:"required,pin"`
+		// BillReference is the bill number of a dynamic QR. A merchant's bill
+		// can only be paid once.
+		BillReference string `json:"-"`
+	}
+
+	// QRPaymentRequest pays a scanned merchant QR. Amount is required for a
+	// static QR and must match the QR when it is dynamic.
+	QRPaymentRequest struct {
+		Payload string      `json:"payload" binding:"required"`
+		Amount  money.Money `json:"amount"`
+		PIN     string      `json:"pin" binding:"required,pin"`
 	}
 
 	MerchantTransactionResponse struct {
-		TransactionId string `json:"transactionId"`
+		TransactionId string      `json:"transactionId"`
+		Fee           money.Money `json:"fee"`
 	}
 
 	TopUpTransactionResponse struct {
-		TransactionId string `json:"transactionId"`
+		TransactionId string      `json:"transactionId"`
+		Fee           money.Money `json:"fee"`
+	}
+
+	PendingTopUp struct {
+		TransactionId string
+		CreatedAt     time.Time
 	}
 
 	WalletTransactionRequest struct {
-		UserId               string  `json:"userId"`
-		FromWalletId         string  `json:"fromWalletId"`
-		ToWalletId           string  `json:"toWalletId"`
-		RecipientPhoneNumber string  `json:"recipientPhoneNumber" binding:"required"`
-		Amount               float64 `json:"amount" binding:"required,min=5"`
-		PIN                  string  `json:"pin" binding:"required,pin"`
-		Description          string  `json:"description"`
+		UserId               string      `json:"userId"`
+		FromWalletId         string      `json:"fromWalletId"`
+		ToWalletId           string      `json:"toWalletId"`
+		RecipientPhoneNumber string      `json:"recipientPhoneNumber" binding:"required"`
+		Amount               money.Money `json:"amount" binding:"required,min=5"`
+		PIN                  string      `json:"pin" binding:"required,pin"`
+		Description          string      `json:"description"`
 	}
 
 	WalletTransactionResponse struct {
-		TransactionId string `json:"transactionId"`
+		TransactionId string      `json:"transactionId"`
+		Fee           money.Money `json:"fee"`
 	}
 
-	MidtransSnapReq struct {
-		TransactionDetail struct {
-			OrderID  string  `json:"order_id"`
-			GrossAmt float64 `json:"gross_amount"`
-		} `json:"transaction_details"`
-		PaymentType string `json:"payment_type"`
-		Customer    string `json:"customer"`
-		Items       []Item `json:"item_details"`
+	FeeQuoteParams struct {
+		TransactionType string
+		PaymentMethodId string
+		Amount          money.Money
 	}
 
-	Item struct {
-		ID       string  `json:"id"`
-		Name     string  `json:"name"`
-		Price    float64 `json:"price"`
-		Quantity int     `json:"quantity"`
+	FeeQuoteResponse struct {
+		TransactionType string      `json:"transactionType"`
+		Amount          money.Money `json:"amount"`
+		Fee             money.Money `json:"fee"`
+		Total           money.Money `json:"total"`
 	}
 
-	MidtransSnapResp struct {
-		Token        string   `json:"token"`
-		RedirectUrl  string   `json:"redirect_url"`
-		ErrorMessage []string `json:"error_messages"`
+	// TopUpCheckoutResponse is where the user pays for a top-up.
+	TopUpCheckoutResponse struct {
+		Token       string `json:"token"`
+		RedirectUrl string `json:"redirect_url"`
 	}
 
 	MidtransNotification struct {
-		TransactionStatus string `json:"transaction_status"`
-		OrderID           string `json:"order_id"`
-		GrossAmount       string `json:"gross_amount"`
+		TransactionStatus string `json:"transaction_status" binding:"required"`
+		OrderID           string `json:"order_id" binding:"required"`
+		StatusCode        string `json:"status_code" binding:"required"`
+		GrossAmount       string `json:"gross_amount" binding:"required"`
+		SignatureKey      string `json:"signature_key" binding:"required"`
 		PaymentType       string `json:"payment_type"`
 		TransactionTime   string `json:"transaction_time"`
 		FraudStatus       string `json:"fraud_status"`
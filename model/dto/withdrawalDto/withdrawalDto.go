@@ -0,0 +1,58 @@
+package withdrawalDto
+
+import (
+	"final-project-enigma/pkg/money"
+	"time"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+type (
+	CreateBankAccountRequest struct {
+		UserId        string `json:"-"`
+		BankCode      string `json:"bankCode" binding:"required,max=20"`
+		AccountNumber string `json:"accountNumber" binding:"required,numeric,min=5,max=30"`
+		AccountName   string `json:"accountName" binding:"required,max=100"`
+	}
+
+	BankAccount struct {
+		Id            string    `json:"id"`
+		BankCode      string    `json:"bankCode"`
+		AccountNumber string    `json:"accountNumber"`
+		AccountName   string    `json:"accountName"`
+		CreatedAt     time.Time `json:"createdAt"`
+	}
+
+	CreateWithdrawalRequest struct {
+		UserId        string      `json:"-"`
+		BankAccountId string      `json:"bankAccountId" binding:"required"`
+		Amount        money.Money `json:"amount" binding:"required,min=10000"`
+		PIN           string      `json:"pin" binding:"required,pin"`
+		Provider      string      `json:"-"`
+	}
+
+	// ResolveWithdrawalRequest settles a withdrawal by hand once its payout
+	// was checked with the provider.
+	ResolveWithdrawalRequest struct {
+		Status string `json:"status" binding:"required,oneof=completed failed"`
+		Reason string `json:"reason" binding:"required_if=Status failed,max=255"`
+	}
+
+	Withdrawal struct {
+		Id            string      `json:"id"`
+		TransactionId string      `json:"transactionId"`
+		BankAccount   BankAccount `json:"bankAccount"`
+		Amount        money.Money `json:"amount"`
+		Fee           money.Money `json:"fee"`
+		Status        string      `json:"status"`
+		ReferenceNo   string      `json:"referenceNo,omitempty"`
+		FailureReason string      `json:"failureReason,omitempty"`
+		CreatedAt     time.Time   `json:"createdAt"`
+		UpdatedAt     time.Time   `json:"updatedAt"`
+	}
+)
@@ -0,0 +1,62 @@
+package dbtx
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxAttempts = 5
+	baseBackoff = 20 * time.Millisecond
+)
+
+// Postgres error codes that mean the transaction lost a race and can simply
+// be run again.
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// WithRetry runs fn inside a DB transaction and commits it. Any error from fn
+// rolls the transaction back. When Postgres aborts the transaction with a
+// serialization failure or a deadlock the whole of fn is retried with a short
+// backoff, so fn must not have side effects outside tx.
+func WithRetry(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = run(db, fn)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+
+		log.Error().Msgf("retrying transaction after %v (attempt %d)", err, attempt)
+		time.Sleep(time.Duration(attempt) * baseBackoff)
+	}
+	return err
+}
+
+func run(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == codeSerializationFailure || pqErr.Code == codeDeadlockDetected
+	}
+	return false
+}
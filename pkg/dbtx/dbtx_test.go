@@ -0,0 +1,66 @@
+package dbtx_test
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/dbtx"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetry_RetriesSerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE wallets").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE wallets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	calls := 0
+	err = dbtx.WithRetry(db, func(tx *sql.Tx) error {
+		calls++
+		_, err := tx.Exec("UPDATE wallets SET balance = balance")
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithRetry_DoesNotRetryOtherErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	calls := 0
+	err = dbtx.WithRetry(db, func(tx *sql.Tx) error {
+		calls++
+		return errors.New("insufficient balance")
+	})
+
+	assert.EqualError(t, err, "insufficient balance")
+	assert.Equal(t, 1, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, dbtx.IsRetryable(fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"})))
+	assert.False(t, dbtx.IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, dbtx.IsRetryable(errors.New("boom")))
+}
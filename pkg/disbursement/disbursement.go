@@ -0,0 +1,76 @@
+package disbursement
+
+import (
+	"errors"
+	"final-project-enigma/pkg/money"
+	"net/http"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Payout statuses a provider reports, reduced to the three the withdrawal
+// flow acts on.
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+
+	ProviderIris = "iris"
+	ProviderFake = "fake"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid disbursement callback signature")
+	ErrInvalidCallback  = errors.New("invalid disbursement callback")
+	ErrPayoutRejected   = errors.New("payout rejected by the disbursement provider")
+)
+
+type (
+	// PayoutRequest is one transfer to a bank account. Reference is our
+	// withdrawal id and is passed along so payouts can be traced.
+	PayoutRequest struct {
+		Reference     string
+		BankCode      string
+		AccountNumber string
+		AccountName   string
+		Amount        money.Money
+		Notes         string
+	}
+
+	// Payout is the provider's answer to a PayoutRequest. ReferenceNo is the
+	// provider's id, which later callbacks refer to.
+	Payout struct {
+		ReferenceNo string
+		Status      string
+	}
+
+	Callback struct {
+		ReferenceNo string
+		Status      string
+		Error       string
+	}
+)
+
+// Disbursement sends money from the platform to bank accounts. Payouts are
+// asynchronous: CreatePayout only queues the transfer, and its outcome
+// arrives later as a callback.
+type Disbursement interface {
+	Name() string
+	CreatePayout(req PayoutRequest) (Payout, error)
+	ParseCallback(header http.Header, body []byte) (Callback, error)
+}
+
+// New returns the provider named by DISBURSEMENT_PROVIDER, Midtrans Iris by
+// default.
+func New(client *resty.Client) Disbursement {
+	if os.Getenv("DISBURSEMENT_PROVIDER") == ProviderFake {
+		return NewFake()
+	}
+
+	baseURL := os.Getenv("IRIS_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultIrisBaseURL
+	}
+	return NewIris(client, baseURL, os.Getenv("IRIS_API_KEY"), os.Getenv("IRIS_MERCHANT_KEY"))
+}
@@ -0,0 +1,51 @@
+package disbursement
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Fake records payouts instead of sending them. Set Err to make
+// CreatePayout fail. Its callbacks are the plain JSON form of Callback and
+// are not signed, so it must never be used in production.
+type Fake struct {
+	mu      sync.Mutex
+	Payouts []PayoutRequest
+	Err     error
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+func (f *Fake) CreatePayout(req PayoutRequest) (Payout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return Payout{}, f.Err
+	}
+	f.Payouts = append(f.Payouts, req)
+	return Payout{
+		ReferenceNo: "fake-" + strconv.Itoa(len(f.Payouts)),
+		Status:      StatusProcessing,
+	}, nil
+}
+
+func (f *Fake) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	var callback struct {
+		ReferenceNo string `json:"referenceNo"`
+		Status      string `json:"status"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &callback); err != nil || callback.ReferenceNo == "" {
+		return Callback{}, ErrInvalidCallback
+	}
+	return Callback{ReferenceNo: callback.ReferenceNo, Status: callback.Status, Error: callback.Error}, nil
+}
@@ -0,0 +1,162 @@
+package disbursement
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	defaultIrisBaseURL = "https://app.sandbox.midtrans.com/iris"
+
+	// IrisSignatureHeader carries SHA-512 of the notification body followed
+	// by the merchant key, hex encoded.
+	IrisSignatureHeader = "Iris-Signature"
+)
+
+// Iris pays out through Midtrans Iris. The creator API key only queues
+// payouts, so the account must have auto-approval enabled for them to be
+// sent without a second approval step.
+type Iris struct {
+	client      *resty.Client
+	baseURL     string
+	apiKey      string
+	merchantKey string
+}
+
+func NewIris(client *resty.Client, baseURL, apiKey, merchantKey string) *Iris {
+	return &Iris{
+		client:      client,
+		baseURL:     baseURL,
+		apiKey:      apiKey,
+		merchantKey: merchantKey,
+	}
+}
+
+type (
+	irisPayout struct {
+		BeneficiaryName    string `json:"beneficiary_name"`
+		BeneficiaryAccount string `json:"beneficiary_account"`
+		BeneficiaryBank    string `json:"beneficiary_bank"`
+		Amount             string `json:"amount"`
+		Notes              string `json:"notes"`
+	}
+
+	irisPayoutRequest struct {
+		Payouts []irisPayout `json:"payouts"`
+	}
+
+	irisPayoutResponse struct {
+		Payouts []struct {
+			Status      string `json:"status"`
+			ReferenceNo string `json:"reference_no"`
+		} `json:"payouts"`
+		ErrorMessage string   `json:"error_message"`
+		Errors       []string `json:"errors"`
+	}
+
+	irisNotification struct {
+		ReferenceNo  string      `json:"reference_no"`
+		Amount       money.Money `json:"amount"`
+		Status       string      `json:"status"`
+		ErrorCode    string      `json:"error_code"`
+		ErrorMessage string      `json:"error_message"`
+	}
+)
+
+func (i *Iris) Name() string {
+	return ProviderIris
+}
+
+func (i *Iris) CreatePayout(req PayoutRequest) (Payout, error) {
+	payload := irisPayoutRequest{
+		Payouts: []irisPayout{{
+			BeneficiaryName:    req.AccountName,
+			BeneficiaryAccount: req.AccountNumber,
+			BeneficiaryBank:    req.BankCode,
+			Amount:             req.Amount.String(),
+			Notes:              req.Notes,
+		}},
+	}
+
+	encodeKey := base64.StdEncoding.EncodeToString([]byte(i.apiKey + ":"))
+	resp, err := i.client.R().
+		SetHeader("Authorization", "Basic "+encodeKey).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		SetBody(payload).
+		Post(i.baseURL + "/api/v1/payouts")
+	if err != nil {
+		return Payout{}, err
+	}
+
+	var payoutResp irisPayoutResponse
+	if err := json.Unmarshal(resp.Body(), &payoutResp); err != nil {
+		return Payout{}, fmt.Errorf("failed to read iris response (%d): %w", resp.StatusCode(), err)
+	}
+	if resp.StatusCode() == http.StatusBadRequest || resp.StatusCode() == http.StatusUnprocessableEntity {
+		return Payout{}, fmt.Errorf("%w: %s %v", ErrPayoutRejected, payoutResp.ErrorMessage, payoutResp.Errors)
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 || len(payoutResp.Payouts) == 0 {
+		return Payout{}, fmt.Errorf("iris answered %d: %s", resp.StatusCode(), payoutResp.ErrorMessage)
+	}
+
+	return Payout{
+		ReferenceNo: payoutResp.Payouts[0].ReferenceNo,
+		Status:      irisStatus(payoutResp.Payouts[0].Status),
+	}, nil
+}
+
+func (i *Iris) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	if !VerifyIrisSignature(header.Get(IrisSignatureHeader), body, i.merchantKey) {
+		return Callback{}, ErrInvalidSignature
+	}
+
+	var notification irisNotification
+	if err := json.Unmarshal(body, &notification); err != nil || notification.ReferenceNo == "" {
+		return Callback{}, ErrInvalidCallback
+	}
+
+	callback := Callback{
+		ReferenceNo: notification.ReferenceNo,
+		Status:      irisStatus(notification.Status),
+	}
+	if callback.Status == StatusFailed {
+		callback.Error = notification.ErrorMessage
+		if callback.Error == "" {
+			callback.Error = notification.ErrorCode
+		}
+	}
+	return callback, nil
+}
+
+func GenerateIrisSignature(body []byte, merchantKey string) string {
+	sum := sha512.Sum512(append(append([]byte{}, body...), merchantKey...))
+	return hex.EncodeToString(sum[:])
+}
+
+func VerifyIrisSignature(signature string, body []byte, merchantKey string) bool {
+	if signature == "" || merchantKey == "" {
+		return false
+	}
+	expected := GenerateIrisSignature(body, merchantKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+}
+
+func irisStatus(status string) string {
+	switch status {
+	case "completed":
+		return StatusCompleted
+	case "failed", "rejected":
+		return StatusFailed
+	default:
+		return StatusProcessing
+	}
+}
@@ -0,0 +1,72 @@
+package disbursement_test
+
+import (
+	"encoding/json"
+	"errors"
+	"final-project-enigma/pkg/disbursement"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIrisCreatePayout(t *testing.T) {
+	var received map[string][]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/payouts", r.URL.Path)
+		username, _, _ := r.BasicAuth()
+		assert.Equal(t, "creator-key", username)
+
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"payouts":[{"status":"queued","reference_no":"ref-1"}]}`))
+	}))
+	defer server.Close()
+
+	iris := disbursement.NewIris(resty.New(), server.URL, "creator-key", "merchant-key")
+	payout, err := iris.CreatePayout(disbursement.PayoutRequest{
+		BankCode:      "bca",
+		AccountNumber: "1234567890",
+		AccountName:   "Budi",
+		Amount:        150000,
+		Notes:         "Withdrawal",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, disbursement.Payout{ReferenceNo: "ref-1", Status: disbursement.StatusProcessing}, payout)
+	assert.Equal(t, "150000.00", received["payouts"][0]["amount"])
+	assert.Equal(t, "bca", received["payouts"][0]["beneficiary_bank"])
+}
+
+func TestIrisCreatePayout_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error_message":"An error occurred when creating payouts","errors":["Beneficiary account is invalid"]}`))
+	}))
+	defer server.Close()
+
+	iris := disbursement.NewIris(resty.New(), server.URL, "creator-key", "merchant-key")
+	_, err := iris.CreatePayout(disbursement.PayoutRequest{Amount: 150000})
+
+	assert.True(t, errors.Is(err, disbursement.ErrPayoutRejected))
+}
+
+func TestIrisParseCallback(t *testing.T) {
+	iris := disbursement.NewIris(resty.New(), "", "creator-key", "merchant-key")
+	body := []byte(`{"reference_no":"ref-1","amount":"150000.00","status":"failed","error_code":"001","error_message":"Account closed"}`)
+
+	header := http.Header{}
+	header.Set(disbursement.IrisSignatureHeader, disbursement.GenerateIrisSignature(body, "merchant-key"))
+	callback, err := iris.ParseCallback(header, body)
+	assert.NoError(t, err)
+	assert.Equal(t, disbursement.Callback{ReferenceNo: "ref-1", Status: disbursement.StatusFailed, Error: "Account closed"}, callback)
+
+	header.Set(disbursement.IrisSignatureHeader, disbursement.GenerateIrisSignature(body, "other-key"))
+	_, err = iris.ParseCallback(header, body)
+	assert.ErrorIs(t, err, disbursement.ErrInvalidSignature)
+}
@@ -0,0 +1,115 @@
+package exchangeRate
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/money"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	ProviderTable = "table"
+	ProviderHTTP  = "http"
+	ProviderFake  = "fake"
+
+	// rateScale is the number of decimal places a rate keeps, the scale of
+	// the fx_rates.rate column.
+	rateScale = 10
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrRateNotFound        = errors.New("exchange rate not available")
+	ErrInvalidRate         = errors.New("invalid exchange rate")
+)
+
+// Provider quotes how many units of the to currency one unit of the from
+// currency is worth. Rates are in major units (16250 for USD to IDR) and
+// never carry more than rateScale decimal places, so a rate stored with a
+// quote converts to exactly the same amount later.
+type Provider interface {
+	Name() string
+	Rate(from, to string) (*big.Rat, error)
+}
+
+// New returns the provider named by FX_PROVIDER, the admin-maintained rate
+// table by default.
+func New(db *sql.DB, client *resty.Client) Provider {
+	switch os.Getenv("FX_PROVIDER") {
+	case ProviderHTTP:
+		return NewHTTP(client, os.Getenv("FX_API_BASE_URL"), os.Getenv("FX_API_KEY"))
+	case ProviderFake:
+		return NewFake()
+	default:
+		return NewTable(db)
+	}
+}
+
+func Supported(currency string) bool {
+	_, ok := money.Currencies[currency]
+	return ok
+}
+
+// ParseRate reads a positive decimal rate and rounds it to rateScale places.
+func ParseRate(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return nil, ErrInvalidRate
+	}
+	return round(r)
+}
+
+func FormatRate(r *big.Rat) string {
+	return r.FloatString(rateScale)
+}
+
+func round(r *big.Rat) (*big.Rat, error) {
+	rounded, _ := new(big.Rat).SetString(r.FloatString(rateScale))
+	if rounded.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return rounded, nil
+}
+
+func invert(r *big.Rat) (*big.Rat, error) {
+	return round(new(big.Rat).Inv(r))
+}
+
+// Convert turns an amount in the smallest unit of from into the smallest unit
+// of to at the given rate. Whatever does not make up a whole unit of to is
+// dropped, so a conversion never credits more than the rate allows.
+func Convert(amount money.Money, from, to string, rate *big.Rat) (money.Money, error) {
+	fromExp, ok := money.Currencies[from]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+	toExp, ok := money.Currencies[to]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil))
+	if toExp > fromExp {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return 0, money.ErrAmountOutOfRange
+	}
+	return money.Money(converted.Int64()), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
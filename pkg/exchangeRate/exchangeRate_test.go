@@ -0,0 +1,73 @@
+package exchangeRate_test
+
+import (
+	"final-project-enigma/pkg/exchangeRate"
+	"final-project-enigma/pkg/money"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	rate, err := exchangeRate.ParseRate("16250")
+	assert.NoError(t, err)
+
+	// 12.34 USD is 200525 IDR.
+	converted, err := exchangeRate.Convert(money.Money(1234), "USD", "IDR", rate)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money(200525), converted)
+
+	// 100000 IDR is 6.1538... USD; the partial cent is dropped.
+	inverse, err := exchangeRate.NewFake().Rate("IDR", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0000625000", exchangeRate.FormatRate(inverse))
+	converted, err = exchangeRate.Convert(money.Money(100000), "IDR", "USD", inverse)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money(625), converted)
+
+	_, err = exchangeRate.Convert(money.Money(100), "IDR", "EUR", rate)
+	assert.ErrorIs(t, err, exchangeRate.ErrUnsupportedCurrency)
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := exchangeRate.ParseRate("0.000061538461538")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0000615385", exchangeRate.FormatRate(rate))
+
+	_, err = exchangeRate.ParseRate("0")
+	assert.ErrorIs(t, err, exchangeRate.ErrInvalidRate)
+	_, err = exchangeRate.ParseRate("abc")
+	assert.ErrorIs(t, err, exchangeRate.ErrInvalidRate)
+}
+
+func TestHTTPRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/latest", r.URL.Path)
+		assert.Equal(t, "SGD", r.URL.Query().Get("base"))
+		assert.Equal(t, "Bearer api-key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"base":"SGD","rates":{"IDR":12104.35}}`))
+	}))
+	defer server.Close()
+
+	provider := exchangeRate.NewHTTP(resty.New(), server.URL+"/", "api-key")
+	rate, err := provider.Rate("SGD", "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, "12104.3500000000", exchangeRate.FormatRate(rate))
+
+	_, err = provider.Rate("SGD", "USD")
+	assert.ErrorIs(t, err, exchangeRate.ErrRateNotFound)
+}
+
+func TestHTTPRate_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"rate limited"}`))
+	}))
+	defer server.Close()
+
+	_, err := exchangeRate.NewHTTP(resty.New(), server.URL, "").Rate("USD", "IDR")
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package exchangeRate
+
+import "math/big"
+
+// Fake answers from a fixed set of rates keyed "FROM/TO", for local
+// development and tests. Pairs only set the other way round are inverted.
+type Fake struct {
+	Rates map[string]string
+}
+
+func NewFake() *Fake {
+	return &Fake{Rates: map[string]string{
+		"USD/IDR": "16000",
+		"SGD/IDR": "12000",
+		"USD/SGD": "1.35",
+	}}
+}
+
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+func (f *Fake) Rate(from, to string) (*big.Rat, error) {
+	if rate, ok := f.Rates[from+"/"+to]; ok {
+		return ParseRate(rate)
+	}
+	if rate, ok := f.Rates[to+"/"+from]; ok {
+		r, err := ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		return invert(r)
+	}
+	return nil, ErrRateNotFound
+}
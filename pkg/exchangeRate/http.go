@@ -0,0 +1,65 @@
+package exchangeRate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// HTTP reads live rates from an exchangerate-style API:
+// GET {baseURL}/latest?base=USD&symbols=IDR answering
+// {"base":"USD","rates":{"IDR":16250.5}}.
+type HTTP struct {
+	client  *resty.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTP(client *resty.Client, baseURL, apiKey string) *HTTP {
+	return &HTTP{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+type latestResponse struct {
+	Base  string                 `json:"base"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+func (h *HTTP) Name() string {
+	return ProviderHTTP
+}
+
+func (h *HTTP) Rate(from, to string) (*big.Rat, error) {
+	req := h.client.R().
+		SetHeader("Accept", "application/json").
+		SetQueryParam("base", from).
+		SetQueryParam("symbols", to)
+	if h.apiKey != "" {
+		req.SetHeader("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := req.Get(h.baseURL + "/latest")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("exchange rate API answered %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var latest latestResponse
+	if err := json.Unmarshal(resp.Body(), &latest); err != nil {
+		return nil, fmt.Errorf("failed to read exchange rate response: %w", err)
+	}
+	rate, ok := latest.Rates[to]
+	if !ok {
+		return nil, ErrRateNotFound
+	}
+
+	return ParseRate(rate.String())
+}
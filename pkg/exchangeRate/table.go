@@ -0,0 +1,63 @@
+package exchangeRate
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+)
+
+// Table reads the rates admins maintain in fx_rates. A pair that is only
+// stored the other way round is answered with the inverse rate.
+type Table struct {
+	db *sql.DB
+}
+
+func NewTable(db *sql.DB) *Table {
+	return &Table{db: db}
+}
+
+func (t *Table) Name() string {
+	return ProviderTable
+}
+
+func (t *Table) Rate(from, to string) (*big.Rat, error) {
+	query := `
+		SELECT base_currency, rate
+		FROM fx_rates
+		WHERE (base_currency = $1 AND quote_currency = $2) OR (base_currency = $2 AND quote_currency = $1)
+	`
+	rows, err := t.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	defer rows.Close()
+
+	var direct, inverse string
+	for rows.Next() {
+		var base, rate string
+		if err := rows.Scan(&base, &rate); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		if base == from {
+			direct = rate
+		} else {
+			inverse = rate
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case direct != "":
+		return ParseRate(direct)
+	case inverse != "":
+		rate, err := ParseRate(inverse)
+		if err != nil {
+			return nil, err
+		}
+		return invert(rate)
+	default:
+		return nil, ErrRateNotFound
+	}
+}
@@ -0,0 +1,121 @@
+package fees
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
+	"fmt"
+)
+
+const (
+	TypeFlat       = "flat"
+	TypePercentage = "percentage"
+
+	// TransactionMerchantSettlement is the fee_schedules transaction type of
+	// the merchant discount rate taken from settlement batches. Unlike the
+	// limits.Type* types it has no usage limits.
+	TransactionMerchantSettlement = "merchant_settlement"
+
+	// PlatformWalletId is the wallet every fee is credited to. It has no
+	// owner and is seeded by init.sql.
+	PlatformWalletId = "00000000-0000-0000-0000-00000000fee1"
+)
+
+var ErrInvalidSchedule = errors.New("invalid fee schedule")
+
+// Schedule is one row of fee_schedules. Percentages are kept in basis points
+// (1 bps = 0.01%) so fees stay exact in whole rupiah.
+type Schedule struct {
+	FeeType       string
+	FlatAmount    money.Money
+	PercentageBps int64
+	MinFee        *money.Money
+	MaxFee        *money.Money
+}
+
+// Calculate returns the fee for amount, rounding percentage fees half up to
+// the nearest rupiah before applying the min/max bounds.
+func (s Schedule) Calculate(amount money.Money) (money.Money, error) {
+	var fee money.Money
+	switch s.FeeType {
+	case TypeFlat:
+		fee = s.FlatAmount
+	case TypePercentage:
+		fee = money.Money((int64(amount)*s.PercentageBps + 5000) / 10000)
+	default:
+		return 0, ErrInvalidSchedule
+	}
+
+	if s.MinFee != nil && fee < *s.MinFee {
+		fee = *s.MinFee
+	}
+	if s.MaxFee != nil && fee > *s.MaxFee {
+		fee = *s.MaxFee
+	}
+	return fee, nil
+}
+
+type querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Quote returns the fee for a transaction of txType (one of the limits.Type*
+// transaction types) and amount. A schedule for the given payment method wins
+// over the generic one for txType; with no active schedule the fee is zero.
+// q may be a *sql.DB or a *sql.Tx.
+func Quote(q querier, txType, paymentMethodId string, amount money.Money) (money.Money, error) {
+	var s Schedule
+	query := `
+		SELECT fee_type, flat_amount, percentage_bps, min_fee, max_fee
+		FROM fee_schedules
+		WHERE transaction_type = $1
+			AND (payment_method_id = $2 OR payment_method_id IS NULL)
+			AND active = TRUE
+		ORDER BY payment_method_id IS NULL
+		LIMIT 1
+	`
+	var paymentMethod sql.NullString
+	if paymentMethodId != "" {
+		paymentMethod = sql.NullString{String: paymentMethodId, Valid: true}
+	}
+	err := q.QueryRow(query, txType, paymentMethod).Scan(&s.FeeType, &s.FlatAmount, &s.PercentageBps, &s.MinFee, &s.MaxFee)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee schedule: %w", err)
+	}
+
+	return s.Calculate(amount)
+}
+
+// Entries returns the ledger entry that credits fee to the platform wallet,
+// or none when there is no fee. The caller debits the payer for the fee on
+// top of the amount so the entries stay balanced.
+func Entries(fee money.Money) []ledger.Entry {
+	if fee <= 0 {
+		return nil
+	}
+	return []ledger.Entry{ledger.Credit(ledger.AccountWallet, PlatformWalletId, fee)}
+}
+
+// WalletIds returns the wallets Entries(fee) posts to. A caller that locks
+// its own wallets with ledger.LockWallets before posting must lock these in
+// the same call, so the whole set is taken in ascending order.
+func WalletIds(fee money.Money) []string {
+	if fee <= 0 {
+		return nil
+	}
+	return []string{PlatformWalletId}
+}
+
+// RefundEntries returns the ledger entry that takes fee back from the
+// platform wallet, or none when there is no fee. The caller credits it to
+// the payer on top of the refunded amount.
+func RefundEntries(fee money.Money) []ledger.Entry {
+	if fee <= 0 {
+		return nil
+	}
+	return []ledger.Entry{ledger.Debit(ledger.AccountWallet, PlatformWalletId, fee)}
+}
@@ -0,0 +1,81 @@
+package fees_test
+
+import (
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func moneyPtr(m money.Money) *money.Money {
+	return &m
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule fees.Schedule
+		amount   money.Money
+		want     money.Money
+	}{
+		{"flat", fees.Schedule{FeeType: fees.TypeFlat, FlatAmount: 2500}, 100000, 2500},
+		{"percentage rounds half up", fees.Schedule{FeeType: fees.TypePercentage, PercentageBps: 70}, 150050, 1050},
+		{"percentage below min", fees.Schedule{FeeType: fees.TypePercentage, PercentageBps: 290, MinFee: moneyPtr(2000)}, 10000, 2000},
+		{"percentage above max", fees.Schedule{FeeType: fees.TypePercentage, PercentageBps: 100, MaxFee: moneyPtr(5000)}, 1000000, 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := tt.schedule.Calculate(tt.amount)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, fee)
+		})
+	}
+
+	_, err := fees.Schedule{FeeType: "tiered"}.Calculate(1000)
+	assert.ErrorIs(t, err, fees.ErrInvalidSchedule)
+}
+
+func TestQuote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"fee_type", "flat_amount", "percentage_bps", "min_fee", "max_fee"}
+	mock.ExpectQuery("SELECT fee_type, flat_amount, percentage_bps, min_fee, max_fee FROM fee_schedules").
+		WithArgs("topup", "pm1").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("percentage", "0.00", 290, "2000.00", nil))
+	mock.ExpectQuery("SELECT fee_type, flat_amount, percentage_bps, min_fee, max_fee FROM fee_schedules").
+		WithArgs("transfer", nil).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	fee, err := fees.Quote(db, "topup", "pm1", 100000)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money(2900), fee)
+
+	fee, err = fees.Quote(db, "transfer", "", 100000)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money(0), fee)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntries(t *testing.T) {
+	assert.Empty(t, fees.Entries(0))
+	assert.Equal(t, []ledger.Entry{ledger.Credit(ledger.AccountWallet, fees.PlatformWalletId, 1500)}, fees.Entries(1500))
+}
+
+func TestWalletIds(t *testing.T) {
+	assert.Empty(t, fees.WalletIds(0))
+	assert.Equal(t, []string{fees.PlatformWalletId}, fees.WalletIds(1500))
+}
+
+func TestRefundEntries(t *testing.T) {
+	assert.Empty(t, fees.RefundEntries(0))
+	assert.Equal(t, []ledger.Entry{ledger.Debit(ledger.AccountWallet, fees.PlatformWalletId, 1500)}, fees.RefundEntries(1500))
+}
@@ -0,0 +1,111 @@
+package gatewayReport
+
+import (
+	"encoding/csv"
+	"errors"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrMissingColumn = errors.New("settlement report is missing a required column")
+	ErrInvalidLine   = errors.New("invalid settlement report line")
+	ErrEmptyReport   = errors.New("settlement report has no lines")
+)
+
+// Line is one transaction of a gateway settlement report. Number is the
+// line in the file, counting the header as line 1.
+type Line struct {
+	Number      int
+	OrderId     string
+	GrossAmount money.Money
+	Status      string
+}
+
+// Header names accepted for each column, compared case-insensitively with
+// spaces and underscores ignored, so both the dashboard export ("Order ID")
+// and the API field names ("order_id") work.
+var columns = map[string][]string{
+	"order":  {"orderid"},
+	"amount": {"grossamount", "amount"},
+	"status": {"transactionstatus", "status"},
+}
+
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, " ", "")
+	return strings.ReplaceAll(name, "_", "")
+}
+
+// Parse reads a settlement report CSV. Columns other than order id, gross
+// amount and transaction status are ignored, and blank lines are skipped.
+func Parse(r io.Reader) ([]Line, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyReport
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLine, err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		name = normalize(strings.TrimPrefix(name, "\ufeff"))
+		for column, aliases := range columns {
+			if _, found := index[column]; found {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					index[column] = i
+				}
+			}
+		}
+	}
+	for column := range columns {
+		if _, found := index[column]; !found {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, column)
+		}
+	}
+
+	var lines []Line
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLine, err)
+		}
+		number, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) <= index["order"] || len(record) <= index["amount"] || len(record) <= index["status"] {
+			return nil, fmt.Errorf("%w %d: too few fields", ErrInvalidLine, number)
+		}
+
+		line := Line{
+			Number:  number,
+			OrderId: strings.TrimSpace(record[index["order"]]),
+			Status:  strings.ToLower(strings.TrimSpace(record[index["status"]])),
+		}
+		amount, err := money.Parse(strings.TrimSpace(record[index["amount"]]))
+		if err != nil || line.OrderId == "" {
+			return nil, fmt.Errorf("%w %d: order id and gross amount are required", ErrInvalidLine, number)
+		}
+		line.GrossAmount = amount
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyReport
+	}
+	return lines, nil
+}
@@ -0,0 +1,34 @@
+package gatewayReport_test
+
+import (
+	"final-project-enigma/pkg/gatewayReport"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	report := "Order ID,Payment Type,Gross Amount,Transaction Status\n" +
+		"order-1,bank_transfer,101000.00,Settlement\n" +
+		"\n" +
+		"order-2,qris,50350,expire\n"
+
+	lines, err := gatewayReport.Parse(strings.NewReader(report))
+	assert.NoError(t, err)
+	assert.Equal(t, []gatewayReport.Line{
+		{Number: 2, OrderId: "order-1", GrossAmount: 101000, Status: "settlement"},
+		{Number: 4, OrderId: "order-2", GrossAmount: 50350, Status: "expire"},
+	}, lines)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := gatewayReport.Parse(strings.NewReader("order_id,status\norder-1,settlement\n"))
+	assert.ErrorIs(t, err, gatewayReport.ErrMissingColumn)
+
+	_, err = gatewayReport.Parse(strings.NewReader("order_id,gross_amount,transaction_status\norder-1,abc,settlement\n"))
+	assert.ErrorIs(t, err, gatewayReport.ErrInvalidLine)
+
+	_, err = gatewayReport.Parse(strings.NewReader("order_id,gross_amount,transaction_status\n"))
+	assert.ErrorIs(t, err, gatewayReport.ErrEmptyReport)
+}
@@ -0,0 +1,47 @@
+package merchantCallback
+
+import (
+	"final-project-enigma/pkg/helper/merchantSignature"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// HTTPSender posts callbacks to merchants, signed the same way merchants sign
+// their requests to us so they can verify them with the same code.
+type HTTPSender struct {
+	client *resty.Client
+}
+
+func NewHTTPSender(client *resty.Client) *HTTPSender {
+	return &HTTPSender{
+		client: client,
+	}
+}
+
+func (s *HTTPSender) Send(callbackUrl, keyId, secret string, body []byte) error {
+	parsed, err := url.Parse(callbackUrl)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	resp, err := s.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(merchantSignature.HeaderApiKey, keyId).
+		SetHeader(merchantSignature.HeaderTimestamp, timestamp).
+		SetHeader(merchantSignature.HeaderSignature, merchantSignature.Generate(secret, timestamp, http.MethodPost, parsed.EscapedPath(), body)).
+		SetBody(body).
+		Post(callbackUrl)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return fmt.Errorf("merchant answered %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package merchantSignature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Headers a partner merchant sends with every signed request, and that the
+// wallet sends with every callback.
+const (
+	HeaderApiKey    = "X-Api-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderSignature = "X-Signature"
+)
+
+// MaxClockSkew is how far the request timestamp may be from the server clock.
+// It bounds how long a captured request can be replayed.
+const MaxClockSkew = 5 * time.Minute
+
+// Generate signs a request: HMAC-SHA256 with the key secret over
+// timestamp + "\n" + method + "\n" + path + "\n" + body, hex encoded.
+func Generate(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and that timestamp, in Unix seconds, is within
+// MaxClockSkew of now.
+func Verify(signature, secret, timestamp, method, path string, body []byte, now time.Time) bool {
+	if signature == "" || secret == "" {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return false
+	}
+
+	expected := Generate(secret, timestamp, method, path, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,25 @@
+package merchantSignature_test
+
+import (
+	"final-project-enigma/pkg/helper/merchantSignature"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"amount":25000}`)
+	signature := merchantSignature.Generate("secret", timestamp, "POST", "/api/v1/merchant/charges", body)
+
+	assert.True(t, merchantSignature.Verify(signature, "secret", timestamp, "POST", "/api/v1/merchant/charges", body, now))
+	assert.True(t, merchantSignature.Verify(signature, "secret", timestamp, "POST", "/api/v1/merchant/charges", body, now.Add(4*time.Minute)))
+	assert.False(t, merchantSignature.Verify(signature, "secret", timestamp, "POST", "/api/v1/merchant/charges", body, now.Add(6*time.Minute)))
+	assert.False(t, merchantSignature.Verify(signature, "secret", timestamp, "POST", "/api/v1/merchant/charges", []byte(`{"amount":1}`), now))
+	assert.False(t, merchantSignature.Verify(signature, "other", timestamp, "POST", "/api/v1/merchant/charges", body, now))
+	assert.False(t, merchantSignature.Verify(signature, "secret", "not-a-time", "POST", "/api/v1/merchant/charges", body, now))
+	assert.False(t, merchantSignature.Verify("", "secret", timestamp, "POST", "/api/v1/merchant/charges", body, now))
+}
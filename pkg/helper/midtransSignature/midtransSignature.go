@@ -0,0 +1,22 @@
+package midtransSignature
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// Generate builds the signature_key Midtrans attaches to HTTP notifications:
+// SHA-512 of order_id + status_code + gross_amount + server key, hex encoded.
+func Generate(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func Verify(signatureKey, orderID, statusCode, grossAmount, serverKey string) bool {
+	if signatureKey == "" || serverKey == "" {
+		return false
+	}
+	expected := Generate(orderID, statusCode, grossAmount, serverKey)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signatureKey)) == 1
+}
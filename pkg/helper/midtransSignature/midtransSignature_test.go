@@ -0,0 +1,18 @@
+package midtransSignature_test
+
+import (
+	"final-project-enigma/pkg/helper/midtransSignature"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	signature := midtransSignature.Generate("order-1", "200", "100000.00", "server-key")
+
+	assert.True(t, midtransSignature.Verify(signature, "order-1", "200", "100000.00", "server-key"))
+	assert.False(t, midtransSignature.Verify(signature, "order-1", "200", "999999.00", "server-key"))
+	assert.False(t, midtransSignature.Verify(signature, "order-1", "200", "100000.00", "other-key"))
+	assert.False(t, midtransSignature.Verify("", "order-1", "200", "100000.00", "server-key"))
+	assert.False(t, midtransSignature.Verify(signature, "order-1", "200", "100000.00", ""))
+}
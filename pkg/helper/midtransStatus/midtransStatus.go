@@ -0,0 +1,100 @@
+package midtransStatus
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"final-project-enigma/model/dto/userDto"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const defaultBaseURL = "https://api.sandbox.midtrans.com"
+
+// ErrOrderNotFound means Midtrans has no transaction for the order, which
+// happens when the user never picked a payment method on the Snap page.
+var ErrOrderNotFound = errors.New("order not found at the payment gateway")
+
+// Client reads transaction statuses from the Midtrans Core API.
+type Client struct {
+	client    *resty.Client
+	baseURL   string
+	serverKey string
+}
+
+// New reads MIDTRANS_API_BASE_URL and MIDTRANS_SERVER_KEY.
+func New(client *resty.Client) *Client {
+	baseURL := os.Getenv("MIDTRANS_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return NewClient(client, baseURL, os.Getenv("MIDTRANS_SERVER_KEY"))
+}
+
+func NewClient(client *resty.Client, baseURL, serverKey string) *Client {
+	return &Client{
+		client:    client,
+		baseURL:   baseURL,
+		serverKey: serverKey,
+	}
+}
+
+// TransactionStatus returns the status of orderID in the same shape as an
+// HTTP notification, so it can be applied the same way.
+func (c *Client) TransactionStatus(orderID string) (userDto.MidtransNotification, error) {
+	encodeKey := base64.StdEncoding.EncodeToString([]byte(c.serverKey + ":"))
+	resp, err := c.client.R().
+		SetHeader("Authorization", "Basic "+encodeKey).
+		SetHeader("Accept", "application/json").
+		Get(c.baseURL + "/v2/" + orderID + "/status")
+	if err != nil {
+		return userDto.MidtransNotification{}, err
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return userDto.MidtransNotification{}, ErrOrderNotFound
+	}
+
+	var status userDto.MidtransNotification
+	if err := json.Unmarshal(resp.Body(), &status); err != nil {
+		return userDto.MidtransNotification{}, fmt.Errorf("failed to read midtrans status (%d): %w", resp.StatusCode(), err)
+	}
+	// Midtrans answers unknown orders with HTTP 200 and a 404 status_code.
+	if status.StatusCode == "404" {
+		return userDto.MidtransNotification{}, ErrOrderNotFound
+	}
+	if resp.StatusCode() != http.StatusOK || status.TransactionStatus == "" {
+		return userDto.MidtransNotification{}, fmt.Errorf("midtrans answered %d with status code %s", resp.StatusCode(), status.StatusCode)
+	}
+
+	return status, nil
+}
+
+// Fake answers from Statuses, keyed by order id, instead of calling
+// Midtrans. Orders it does not know are reported as not found.
+type Fake struct {
+	mu       sync.Mutex
+	Statuses map[string]userDto.MidtransNotification
+	Err      error
+}
+
+func NewFake() *Fake {
+	return &Fake{Statuses: map[string]userDto.MidtransNotification{}}
+}
+
+func (f *Fake) TransactionStatus(orderID string) (userDto.MidtransNotification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return userDto.MidtransNotification{}, f.Err
+	}
+	status, ok := f.Statuses[orderID]
+	if !ok {
+		return userDto.MidtransNotification{}, ErrOrderNotFound
+	}
+	return status, nil
+}
@@ -63,3 +63,21 @@ func SendEmailForgotPin(email, username, code, unique string) error {
 	}
 	return nil
 }
+
+// EmailNotifier sends plain-text notifications, such as scheduled transfer
+// results, from the same account as the other emails.
+type EmailNotifier struct{}
+
+func (EmailNotifier) Notify(email, subject, message string) error {
+	emailPort, _ := strconv.Atoi(os.Getenv("EMAIL_PORT"))
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("EMAIL_ADDRESS"))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", message)
+
+	d := gomail.NewDialer(os.Getenv("EMAIL_HOST"), emailPort, os.Getenv("EMAIL_ADDRESS"), os.Getenv("EMAIL_PASSWORD"))
+
+	return d.DialAndSend(m)
+}
@@ -0,0 +1,151 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const HeaderKey = "Idempotency-Key"
+
+var ErrKeyNotFound = errors.New("idempotency key not found")
+
+// Record is a stored idempotency key. A record without CompletedAt is still
+// being processed by the request that reserved it.
+type Record struct {
+	UserId       string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+type Store interface {
+	// Reserve claims the key for a new request. When the key already exists
+	// and is younger than the retention window it returns the existing record
+	// and reserved is false. A reservation that was never completed or
+	// released is given up after the store's processing timeout, so a request
+	// that died half-way does not block its retries for the whole retention.
+	Reserve(userId, key, requestHash string, retention time.Duration) (existing Record, reserved bool, err error)
+	Complete(userId, key string, statusCode int, responseBody []byte) error
+	Release(userId, key string) error
+}
+
+func HashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ProcessingTimeout reads IDEMPOTENCY_PROCESSING_TIMEOUT, how long a key may
+// stay reserved by a request that has not finished, defaulting to two
+// minutes. It must be longer than any request takes, gateway calls included,
+// or a slow request and its retry would both run.
+func ProcessingTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_PROCESSING_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return 2 * time.Minute
+	}
+	return timeout
+}
+
+type sqlStore struct {
+	db                *sql.DB
+	processingTimeout time.Duration
+}
+
+func NewSQLStore(db *sql.DB) Store {
+	return &sqlStore{
+		db:                db,
+		processingTimeout: ProcessingTimeout(),
+	}
+}
+
+func (s *sqlStore) Reserve(userId, key, requestHash string, retention time.Duration) (existing Record, reserved bool, err error) {
+	currentTime := time.Now()
+
+	expiredQuery := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+		AND (created_at < $3 OR (completed_at IS NULL AND created_at < $4))
+	`
+	if _, err := s.db.Exec(expiredQuery, userId, key, currentTime.Add(-retention), currentTime.Add(-s.processingTimeout)); err != nil {
+		log.Error().Msg("failed to purge expired idempotency key: " + err.Error())
+		return existing, false, err
+	}
+
+	insertQuery := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+	`
+	res, err := s.db.Exec(insertQuery, userId, key, requestHash, currentTime)
+	if err != nil {
+		log.Error().Msg("failed to reserve idempotency key: " + err.Error())
+		return existing, false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return existing, false, err
+	}
+	if rowsAffected == 1 {
+		return existing, true, nil
+	}
+
+	selectQuery := `
+		SELECT user_id, idempotency_key, request_hash, status_code, response_body, created_at, completed_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+	var statusCode sql.NullInt64
+	var responseBody sql.NullString
+	var completedAt sql.NullTime
+	err = s.db.QueryRow(selectQuery, userId, key).Scan(&existing.UserId, &existing.Key, &existing.RequestHash, &statusCode, &responseBody, &existing.CreatedAt, &completedAt)
+	if err == sql.ErrNoRows {
+		return existing, false, ErrKeyNotFound
+	}
+	if err != nil {
+		return existing, false, err
+	}
+
+	existing.StatusCode = int(statusCode.Int64)
+	existing.ResponseBody = []byte(responseBody.String)
+	if completedAt.Valid {
+		existing.CompletedAt = &completedAt.Time
+	}
+
+	return existing, false, nil
+}
+
+func (s *sqlStore) Complete(userId, key string, statusCode int, responseBody []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2, completed_at = $3
+		WHERE user_id = $4 AND idempotency_key = $5
+	`
+	if _, err := s.db.Exec(query, statusCode, string(responseBody), time.Now(), userId, key); err != nil {
+		log.Error().Msg("failed to store idempotent response: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *sqlStore) Release(userId, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND completed_at IS NULL`
+	if _, err := s.db.Exec(query, userId, key); err != nil {
+		log.Error().Msg("failed to release idempotency key: " + err.Error())
+		return err
+	}
+	return nil
+}
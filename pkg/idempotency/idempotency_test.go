@@ -0,0 +1,48 @@
+package idempotency_test
+
+import (
+	"database/sql/driver"
+	"final-project-enigma/pkg/idempotency"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// agoArg matches a time that lies the given duration before now.
+type agoArg time.Duration
+
+func (a agoArg) Match(v driver.Value) bool {
+	at, ok := v.(time.Time)
+	return ok && time.Since(at)-time.Duration(a) < time.Second
+}
+
+func TestReserve_TakesOverStaleReservation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("IDEMPOTENCY_PROCESSING_TIMEOUT", "30s")
+	store := idempotency.NewSQLStore(db)
+
+	mock.ExpectExec("DELETE FROM idempotency_keys").
+		WithArgs("user-1", "key-1", agoArg(24*time.Hour), agoArg(30*time.Second)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs("user-1", "key-1", "hash", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, reserved, err := store.Reserve("user-1", "key-1", "hash", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessingTimeout(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_PROCESSING_TIMEOUT", "")
+	assert.Equal(t, 2*time.Minute, idempotency.ProcessingTimeout())
+
+	t.Setenv("IDEMPOTENCY_PROCESSING_TIMEOUT", "45s")
+	assert.Equal(t, 45*time.Second, idempotency.ProcessingTimeout())
+}
@@ -0,0 +1,264 @@
+package ledger
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Account types that can appear on a ledger entry. Wallet accounts are the
+// only ones with a cached balance (wallets.balance); the others are
+// counter-accounts that exist only in ledger_entries. Merchants are paid into
+// wallets now, so AccountMerchant only appears on older entries.
+// AccountSettlement holds what was paid out to a merchant's bank account,
+// AccountHold what a pending withdrawal reserved (account id is the
+// withdrawal id) and AccountDisbursement what a payout provider sent out.
+// AccountFX is the clearing account of a currency conversion; its account id
+// is the currency code, so each side of a conversion balances on its own.
+const (
+	AccountWallet         = "wallet"
+	AccountMerchant       = "merchant"
+	AccountPaymentGateway = "payment_gateway"
+	AccountFeeRevenue     = "fee_revenue"
+	AccountSettlement     = "settlement"
+	AccountHold           = "hold"
+	AccountDisbursement   = "disbursement"
+	AccountFX             = "fx"
+
+	EntryDebit  = "debit"
+	EntryCredit = "credit"
+
+	// Wallet statuses. A frozen_debit wallet can still receive money but not
+	// send any; a frozen_all wallet can do neither.
+	WalletActive      = "active"
+	WalletFrozenDebit = "frozen_debit"
+	WalletFrozenAll   = "frozen_all"
+
+	// GatewayMidtrans is the account id used for money arriving from Midtrans.
+	GatewayMidtrans = "midtrans"
+)
+
+var (
+	ErrUnbalanced          = errors.New("ledger entries are not balanced")
+	ErrInvalidEntry        = errors.New("invalid ledger entry")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrCurrencyMismatch    = errors.New("entries in different currencies must go through a conversion")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+)
+
+// Entry is one side of a transaction. Owed marks a credit of money the user
+// is already owed, see CreditOwed.
+type Entry struct {
+	AccountType string
+	AccountId   string
+	EntryType   string
+	Amount      money.Money
+	Owed        bool
+}
+
+func Debit(accountType, accountId string, amount money.Money) Entry {
+	return Entry{AccountType: accountType, AccountId: accountId, EntryType: EntryDebit, Amount: amount}
+}
+
+func Credit(accountType, accountId string, amount money.Money) Entry {
+	return Entry{AccountType: accountType, AccountId: accountId, EntryType: EntryCredit, Amount: amount}
+}
+
+// CreditOwed credits money the user has already paid or that is being given
+// back to them, such as a settled top-up or released withdrawal funds. Unlike
+// Credit it lands on a frozen_all wallet too, where it stays until the wallet
+// is unfrozen.
+func CreditOwed(accountType, accountId string, amount money.Money) Entry {
+	return Entry{AccountType: accountType, AccountId: accountId, EntryType: EntryCredit, Amount: amount, Owed: true}
+}
+
+func Validate(entries []Entry) error {
+	if len(entries) < 2 {
+		return ErrUnbalanced
+	}
+
+	var totalDebit, totalCredit money.Money
+	for _, e := range entries {
+		if e.AccountType == "" || e.AccountId == "" || e.Amount <= 0 {
+			return ErrInvalidEntry
+		}
+		switch e.EntryType {
+		case EntryDebit:
+			totalDebit += e.Amount
+		case EntryCredit:
+			totalCredit += e.Amount
+		default:
+			return ErrInvalidEntry
+		}
+	}
+
+	if totalDebit != totalCredit {
+		return ErrUnbalanced
+	}
+	return nil
+}
+
+// LockWallets takes row locks on the given wallets and returns their current
+// balances. Locks are always taken in ascending wallet-ID order so two
+// transactions touching the same pair of wallets cannot deadlock each other.
+// Call it before reading a balance that a later Post depends on.
+func LockWallets(tx *sql.Tx, walletIds ...string) (map[string]money.Money, error) {
+	ids := make([]string, 0, len(walletIds))
+	seen := make(map[string]bool, len(walletIds))
+	for _, id := range walletIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	balances := make(map[string]money.Money, len(ids))
+	query := `SELECT balance FROM wallets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	for _, id := range ids {
+		var balance money.Money
+		err := tx.QueryRow(query, id).Scan(&balance)
+		if err == sql.ErrNoRows {
+			log.Error().Msg("wallet not found")
+			return nil, ErrWalletNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		balances[id] = balance
+	}
+
+	return balances, nil
+}
+
+// Post writes a balanced set of entries for a transaction and applies them to
+// the cached balance of every wallet account involved. A debit on a wallet
+// decreases its balance and fails with ErrInsufficientBalance instead of going
+// negative; a credit increases it. Entries must also balance within each
+// currency: wallets are in their own currency, AccountFX in the one named by
+// its id and every other account in money.Currency. Otherwise Post fails with
+// ErrCurrencyMismatch. Debiting a wallet that is not active, or crediting a
+// frozen_all one other than with CreditOwed, fails with ErrWalletFrozen. It
+// must run inside the caller's DB transaction so the entries and the balances
+// commit together.
+func Post(tx *sql.Tx, transactionId string, entries ...Entry) error {
+	if err := Validate(entries); err != nil {
+		log.Error().Msg(err.Error())
+		return err
+	}
+
+	var walletIds []string
+	for _, e := range entries {
+		if e.AccountType == AccountWallet {
+			walletIds = append(walletIds, e.AccountId)
+		}
+	}
+	if _, err := LockWallets(tx, walletIds...); err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	net := make(map[string]money.Money)
+
+	for _, e := range entries {
+		var balanceAfter sql.NullString
+		currency := money.Currency
+		if e.AccountType == AccountFX {
+			currency = e.AccountId
+		}
+
+		if e.AccountType == AccountWallet {
+			var err error
+			balanceAfter.String, currency, err = applyToWallet(tx, e, currentTime)
+			if err != nil {
+				return err
+			}
+			balanceAfter.Valid = true
+		}
+
+		if e.EntryType == EntryDebit {
+			net[currency] += e.Amount
+		} else {
+			net[currency] -= e.Amount
+		}
+
+		insertQuery := `
+			INSERT INTO ledger_entries (transaction_id, account_type, account_id, entry_type, amount, balance_after, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		if _, err := tx.Exec(insertQuery, transactionId, e.AccountType, e.AccountId, e.EntryType, e.Amount, balanceAfter, currentTime); err != nil {
+			log.Error().Msg("failed to insert ledger entry: " + err.Error())
+			return fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+	}
+
+	for _, amount := range net {
+		if amount != 0 {
+			log.Error().Msg(ErrCurrencyMismatch.Error())
+			return ErrCurrencyMismatch
+		}
+	}
+
+	return nil
+}
+
+// applyToWallet returns the wallet's balance after the entry and its currency.
+// The status is checked after the update; the caller's transaction rolls the
+// update back when the wallet turns out to be frozen.
+func applyToWallet(tx *sql.Tx, e Entry, currentTime time.Time) (string, string, error) {
+	var query string
+	if e.EntryType == EntryDebit {
+		query = `
+			UPDATE wallets
+			SET balance = balance - $1, updated_at = $2
+			WHERE id = $3 AND balance >= $1
+			RETURNING balance, currency, status
+		`
+	} else {
+		query = `
+			UPDATE wallets
+			SET balance = balance + $1, updated_at = $2
+			WHERE id = $3
+			RETURNING balance, currency, status
+		`
+	}
+
+	var balance, currency, status string
+	err := tx.QueryRow(query, e.Amount, currentTime, e.AccountId).Scan(&balance, &currency, &status)
+	if err == sql.ErrNoRows {
+		if e.EntryType == EntryDebit {
+			log.Error().Msg("insufficient balance")
+			return "", "", ErrInsufficientBalance
+		}
+		log.Error().Msg("wallet not found")
+		return "", "", ErrWalletNotFound
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+	if !e.Owed && !CanMove(status, e.EntryType) {
+		log.Error().Msg("wallet " + e.AccountId + " is " + status)
+		return "", "", ErrWalletFrozen
+	}
+
+	return balance, currency, nil
+}
+
+// CanMove reports whether a wallet in the given status accepts an entry of
+// entryType.
+func CanMove(status, entryType string) bool {
+	switch status {
+	case WalletActive:
+		return true
+	case WalletFrozenDebit:
+		return entryType == EntryCredit
+	default:
+		return false
+	}
+}
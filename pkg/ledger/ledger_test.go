@@ -0,0 +1,229 @@
+package ledger_test
+
+import (
+	"final-project-enigma/pkg/ledger"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	err := ledger.Validate([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountWallet, "w2", 100),
+	})
+	assert.NoError(t, err)
+
+	err = ledger.Validate([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountWallet, "w2", 90),
+	})
+	assert.ErrorIs(t, err, ledger.ErrUnbalanced)
+
+	err = ledger.Validate([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+	})
+	assert.ErrorIs(t, err, ledger.ErrUnbalanced)
+
+	err = ledger.Validate([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, "w1", 0),
+		ledger.Credit(ledger.AccountWallet, "w2", 0),
+	})
+	assert.ErrorIs(t, err, ledger.ErrInvalidEntry)
+}
+
+func TestPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("150.00"))
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w2").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("0.00"))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance - \\$1, updated_at = \\$2 WHERE id = \\$3 AND balance >= \\$1 RETURNING balance, currency, status").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("50.00", "IDR", "active"))
+	mock.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("trx1", ledger.AccountWallet, "w1", ledger.EntryDebit, int64(100), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = \\$2 WHERE id = \\$3 RETURNING balance, currency, status").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w2").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("100.00", "IDR", "active"))
+	mock.ExpectExec("INSERT INTO ledger_entries").
+		WithArgs("trx1", ledger.AccountWallet, "w2", ledger.EntryCredit, int64(100), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx1",
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountWallet, "w2", 100),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPost_InsufficientBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("50.00"))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance - \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx1",
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountMerchant, "m1", 100),
+	)
+	assert.ErrorIs(t, err, ledger.ErrInsufficientBalance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPost_CurrencyMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("150.00"))
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w2").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("0.00"))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance - \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("50.00", "IDR", "active"))
+	mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance \\+ \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w2").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("100.00", "USD", "active"))
+	mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx1",
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountWallet, "w2", 100),
+	)
+	assert.ErrorIs(t, err, ledger.ErrCurrencyMismatch)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPost_FrozenWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("150.00"))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance - \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("50.00", "IDR", "frozen_debit"))
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx1",
+		ledger.Debit(ledger.AccountWallet, "w1", 100),
+		ledger.Credit(ledger.AccountMerchant, "m1", 100),
+	)
+	assert.ErrorIs(t, err, ledger.ErrWalletFrozen)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPost_OwedCreditToFrozenWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("0.00"))
+	mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance \\+ \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("100.00", "IDR", "frozen_all"))
+	mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("100.00"))
+	mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance \\+ \\$1").
+		WithArgs(int64(100), sqlmock.AnyArg(), "w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("200.00", "IDR", "frozen_all"))
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx1",
+		ledger.Debit(ledger.AccountPaymentGateway, ledger.GatewayMidtrans, 100),
+		ledger.CreditOwed(ledger.AccountWallet, "w1", 100),
+	)
+	assert.NoError(t, err)
+
+	err = ledger.Post(tx, "trx2",
+		ledger.Debit(ledger.AccountPaymentGateway, ledger.GatewayMidtrans, 100),
+		ledger.Credit(ledger.AccountWallet, "w1", 100),
+	)
+	assert.ErrorIs(t, err, ledger.ErrWalletFrozen)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCanMove(t *testing.T) {
+	assert.True(t, ledger.CanMove(ledger.WalletActive, ledger.EntryDebit))
+	assert.True(t, ledger.CanMove(ledger.WalletFrozenDebit, ledger.EntryCredit))
+	assert.False(t, ledger.CanMove(ledger.WalletFrozenDebit, ledger.EntryDebit))
+	assert.False(t, ledger.CanMove(ledger.WalletFrozenAll, ledger.EntryCredit))
+}
+
+func TestLockWallets_SortedOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	for _, id := range []string{"a-wallet", "b-wallet", "c-wallet"} {
+		mock.ExpectQuery("SELECT balance FROM wallets WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("10.00"))
+	}
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	balances, err := ledger.LockWallets(tx, "c-wallet", "a-wallet", "b-wallet", "a-wallet")
+	assert.NoError(t, err)
+	assert.Len(t, balances, 3)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
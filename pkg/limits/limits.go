@@ -0,0 +1,154 @@
+package limits
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Account tiers and the transaction types a tier can cap.
+const (
+	TierUnverified = "unverified"
+	TierVerified   = "verified"
+
+	TypeTopUp           = "topup"
+	TypeTransfer        = "transfer"
+	TypeMerchantPayment = "merchant_payment"
+	TypeWithdrawal      = "withdrawal"
+)
+
+var (
+	ErrPerTransactionLimit = errors.New("amount exceeds the per-transaction limit")
+	ErrDailyLimit          = errors.New("amount exceeds the daily limit")
+	ErrMonthlyLimit        = errors.New("amount exceeds the monthly limit")
+	ErrMaxBalance          = errors.New("amount would exceed the maximum wallet balance")
+	ErrUnknownType         = errors.New("unknown transaction type")
+)
+
+// usageJoins holds, per transaction type, the detail table that identifies a
+// transactions row as that type.
+var usageJoins = map[string]string{
+	TypeTopUp:           "topup_transactions",
+	TypeTransfer:        "wallet_transactions",
+	TypeMerchantPayment: "merchant_transactions",
+	TypeWithdrawal:      "withdrawals",
+}
+
+// usageFilters narrows the usage of a transaction type down to what its caps
+// are about. A move between two pockets of the same user, including a
+// currency conversion, is recorded as a wallet transfer but sends nothing to
+// anyone, so it is left out.
+var usageFilters = map[string]string{
+	TypeTransfer: `AND NOT EXISTS (
+			SELECT 1 FROM wallets fw JOIN wallets tw ON tw.user_id = fw.user_id
+			WHERE fw.id = d.from_wallet_id AND tw.id = d.to_wallet_id
+		)`,
+}
+
+// Check fails when amount would push userId past one of the caps configured
+// for their tier and txType. A missing cap means no limit. It must run in the
+// same DB transaction that records the new transaction, after the user's
+// wallet is locked, so concurrent requests count each other's totals.
+func Check(tx *sql.Tx, userId, txType string, amount money.Money) error {
+	detailTable, ok := usageJoins[txType]
+	if !ok {
+		return ErrUnknownType
+	}
+
+	var perTransaction, daily, monthly *money.Money
+	limitQuery := `
+		SELECT l.per_transaction, l.daily, l.monthly
+		FROM users u
+		JOIN transaction_limits l ON l.tier = u.tier AND l.transaction_type = $2
+		WHERE u.id = $1
+	`
+	err := tx.QueryRow(limitQuery, userId, txType).Scan(&perTransaction, &daily, &monthly)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get transaction limits: %w", err)
+	}
+
+	if perTransaction != nil && amount > *perTransaction {
+		log.Error().Msg(ErrPerTransactionLimit.Error())
+		return ErrPerTransactionLimit
+	}
+	if daily == nil && monthly == nil {
+		return nil
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	// Pending top-ups count too, otherwise a user could open many Snap
+	// payments at once and settle all of them past the cap. Caps are in
+	// rupiah, so amounts in other currencies, such as the source side of a
+	// currency conversion, are never added to them.
+	var usedToday, usedThisMonth money.Money
+	usageQuery := `
+		SELECT
+			COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= $3), 0),
+			COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		JOIN ` + detailTable + ` d ON d.transaction_id = t.id
+		WHERE t.user_id = $1
+			AND t.created_at >= $2
+			AND t.reversal_of IS NULL
+			AND t.status IN ('success', 'pending')
+			AND t.currency = $4
+			` + usageFilters[txType] + `
+	`
+	err = tx.QueryRow(usageQuery, userId, startOfMonth, startOfDay, money.Currency).Scan(&usedToday, &usedThisMonth)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction usage: %w", err)
+	}
+
+	if daily != nil && usedToday+amount > *daily {
+		log.Error().Msg(ErrDailyLimit.Error())
+		return ErrDailyLimit
+	}
+	if monthly != nil && usedThisMonth+amount > *monthly {
+		log.Error().Msg(ErrMonthlyLimit.Error())
+		return ErrMonthlyLimit
+	}
+
+	return nil
+}
+
+// CheckBalance fails when crediting amount to walletId would take its owner
+// over the maximum balance of their tier. The maximum covers all of the
+// owner's open IDR pockets together; tiers say nothing about foreign currency
+// pockets, so crediting one is never limited. The wallet should already be
+// locked.
+func CheckBalance(tx *sql.Tx, walletId string, amount money.Money) error {
+	var balance money.Money
+	var maxBalance *money.Money
+	query := `
+		SELECT SUM(p.balance), at.max_balance
+		FROM wallets w
+		JOIN users u ON u.id = w.user_id
+		JOIN account_tiers at ON at.name = u.tier
+		JOIN wallets p ON p.user_id = w.user_id AND p.currency = w.currency AND p.deleted_at IS NULL
+		WHERE w.id = $1 AND w.currency = 'IDR'
+		GROUP BY at.max_balance
+	`
+	err := tx.QueryRow(query, walletId).Scan(&balance, &maxBalance)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get maximum balance: %w", err)
+	}
+
+	if maxBalance != nil && balance+amount > *maxBalance {
+		log.Error().Msg(ErrMaxBalance.Error())
+		return ErrMaxBalance
+	}
+	return nil
+}
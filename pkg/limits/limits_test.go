@@ -0,0 +1,128 @@
+package limits_test
+
+import (
+	"database/sql"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/money"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func beginTx(t *testing.T) (*sql.Tx, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, mock, func() { db.Close() }
+}
+
+func TestCheck(t *testing.T) {
+	limitColumns := []string{"per_transaction", "daily", "monthly"}
+
+	t.Run("no limits configured", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeTransfer).
+			WillReturnRows(sqlmock.NewRows(limitColumns))
+
+		assert.NoError(t, limits.Check(tx, "u1", limits.TypeTransfer, money.Money(5000000)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("per transaction cap", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeTransfer).
+			WillReturnRows(sqlmock.NewRows(limitColumns).AddRow("1000000.00", nil, nil))
+
+		err := limits.Check(tx, "u1", limits.TypeTransfer, money.Money(1000001))
+		assert.ErrorIs(t, err, limits.ErrPerTransactionLimit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("daily cap counts earlier transactions", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeMerchantPayment).
+			WillReturnRows(sqlmock.NewRows(limitColumns).AddRow("1000000.00", "2000000.00", nil))
+		mock.ExpectQuery("FROM transactions t JOIN merchant_transactions d").
+			WillReturnRows(sqlmock.NewRows([]string{"today", "month"}).AddRow("1500000.00", "1500000.00"))
+
+		err := limits.Check(tx, "u1", limits.TypeMerchantPayment, money.Money(600000))
+		assert.ErrorIs(t, err, limits.ErrDailyLimit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("monthly cap", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeTopUp).
+			WillReturnRows(sqlmock.NewRows(limitColumns).AddRow(nil, "2000000.00", "20000000.00"))
+		mock.ExpectQuery("FROM transactions t JOIN topup_transactions d").
+			WillReturnRows(sqlmock.NewRows([]string{"today", "month"}).AddRow("0.00", "19500000.00"))
+
+		err := limits.Check(tx, "u1", limits.TypeTopUp, money.Money(600000))
+		assert.ErrorIs(t, err, limits.ErrMonthlyLimit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("transfers leave out moves between own pockets", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeTransfer).
+			WillReturnRows(sqlmock.NewRows(limitColumns).AddRow(nil, "2000000.00", nil))
+		mock.ExpectQuery("FROM transactions t JOIN wallet_transactions d .* AND NOT EXISTS \\( SELECT 1 FROM wallets fw JOIN wallets tw ON tw.user_id = fw.user_id").
+			WillReturnRows(sqlmock.NewRows([]string{"today", "month"}).AddRow("1500000.00", "1500000.00"))
+
+		assert.NoError(t, limits.Check(tx, "u1", limits.TypeTransfer, money.Money(500000)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("only rupiah transactions count", func(t *testing.T) {
+		tx, mock, done := beginTx(t)
+		defer done()
+
+		mock.ExpectQuery("SELECT l.per_transaction, l.daily, l.monthly").
+			WithArgs("u1", limits.TypeTransfer).
+			WillReturnRows(sqlmock.NewRows(limitColumns).AddRow(nil, "2000000.00", nil))
+		mock.ExpectQuery("FROM transactions t JOIN wallet_transactions d .* AND t.currency = \\$4").
+			WithArgs("u1", sqlmock.AnyArg(), sqlmock.AnyArg(), money.Currency).
+			WillReturnRows(sqlmock.NewRows([]string{"today", "month"}).AddRow("0.00", "0.00"))
+
+		assert.NoError(t, limits.Check(tx, "u1", limits.TypeTransfer, money.Money(2000000)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCheckBalance(t *testing.T) {
+	tx, mock, done := beginTx(t)
+	defer done()
+
+	mock.ExpectQuery("SELECT SUM\\(p.balance\\), at.max_balance").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "max_balance"}).AddRow("1900000.00", "2000000.00"))
+	mock.ExpectQuery("SELECT SUM\\(p.balance\\), at.max_balance").
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "max_balance"}).AddRow("1900000.00", "2000000.00"))
+
+	assert.NoError(t, limits.CheckBalance(tx, "w1", money.Money(100000)))
+	assert.ErrorIs(t, limits.CheckBalance(tx, "w1", money.Money(100001)), limits.ErrMaxBalance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
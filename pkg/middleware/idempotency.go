@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/pkg/idempotency"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyRetention reads IDEMPOTENCY_RETENTION, how long stored
+// responses are replayed, defaulting to a day.
+func IdempotencyRetention() time.Duration {
+	retention, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_RETENTION"))
+	if err != nil || retention <= 0 {
+		return 24 * time.Hour
+	}
+	return retention
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header. Keys are scoped to the authenticated user; a
+// key reused with a different method, path or body is rejected. Only
+// successful responses are kept, so a failed attempt can be retried with the
+// same key. Requests without the header pass through untouched.
+func Idempotency(store idempotency.Store, retention time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			json.NewResponBadRequest(c, nil, "Idempotency-Key is too long", "01", "03")
+			c.Abort()
+			return
+		}
+
+		userId, err := GetIdFromToken(c.GetHeader("Authorization"))
+		if err != nil {
+			json.NewResponseUnauthorized(c, "Invalid token", "01", "02")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			json.NewResponseError(c, "failed to read request body", "01", "02")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := idempotency.HashRequest(c.Request.Method, c.FullPath(), body)
+
+		existing, reserved, err := store.Reserve(userId, key, requestHash, retention)
+		if err != nil {
+			json.NewResponseError(c, err.Error(), "01", "02")
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			if existing.RequestHash != requestHash {
+				json.NewResponseUnprocessable(c, "Idempotency-Key has already been used for a different request", "01", "03")
+				c.Abort()
+				return
+			}
+			if existing.CompletedAt == nil {
+				json.NewResponseConflict(c, "a request with this Idempotency-Key is still being processed", "01", "03")
+				c.Abort()
+				return
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusOK && recorder.Status() < http.StatusMultipleChoices {
+			store.Complete(userId, key, recorder.Status(), recorder.body.Bytes())
+			return
+		}
+		store.Release(userId, key)
+	}
+}
@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	records map[string]idempotency.Record
+}
+
+func (m *memoryStore) Reserve(userId, key, requestHash string, retention time.Duration) (idempotency.Record, bool, error) {
+	if record, ok := m.records[userId+key]; ok {
+		return record, false, nil
+	}
+	m.records[userId+key] = idempotency.Record{UserId: userId, Key: key, RequestHash: requestHash}
+	return idempotency.Record{}, true, nil
+}
+
+func (m *memoryStore) Complete(userId, key string, statusCode int, responseBody []byte) error {
+	record := m.records[userId+key]
+	now := time.Now()
+	record.StatusCode = statusCode
+	record.ResponseBody = responseBody
+	record.CompletedAt = &now
+	m.records[userId+key] = record
+	return nil
+}
+
+func (m *memoryStore) Release(userId, key string) error {
+	delete(m.records, userId+key)
+	return nil
+}
+
+func newIdempotentRouter(store idempotency.Store, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/transfer", middleware.Idempotency(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{"transactionId": "trx-1"})
+	})
+	return r
+}
+
+func idempotentRequest(t *testing.T, r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", "USER", 1)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/transfer", strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(idempotency.HeaderKey, key)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	store := &memoryStore{records: map[string]idempotency.Record{}}
+	calls := 0
+	r := newIdempotentRouter(store, &calls)
+
+	first := idempotentRequest(t, r, "key-1", `{"amount":100}`)
+	second := idempotentRequest(t, r, "key-1", `{"amount":100}`)
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_DifferentBody(t *testing.T) {
+	store := &memoryStore{records: map[string]idempotency.Record{}}
+	calls := 0
+	r := newIdempotentRouter(store, &calls)
+
+	idempotentRequest(t, r, "key-1", `{"amount":100}`)
+	second := idempotentRequest(t, r, "key-1", `{"amount":200}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, second.Code)
+	assert.Equal(t, 1, calls)
+}
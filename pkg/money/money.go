@@ -0,0 +1,222 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is the home currency. Main pockets, fees, limits, top-ups and
+// everything paid through a gateway are in IDR; only pockets can hold one of
+// the other Currencies.
+const Currency = "IDR"
+
+// Currencies maps every currency a wallet can hold to the number of decimal
+// places of its smallest unit. IDR has no fractional unit in practice, so its
+// amounts are whole rupiah; USD and SGD amounts are cents.
+var Currencies = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"SGD": 2,
+}
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrSubUnitPrecision = errors.New("amount must be a whole number of rupiah")
+	ErrAmountOutOfRange = errors.New("amount is out of range")
+	maxAmount           = Money(9999999999999) // DECIMAL(15, 2)
+)
+
+// Money is an exact amount in the smallest unit of its currency, whole rupiah
+// for IDR. The currency itself is carried by the wallet or transaction the
+// amount belongs to. It reads DECIMAL columns and JSON numbers or strings,
+// and rejects any value with a non-zero fractional part instead of rounding
+// it.
+type Money int64
+
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	if whole == "" || (hasFraction && fraction == "") {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	if strings.Trim(fraction, "0") != "" {
+		return 0, ErrSubUnitPrecision
+	}
+
+	value, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || Money(value) > maxAmount {
+		return 0, ErrAmountOutOfRange
+	}
+
+	if negative {
+		value = -value
+	}
+	return Money(value), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// String formats the amount the way Midtrans and the DB expect it, with two
+// decimal places, e.g. "150000.00".
+func (m Money) String() string {
+	return strconv.FormatInt(int64(m), 10) + ".00"
+}
+
+// Decimal renders the amount in the major unit of currency, e.g. "1250000"
+// rupiah or "12.50" dollars for 1250 cents. Unknown currencies are treated
+// as having no decimal places.
+func (m Money) Decimal(currency string) string {
+	whole, fraction := m.split(currency)
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+// Format is Decimal with thousands separators, e.g. "1,250,000" or "-12.50",
+// for amounts shown to people.
+func (m Money) Format(currency string) string {
+	whole, fraction := m.split(currency)
+
+	negative := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
+
+func (m Money) split(currency string) (string, string) {
+	exp := Currencies[currency]
+	value := int64(m)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	digits := strconv.FormatInt(value, 10)
+	if exp == 0 {
+		return sign + digits, ""
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp], digits[len(digits)-exp:]
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(m), 10)), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+
+	// JSON numbers may arrive in exponent form (1e5); only accept those when
+	// they are whole numbers.
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ErrInvalidAmount
+		}
+		if f != math.Trunc(f) {
+			return ErrSubUnitPrecision
+		}
+		if math.Abs(f) > float64(maxAmount) {
+			return ErrAmountOutOfRange
+		}
+		*m = Money(f)
+		return nil
+	}
+
+	value, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = Money(v)
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money.Money", src)
+	}
+}
+
+func (m *Money) scanString(s string) error {
+	value, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into money.Money: %w", s, err)
+	}
+	*m = value
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
@@ -0,0 +1,91 @@
+package money_test
+
+import (
+	"encoding/json"
+	"final-project-enigma/pkg/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected money.Money
+		err      error
+	}{
+		{"150000", 150000, nil},
+		{"150000.00", 150000, nil},
+		{"150000.0", 150000, nil},
+		{"-25000.00", -25000, nil},
+		{"150000.50", 0, money.ErrSubUnitPrecision},
+		{"0.01", 0, money.ErrSubUnitPrecision},
+		{"12a", 0, money.ErrInvalidAmount},
+		{"1.", 0, money.ErrInvalidAmount},
+		{"", 0, money.ErrInvalidAmount},
+		{"99999999999999", 0, money.ErrAmountOutOfRange},
+	}
+
+	for _, tt := range tests {
+		amount, err := money.Parse(tt.input)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.input)
+			continue
+		}
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, amount, tt.input)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var req struct {
+		Amount money.Money `json:"amount"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":100000}`), &req))
+	assert.Equal(t, money.Money(100000), req.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"250000.00"}`), &req))
+	assert.Equal(t, money.Money(250000), req.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":1e5}`), &req))
+	assert.Equal(t, money.Money(100000), req.Amount)
+
+	err := json.Unmarshal([]byte(`{"amount":100.5}`), &req)
+	assert.ErrorIs(t, err, money.ErrSubUnitPrecision)
+
+	out, err := json.Marshal(req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":100000}`, string(out))
+}
+
+func TestScan(t *testing.T) {
+	var amount money.Money
+	assert.NoError(t, amount.Scan([]byte("75000.00")))
+	assert.Equal(t, money.Money(75000), amount)
+	assert.Equal(t, "75000.00", amount.String())
+
+	assert.Error(t, amount.Scan([]byte("75000.25")))
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount   money.Money
+		currency string
+		decimal  string
+		format   string
+	}{
+		{1250000, "IDR", "1250000", "1,250,000"},
+		{999, "IDR", "999", "999"},
+		{-25000, "IDR", "-25000", "-25,000"},
+		{1250, "USD", "12.50", "12.50"},
+		{5, "SGD", "0.05", "0.05"},
+		{-123456789, "USD", "-1234567.89", "-1,234,567.89"},
+		{0, "USD", "0.00", "0.00"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.decimal, tt.amount.Decimal(tt.currency), tt.decimal)
+		assert.Equal(t, tt.format, tt.amount.Format(tt.currency), tt.format)
+	}
+}
@@ -0,0 +1,40 @@
+package paymentGateway
+
+import (
+	"final-project-enigma/pkg/helper/midtransStatus"
+	"strconv"
+	"sync"
+)
+
+// Fake records checkouts instead of calling a gateway and answers status
+// checks from its embedded midtransStatus.Fake. Set Err to make
+// CreateCheckout fail.
+type Fake struct {
+	*midtransStatus.Fake
+	mu        sync.Mutex
+	Checkouts []CheckoutRequest
+	Err       error
+}
+
+func NewFake() *Fake {
+	return &Fake{Fake: midtransStatus.NewFake()}
+}
+
+func (f *Fake) Name() string {
+	return GatewayFake
+}
+
+func (f *Fake) CreateCheckout(req CheckoutRequest) (Checkout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return Checkout{}, f.Err
+	}
+	f.Checkouts = append(f.Checkouts, req)
+	token := "fake-" + strconv.Itoa(len(f.Checkouts))
+	return Checkout{
+		Token:       token,
+		RedirectUrl: "fake://checkout/" + token,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package paymentGateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"final-project-enigma/pkg/helper/midtransStatus"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// defaultSnapBaseURL is the sandbox; production is https://app.midtrans.com.
+const defaultSnapBaseURL = "https://app.sandbox.midtrans.com"
+
+// Midtrans takes payments through the Snap hosted page. Statuses are read
+// from the Core API, configured separately with MIDTRANS_API_BASE_URL.
+type Midtrans struct {
+	*midtransStatus.Client
+	client      *resty.Client
+	snapBaseURL string
+	serverKey   string
+}
+
+func NewMidtrans(client *resty.Client, snapBaseURL, serverKey string) *Midtrans {
+	return &Midtrans{
+		Client:      midtransStatus.New(client),
+		client:      client,
+		snapBaseURL: strings.TrimSuffix(snapBaseURL, "/"),
+		serverKey:   serverKey,
+	}
+}
+
+// midtransMethod is the "midtrans" entry of a payment method's config.
+// SnapPath opens the method directly on the Snap page, for example
+// "#/bank-transfer/bca-va". EnabledPayments limits the Snap page to those
+// Midtrans payment types and defaults to the method's gateway code.
+type midtransMethod struct {
+	SnapPath        string   `json:"snap_path"`
+	EnabledPayments []string `json:"enabled_payments"`
+}
+
+type (
+	snapItem struct {
+		ID       string      `json:"id"`
+		Name     string      `json:"name"`
+		Price    money.Money `json:"price"`
+		Quantity int         `json:"quantity"`
+	}
+
+	snapRequest struct {
+		TransactionDetail struct {
+			OrderID  string      `json:"order_id"`
+			GrossAmt money.Money `json:"gross_amount"`
+		} `json:"transaction_details"`
+		PaymentType     string      `json:"payment_type"`
+		Customer        string      `json:"customer"`
+		Items           []snapItem  `json:"item_details"`
+		EnabledPayments []string    `json:"enabled_payments,omitempty"`
+		Expiry          *snapExpiry `json:"expiry,omitempty"`
+	}
+
+	snapExpiry struct {
+		Unit     string `json:"unit"`
+		Duration int    `json:"duration"`
+	}
+
+	snapResponse struct {
+		Token        string   `json:"token"`
+		ErrorMessage []string `json:"error_messages"`
+	}
+)
+
+func (m *Midtrans) Name() string {
+	return GatewayMidtrans
+}
+
+func (m *Midtrans) CreateCheckout(req CheckoutRequest) (Checkout, error) {
+	var method midtransMethod
+	if raw, ok := req.Method.Config[GatewayMidtrans]; ok {
+		if err := json.Unmarshal(raw, &method); err != nil {
+			return Checkout{}, fmt.Errorf("invalid midtrans config for payment method %s: %w", req.Method.Id, err)
+		}
+	}
+
+	var payload snapRequest
+	payload.TransactionDetail.OrderID = req.OrderId
+	payload.TransactionDetail.GrossAmt = req.GrossAmount
+	payload.PaymentType = req.Method.Name
+	payload.Customer = req.Customer
+	payload.EnabledPayments = method.EnabledPayments
+	if len(payload.EnabledPayments) == 0 && req.Method.Code != "" {
+		payload.EnabledPayments = []string{req.Method.Code}
+	}
+	if req.Method.ExpiryMinutes > 0 {
+		payload.Expiry = &snapExpiry{Unit: "minutes", Duration: req.Method.ExpiryMinutes}
+	}
+	for _, item := range req.Items {
+		payload.Items = append(payload.Items, snapItem{ID: item.Id, Name: item.Name, Price: item.Price, Quantity: item.Quantity})
+	}
+
+	encodeKey := base64.StdEncoding.EncodeToString([]byte(m.serverKey + ":"))
+	resp, err := m.client.R().
+		SetHeader("Authorization", "Basic "+encodeKey).
+		SetHeader("Accept", "application/json").
+		SetBody(payload).
+		Post(m.snapBaseURL + "/snap/v1/transactions")
+	if err != nil {
+		return Checkout{}, err
+	}
+
+	var snapResp snapResponse
+	if err := json.Unmarshal(resp.Body(), &snapResp); err != nil {
+		return Checkout{}, fmt.Errorf("failed to read midtrans snap response (%d): %w", resp.StatusCode(), err)
+	}
+	if snapResp.Token == "" {
+		return Checkout{}, fmt.Errorf("midtrans snap answered %d: %s", resp.StatusCode(), strings.Join(snapResp.ErrorMessage, ", "))
+	}
+
+	return Checkout{
+		Token:       snapResp.Token,
+		RedirectUrl: m.snapBaseURL + "/snap/v2/vtweb/" + snapResp.Token + method.SnapPath,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package paymentGateway_test
+
+import (
+	"encoding/json"
+	"final-project-enigma/pkg/paymentGateway"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMidtransCreateCheckout(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/snap/v1/transactions", r.URL.Path)
+		username, _, _ := r.BasicAuth()
+		assert.Equal(t, "server-key", username)
+
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":"snap-token","redirect_url":"ignored"}`))
+	}))
+	defer server.Close()
+
+	midtrans := paymentGateway.NewMidtrans(resty.New(), server.URL+"/", "server-key")
+	checkout, err := midtrans.CreateCheckout(paymentGateway.CheckoutRequest{
+		OrderId:     "order-1",
+		GrossAmount: 101000,
+		Customer:    "Budi",
+		Items:       []paymentGateway.Item{{Id: "usertopup", Name: "TopUp Balance", Price: 100000, Quantity: 1}},
+		Method: paymentGateway.Method{
+			Id:            "method-1",
+			Name:          "BCA",
+			Code:          "bca_va",
+			ExpiryMinutes: 60,
+			Config: map[string]json.RawMessage{
+				"midtrans": json.RawMessage(`{"snap_path":"#/bank-transfer/bca-va"}`),
+			},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "snap-token", checkout.Token)
+	assert.Equal(t, server.URL+"/snap/v2/vtweb/snap-token#/bank-transfer/bca-va", checkout.RedirectUrl)
+	assert.Equal(t, float64(101000), received["transaction_details"].(map[string]interface{})["gross_amount"])
+	assert.Equal(t, []interface{}{"bca_va"}, received["enabled_payments"])
+	assert.Equal(t, map[string]interface{}{"unit": "minutes", "duration": float64(60)}, received["expiry"])
+}
+
+func TestMidtransCreateCheckout_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error_messages":["transaction_details.order_id has already been taken"]}`))
+	}))
+	defer server.Close()
+
+	midtrans := paymentGateway.NewMidtrans(resty.New(), server.URL, "server-key")
+	_, err := midtrans.CreateCheckout(paymentGateway.CheckoutRequest{OrderId: "order-1", GrossAmount: 1000})
+	assert.ErrorContains(t, err, "has already been taken")
+}
@@ -0,0 +1,74 @@
+package paymentGateway
+
+import (
+	"encoding/json"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/money"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	GatewayMidtrans = "midtrans"
+	GatewayFake     = "fake"
+)
+
+type (
+	// Method is a payment method as configured in payment_method. Code is
+	// the method's identifier at the gateway and ExpiryMinutes how long the
+	// user has to pay. Config holds each gateway's settings for it keyed by
+	// gateway name, so a new method only needs a row, not code.
+	Method struct {
+		Id            string
+		Name          string
+		Code          string
+		ExpiryMinutes int
+		Config        map[string]json.RawMessage
+	}
+
+	Item struct {
+		Id       string
+		Name     string
+		Price    money.Money
+		Quantity int
+	}
+
+	// CheckoutRequest asks the gateway to collect GrossAmount for an order.
+	// OrderId is our transaction id and comes back in notifications.
+	CheckoutRequest struct {
+		OrderId     string
+		GrossAmount money.Money
+		Customer    string
+		Items       []Item
+		Method      Method
+	}
+
+	// Checkout is where the user is sent to pay.
+	Checkout struct {
+		Token       string
+		RedirectUrl string
+	}
+)
+
+// PaymentGateway collects top-up payments. CreateCheckout starts a payment
+// the user completes on the gateway's page; its outcome arrives as a
+// notification, or can be polled with TransactionStatus.
+type PaymentGateway interface {
+	Name() string
+	CreateCheckout(req CheckoutRequest) (Checkout, error)
+	TransactionStatus(orderID string) (userDto.MidtransNotification, error)
+}
+
+// New returns the gateway named by PAYMENT_GATEWAY, Midtrans by default.
+func New(client *resty.Client) PaymentGateway {
+	if os.Getenv("PAYMENT_GATEWAY") == GatewayFake {
+		return NewFake()
+	}
+
+	snapBaseURL := os.Getenv("MIDTRANS_SNAP_BASE_URL")
+	if snapBaseURL == "" {
+		snapBaseURL = defaultSnapBaseURL
+	}
+	return NewMidtrans(client, snapBaseURL, os.Getenv("MIDTRANS_SERVER_KEY"))
+}
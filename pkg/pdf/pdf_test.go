@@ -0,0 +1,53 @@
+package pdf_test
+
+import (
+	"bytes"
+	"final-project-enigma/pkg/pdf"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	doc := pdf.New()
+	first := doc.AddPage()
+	first.Text(40, 800, 16, true, "Account Statement")
+	first.Text(40, 780, 9, false, "Top Up (Bank Transfer) \\ Café ✓")
+	first.Line(40, 770, 555, 770)
+	second := doc.AddPage()
+	second.TextRight(555, 800, 9, false, "1,250,000")
+
+	out := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/BaseFont /Helvetica-Bold")
+	assert.Contains(t, string(out), "(Top Up \\(Bank Transfer\\) \\\\ Caf\xe9 ?) Tj")
+	assert.Regexp(t, `/F1 9 Tf 514\.96\d* 800 Td \(1,250,000\) Tj`, string(out))
+
+	// Every object the cross-reference table points at starts where it says.
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	assert.NotNil(t, startxref)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n0 9\n")))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(out, -1)
+	assert.Len(t, offsets, 8)
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(string(offset[1]))
+		assert.True(t, bytes.HasPrefix(out[at:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestDocument_Empty(t *testing.T) {
+	out := pdf.New().Bytes()
+	assert.Contains(t, string(out), "/Count 1")
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Equal(t, 5.56, pdf.TextWidth("0", 10))
+	assert.InDelta(t, 40.032, pdf.TextWidth("1,250,000", 9), 0.0001)
+}
@@ -0,0 +1,230 @@
+package qris
+
+import (
+	"errors"
+	"final-project-enigma/pkg/money"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Top-level and template tags of the EMVCo merchant-presented QR format used
+// by QRIS.
+const (
+	tagFormatIndicator = "00"
+	tagInitiation      = "01"
+	tagMerchantAccount = "26"
+	tagCategoryCode    = "52"
+	tagCurrency        = "53"
+	tagAmount          = "54"
+	tagCountry         = "58"
+	tagMerchantName    = "59"
+	tagMerchantCity    = "60"
+	tagAdditionalData  = "62"
+	tagCRC             = "63"
+
+	subtagGUID       = "00"
+	subtagMerchantId = "01"
+	subtagBillNumber = "01"
+
+	initiationStatic  = "11"
+	initiationDynamic = "12"
+
+	formatIndicator = "01"
+	categoryCode    = "5999"
+	currencyIDR     = "360"
+	countryCode     = "ID"
+
+	defaultGUID = "ID.CO.ENIGMA"
+	defaultCity = "JAKARTA"
+
+	maxNameLength       = 25
+	maxCityLength       = 15
+	maxBillNumberLength = 25
+)
+
+var (
+	ErrInvalidPayload      = errors.New("invalid QR payload")
+	ErrChecksumMismatch    = errors.New("QR payload checksum mismatch")
+	ErrUnsupportedMerchant = errors.New("QR payload is not for a merchant of this wallet")
+	ErrUnsupportedCurrency = errors.New("QR payload currency is not supported")
+)
+
+// Payload is a decoded merchant QR. A static QR has no amount or bill number;
+// the payer enters the amount.
+type Payload struct {
+	MerchantId   string
+	MerchantName string
+	MerchantCity string
+	Amount       money.Money
+	BillNumber   string
+}
+
+func (p Payload) IsDynamic() bool {
+	return p.Amount > 0
+}
+
+// GUID identifies this wallet in the merchant account template. It is read
+// from QR_MERCHANT_GUID so that QRs issued by different environments do not
+// pay each other's merchants.
+func GUID() string {
+	if guid := os.Getenv("QR_MERCHANT_GUID"); guid != "" {
+		return guid
+	}
+	return defaultGUID
+}
+
+// City is printed on every QR and read from QR_MERCHANT_CITY.
+func City() string {
+	if city := os.Getenv("QR_MERCHANT_CITY"); city != "" {
+		return city
+	}
+	return defaultCity
+}
+
+// Encode builds the QR string for p, ending with its CRC. Name, city and bill
+// number are cut to the lengths the format allows.
+func Encode(p Payload) string {
+	initiation := initiationStatic
+	if p.IsDynamic() {
+		initiation = initiationDynamic
+	}
+
+	var b strings.Builder
+	b.WriteString(field(tagFormatIndicator, formatIndicator))
+	b.WriteString(field(tagInitiation, initiation))
+	b.WriteString(field(tagMerchantAccount, field(subtagGUID, GUID())+field(subtagMerchantId, p.MerchantId)))
+	b.WriteString(field(tagCategoryCode, categoryCode))
+	b.WriteString(field(tagCurrency, currencyIDR))
+	if p.IsDynamic() {
+		b.WriteString(field(tagAmount, strconv.FormatInt(int64(p.Amount), 10)))
+	}
+	b.WriteString(field(tagCountry, countryCode))
+	b.WriteString(field(tagMerchantName, truncate(p.MerchantName, maxNameLength)))
+	b.WriteString(field(tagMerchantCity, truncate(p.MerchantCity, maxCityLength)))
+	if p.BillNumber != "" {
+		b.WriteString(field(tagAdditionalData, field(subtagBillNumber, truncate(p.BillNumber, maxBillNumberLength))))
+	}
+	b.WriteString(tagCRC + "04")
+
+	return b.String() + CRC16(b.String())
+}
+
+// Decode checks the CRC and the fields this wallet relies on and returns the
+// merchant and, for a dynamic QR, the amount and bill number.
+func Decode(s string) (Payload, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != tagCRC+"04" {
+		return Payload{}, ErrInvalidPayload
+	}
+	if !strings.EqualFold(CRC16(s[:len(s)-4]), s[len(s)-4:]) {
+		return Payload{}, ErrChecksumMismatch
+	}
+
+	fields, err := parse(s[:len(s)-8])
+	if err != nil {
+		return Payload{}, err
+	}
+	if fields[tagFormatIndicator] != formatIndicator {
+		return Payload{}, fmt.Errorf("%w: unknown payload format", ErrInvalidPayload)
+	}
+	if fields[tagCurrency] != currencyIDR {
+		return Payload{}, ErrUnsupportedCurrency
+	}
+
+	account, err := parse(fields[tagMerchantAccount])
+	if err != nil {
+		return Payload{}, err
+	}
+	if account[subtagGUID] != GUID() || account[subtagMerchantId] == "" {
+		return Payload{}, ErrUnsupportedMerchant
+	}
+
+	payload := Payload{
+		MerchantId:   account[subtagMerchantId],
+		MerchantName: fields[tagMerchantName],
+		MerchantCity: fields[tagMerchantCity],
+	}
+
+	if additional, ok := fields[tagAdditionalData]; ok {
+		data, err := parse(additional)
+		if err != nil {
+			return Payload{}, err
+		}
+		payload.BillNumber = data[subtagBillNumber]
+	}
+
+	switch fields[tagInitiation] {
+	case initiationStatic:
+		if _, ok := fields[tagAmount]; ok {
+			return Payload{}, fmt.Errorf("%w: static QR carries an amount", ErrInvalidPayload)
+		}
+	case initiationDynamic:
+		payload.Amount, err = money.Parse(fields[tagAmount])
+		if err != nil || !payload.Amount.IsPositive() {
+			return Payload{}, fmt.Errorf("%w: dynamic QR needs a valid amount", ErrInvalidPayload)
+		}
+	default:
+		return Payload{}, fmt.Errorf("%w: unknown point of initiation", ErrInvalidPayload)
+	}
+
+	return payload, nil
+}
+
+// PNG renders payload as a square QR image of size pixels.
+func PNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// CRC16 is the CRC-16/CCITT-FALSE checksum the format uses, as four upper
+// case hex digits.
+func CRC16(s string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+func field(tag, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// parse splits s into its tag-length-value fields. A tag that appears twice
+// makes the payload invalid.
+func parse(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, ErrInvalidPayload
+		}
+		tag := s[:2]
+		length, err := strconv.Atoi(s[2:4])
+		if err != nil || length < 0 || len(s) < 4+length {
+			return nil, ErrInvalidPayload
+		}
+		if _, ok := fields[tag]; ok {
+			return nil, fmt.Errorf("%w: duplicate tag %s", ErrInvalidPayload, tag)
+		}
+		fields[tag] = s[4 : 4+length]
+		s = s[4+length:]
+	}
+	return fields, nil
+}
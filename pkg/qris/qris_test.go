@@ -0,0 +1,60 @@
+package qris
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRC16(t *testing.T) {
+	assert.Equal(t, "29B1", CRC16("123456789"))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Run("static", func(t *testing.T) {
+		payload := Encode(Payload{MerchantId: "44efb0d8-09e9-458d-afd7-09e31087b638", MerchantName: "Indomaret", MerchantCity: "JAKARTA"})
+
+		assert.Contains(t, payload, "010211")
+		decoded, err := Decode(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "44efb0d8-09e9-458d-afd7-09e31087b638", decoded.MerchantId)
+		assert.Equal(t, "Indomaret", decoded.MerchantName)
+		assert.False(t, decoded.IsDynamic())
+	})
+
+	t.Run("dynamic", func(t *testing.T) {
+		payload := Encode(Payload{MerchantId: "44efb0d8-09e9-458d-afd7-09e31087b638", MerchantName: "Indomaret", MerchantCity: "JAKARTA", Amount: 25000, BillNumber: "INV-001"})
+
+		assert.Contains(t, payload, "010212")
+		assert.Contains(t, payload, "540525000")
+		decoded, err := Decode(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "INV-001", decoded.BillNumber)
+		assert.EqualValues(t, 25000, decoded.Amount)
+	})
+}
+
+func TestDecodeRejects(t *testing.T) {
+	valid := Encode(Payload{MerchantId: "44efb0d8-09e9-458d-afd7-09e31087b638", MerchantName: "Indomaret", MerchantCity: "JAKARTA", Amount: 25000})
+
+	_, err := Decode(valid[:len(valid)-1] + "0")
+	if valid[len(valid)-1] == '0' {
+		_, err = Decode(valid[:len(valid)-1] + "1")
+	}
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	_, err = Decode("not a qr")
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+
+	t.Setenv("QR_MERCHANT_GUID", "ID.CO.OTHER")
+	_, err = Decode(valid)
+	assert.ErrorIs(t, err, ErrUnsupportedMerchant)
+}
+
+func TestPNG(t *testing.T) {
+	image, err := PNG(Encode(Payload{MerchantId: "44efb0d8-09e9-458d-afd7-09e31087b638", MerchantName: "Indomaret"}), 256)
+
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(image, []byte("\x89PNG")))
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Every runs job once per interval until ctx is cancelled. A job that returns
+// an error is logged and tried again on the next tick; ticks that arrive while
+// a job is still running are dropped rather than queued.
+func Every(ctx context.Context, name string, interval time.Duration, job func(now time.Time) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := job(now); err != nil {
+				log.Error().Msg(name + ": " + err.Error())
+			}
+		}
+	}
+}
+
+// Interval reads a duration from the environment variable key, falling back
+// to def when it is unset or invalid.
+func Interval(key string, def time.Duration) time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || interval <= 0 {
+		return def
+	}
+	return interval
+}
@@ -0,0 +1,128 @@
+package txauth
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/pkg/helper/hashingPassword"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrInvalidPin   = errors.New("invalid PIN")
+	ErrPinLocked    = errors.New("transactions are temporarily locked after too many wrong PIN attempts")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+const (
+	defaultMaxAttempts  = 3
+	defaultLockDuration = 30 * time.Minute
+)
+
+// Authorizer checks the transaction PIN of a user. Every flow that takes
+// money out of a wallet must call Authorize and stop on error before it
+// touches any balance.
+type Authorizer interface {
+	Authorize(userId, pin string) error
+}
+
+type pinAuthorizer struct {
+	db           *sql.DB
+	maxAttempts  int
+	lockDuration time.Duration
+}
+
+// NewPinAuthorizer reads PIN_MAX_ATTEMPTS and PIN_LOCK_DURATION from the
+// environment, falling back to 3 attempts and a 30 minute lock.
+func NewPinAuthorizer(db *sql.DB) Authorizer {
+	maxAttempts, err := strconv.Atoi(os.Getenv("PIN_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	lockDuration, err := time.ParseDuration(os.Getenv("PIN_LOCK_DURATION"))
+	if err != nil || lockDuration <= 0 {
+		lockDuration = defaultLockDuration
+	}
+
+	return &pinAuthorizer{
+		db:           db,
+		maxAttempts:  maxAttempts,
+		lockDuration: lockDuration,
+	}
+}
+
+func (a *pinAuthorizer) Authorize(userId, pin string) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var storedPin string
+	var failedAttempts int
+	var lockedUntil sql.NullTime
+	query := `
+		SELECT pin, pin_failed_attempts, pin_locked_until
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err = tx.QueryRow(query, userId).Scan(&storedPin, &failedAttempts, &lockedUntil)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		log.Error().Msg("user not found")
+		return ErrUserNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	currentTime := time.Now()
+	if lockedUntil.Valid && currentTime.Before(lockedUntil.Time) {
+		tx.Rollback()
+		log.Error().Msg("transaction PIN locked for user " + userId)
+		return fmt.Errorf("%w until %s", ErrPinLocked, lockedUntil.Time.Format("02-01-2006 15:04:05"))
+	}
+
+	if err := hashingPassword.ComparePassword(storedPin, pin); err != nil {
+		failedAttempts++
+
+		var lockErr error
+		var newLockedUntil sql.NullTime
+		if failedAttempts >= a.maxAttempts {
+			newLockedUntil = sql.NullTime{Time: currentTime.Add(a.lockDuration), Valid: true}
+			lockErr = fmt.Errorf("%w until %s", ErrPinLocked, newLockedUntil.Time.Format("02-01-2006 15:04:05"))
+			failedAttempts = 0
+		}
+
+		updateQuery := `UPDATE users SET pin_failed_attempts = $1, pin_locked_until = $2 WHERE id = $3`
+		if _, err := tx.Exec(updateQuery, failedAttempts, newLockedUntil, userId); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		log.Error().Msg("invalid PIN")
+		if lockErr != nil {
+			return lockErr
+		}
+		return fmt.Errorf("%w, %d attempts left", ErrInvalidPin, a.maxAttempts-failedAttempts)
+	}
+
+	if failedAttempts > 0 || lockedUntil.Valid {
+		resetQuery := `UPDATE users SET pin_failed_attempts = 0, pin_locked_until = NULL WHERE id = $1`
+		if _, err := tx.Exec(resetQuery, userId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,103 @@
+package txauth_test
+
+import (
+	"errors"
+	"final-project-enigma/pkg/helper/hashingPassword"
+	"final-project-enigma/pkg/txauth"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const userId = "913a9dcf-28cd-4d2a-991b-60bdb3e57687"
+
+func hashedPin(t *testing.T) string {
+	hashed, err := hashingPassword.HashPassword("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hashed
+}
+
+func TestAuthorize_ValidPinResetsAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pin, pin_failed_attempts, pin_locked_until").
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"pin", "pin_failed_attempts", "pin_locked_until"}).AddRow(hashedPin(t), 2, nil))
+	mock.ExpectExec("UPDATE users SET pin_failed_attempts = 0").WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = txauth.NewPinAuthorizer(db).Authorize(userId, "123456")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorize_WrongPinCountsAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pin, pin_failed_attempts, pin_locked_until").
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"pin", "pin_failed_attempts", "pin_locked_until"}).AddRow(hashedPin(t), 0, nil))
+	mock.ExpectExec("UPDATE users SET pin_failed_attempts").WithArgs(1, nil, userId).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = txauth.NewPinAuthorizer(db).Authorize(userId, "654321")
+
+	assert.True(t, errors.Is(err, txauth.ErrInvalidPin))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorize_LocksAfterMaxAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	t.Setenv("PIN_MAX_ATTEMPTS", "3")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pin, pin_failed_attempts, pin_locked_until").
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"pin", "pin_failed_attempts", "pin_locked_until"}).AddRow(hashedPin(t), 2, nil))
+	mock.ExpectExec("UPDATE users SET pin_failed_attempts").WithArgs(0, sqlmock.AnyArg(), userId).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = txauth.NewPinAuthorizer(db).Authorize(userId, "654321")
+
+	assert.True(t, errors.Is(err, txauth.ErrPinLocked))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorize_RejectsWhileLocked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pin, pin_failed_attempts, pin_locked_until").
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"pin", "pin_failed_attempts", "pin_locked_until"}).AddRow(hashedPin(t), 0, time.Now().Add(time.Minute)))
+	mock.ExpectRollback()
+
+	err = txauth.NewPinAuthorizer(db).Authorize(userId, "123456")
+
+	assert.True(t, errors.Is(err, txauth.ErrPinLocked))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,7 +1,9 @@
 package validation
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/json"
+	"final-project-enigma/pkg/money"
 	"fmt"
 	"regexp"
 	"strings"
@@ -14,6 +16,12 @@ import (
 func GetValidationError(err error) []json.ValidationField {
 
 	var validationField []json.ValidationField
+	if errors.Is(err, money.ErrSubUnitPrecision) || errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrAmountOutOfRange) {
+		return append(validationField, json.ValidationField{
+			FieldName: "amount",
+			Message:   err.Error(),
+		})
+	}
 	if ve, ok := err.(validator.ValidationErrors); ok {
 		for _, validationError := range ve {
 			log.Debug().Msg(fmt.Sprintf("validationError: %v", validationError))
@@ -77,6 +85,8 @@ func formatMessage(err validator.FieldError) string {
 		message = "invalid username format, don't use sepcial characters and space"
 	case "pin":
 		message = "only 6 characters of number"
+	case "nefield":
+		message = "must be different from " + strcase.LowerCamelCase(err.Param())
 	}
 	return message
 }
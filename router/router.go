@@ -1,10 +1,20 @@
 package router
 
 import (
+	"context"
 	"database/sql"
+	"final-project-enigma/pkg/disbursement"
+	"final-project-enigma/pkg/exchangeRate"
+	"final-project-enigma/pkg/helper/merchantCallback"
+	"final-project-enigma/pkg/helper/sendEmail"
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/paymentGateway"
+	"final-project-enigma/pkg/scheduler"
+	"final-project-enigma/pkg/txauth"
 	"final-project-enigma/src/user/userDelivery"
 	"final-project-enigma/src/user/userRepository"
 	"final-project-enigma/src/user/userUsecase"
+	"time"
 
 	"final-project-enigma/src/auth/authDelivery"
 	"final-project-enigma/src/auth/authRepository"
@@ -13,10 +23,66 @@ import (
 	"final-project-enigma/src/payment/paymentRepository"
 	"final-project-enigma/src/payment/paymentUsecase"
 
+	"final-project-enigma/src/paymentMethod/paymentMethodDelivery"
+	"final-project-enigma/src/paymentMethod/paymentMethodRepository"
+	"final-project-enigma/src/paymentMethod/paymentMethodUsecase"
+
 	"final-project-enigma/src/admin/adminDelivery"
 	"final-project-enigma/src/admin/adminRepository"
 	"final-project-enigma/src/admin/adminUsecase"
 
+	"final-project-enigma/src/ledger/ledgerDelivery"
+	"final-project-enigma/src/ledger/ledgerRepository"
+	"final-project-enigma/src/ledger/ledgerUsecase"
+
+	"final-project-enigma/src/limits/limitsDelivery"
+	"final-project-enigma/src/limits/limitsRepository"
+	"final-project-enigma/src/limits/limitsUsecase"
+
+	"final-project-enigma/src/scheduledTransfer/scheduledTransferDelivery"
+	"final-project-enigma/src/scheduledTransfer/scheduledTransferRepository"
+	"final-project-enigma/src/scheduledTransfer/scheduledTransferUsecase"
+
+	"final-project-enigma/src/paymentRequest/paymentRequestDelivery"
+	"final-project-enigma/src/paymentRequest/paymentRequestRepository"
+	"final-project-enigma/src/paymentRequest/paymentRequestUsecase"
+
+	"final-project-enigma/src/splitBill/splitBillDelivery"
+	"final-project-enigma/src/splitBill/splitBillRepository"
+	"final-project-enigma/src/splitBill/splitBillUsecase"
+
+	"final-project-enigma/src/merchant/merchantDelivery"
+	"final-project-enigma/src/merchant/merchantRepository"
+	"final-project-enigma/src/merchant/merchantUsecase"
+
+	"final-project-enigma/src/charge/chargeDelivery"
+	"final-project-enigma/src/charge/chargeRepository"
+	"final-project-enigma/src/charge/chargeUsecase"
+
+	"final-project-enigma/src/settlement/settlementDelivery"
+	"final-project-enigma/src/settlement/settlementRepository"
+	"final-project-enigma/src/settlement/settlementUsecase"
+
+	"final-project-enigma/src/withdrawal/withdrawalDelivery"
+	"final-project-enigma/src/withdrawal/withdrawalRepository"
+	"final-project-enigma/src/withdrawal/withdrawalUsecase"
+
+	"final-project-enigma/src/reconciliation/reconciliationDelivery"
+	"final-project-enigma/src/reconciliation/reconciliationRepository"
+	"final-project-enigma/src/reconciliation/reconciliationUsecase"
+
+	"final-project-enigma/src/pocket/pocketDelivery"
+	"final-project-enigma/src/pocket/pocketRepository"
+	"final-project-enigma/src/pocket/pocketUsecase"
+
+	"final-project-enigma/src/fx/fxDelivery"
+	"final-project-enigma/src/fx/fxRepository"
+	"final-project-enigma/src/fx/fxUsecase"
+
+	"final-project-enigma/src/statement/statementDelivery"
+	"final-project-enigma/src/statement/statementRepository"
+	"final-project-enigma/src/statement/statementUsecase"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 )
@@ -35,11 +101,88 @@ func InitRoute(v1Group *gin.RouterGroup, db *sql.DB, client *resty.Client) {
 
 	//Users
 	userRepo := userRepository.NewUserRepository(db, client)
-	userUC := userUsecase.NewUserUsecase(userRepo)
-	userDelivery.NewUserDelivery(v1Group, userUC)
+	txAuthorizer := txauth.NewPinAuthorizer(db)
+	gateway := paymentGateway.New(client)
+	userUC := userUsecase.NewUserUsecase(userRepo, txAuthorizer, gateway)
+	idempotencyStore := idempotency.NewSQLStore(db)
+	userDelivery.NewUserDelivery(v1Group, userUC, idempotencyStore)
 
 	//Payment
 	paymentRepo := paymentRepository.NewPaymentRepository(db)
-	paymentUC := paymentUsecase.NewPaymentUsecase(paymentRepo)
+	paymentUC := paymentUsecase.NewPaymentUsecase(paymentRepo, gateway)
 	paymentDelivery.NewPaymentDelivery(v1Group, paymentUC)
+	go scheduler.Every(context.Background(), "pending top ups", scheduler.Interval("TOPUP_POLL_INTERVAL", 5*time.Minute), paymentUC.PollPendingTopUpsUC)
+
+	//Payment methods
+	paymentMethodRepo := paymentMethodRepository.NewPaymentMethodRepository(db)
+	paymentMethodUC := paymentMethodUsecase.NewPaymentMethodUsecase(paymentMethodRepo)
+	paymentMethodDelivery.NewPaymentMethodDelivery(v1Group, paymentMethodUC)
+
+	//Ledger
+	ledgerRepo := ledgerRepository.NewLedgerRepository(db)
+	ledgerUC := ledgerUsecase.NewLedgerUsecase(ledgerRepo)
+	ledgerDelivery.NewLedgerDelivery(v1Group, ledgerUC)
+
+	//Limits
+	limitsRepo := limitsRepository.NewLimitsRepository(db)
+	limitsUC := limitsUsecase.NewLimitsUsecase(limitsRepo)
+	limitsDelivery.NewLimitsDelivery(v1Group, limitsUC)
+
+	//Scheduled transfers
+	scheduleRepo := scheduledTransferRepository.NewScheduledTransferRepository(db)
+	scheduleUC := scheduledTransferUsecase.NewScheduledTransferUsecase(scheduleRepo, txAuthorizer, sendEmail.EmailNotifier{})
+	scheduledTransferDelivery.NewScheduledTransferDelivery(v1Group, scheduleUC)
+	go scheduler.Every(context.Background(), "scheduled transfers", scheduler.Interval("SCHEDULED_TRANSFER_INTERVAL", time.Minute), scheduleUC.RunDueUC)
+
+	//Payment requests
+	requestRepo := paymentRequestRepository.NewPaymentRequestRepository(db)
+	requestUC := paymentRequestUsecase.NewPaymentRequestUsecase(requestRepo, txAuthorizer)
+	paymentRequestDelivery.NewPaymentRequestDelivery(v1Group, requestUC)
+
+	//Split bills
+	splitBillRepo := splitBillRepository.NewSplitBillRepository(db)
+	splitBillUC := splitBillUsecase.NewSplitBillUsecase(splitBillRepo)
+	splitBillDelivery.NewSplitBillDelivery(v1Group, splitBillUC)
+
+	//Merchants
+	merchantRepo := merchantRepository.NewMerchantRepository(db)
+	merchantUC := merchantUsecase.NewMerchantUsecase(merchantRepo)
+	merchantDelivery.NewMerchantDelivery(v1Group, merchantUC)
+
+	//Merchant charges
+	chargeRepo := chargeRepository.NewChargeRepository(db)
+	chargeUC := chargeUsecase.NewChargeUsecase(chargeRepo, txAuthorizer, merchantCallback.NewHTTPSender(client))
+	chargeDelivery.NewChargeDelivery(v1Group, chargeUC, merchantUC)
+	go scheduler.Every(context.Background(), "merchant callbacks", scheduler.Interval("MERCHANT_CALLBACK_INTERVAL", 30*time.Second), chargeUC.DeliverCallbacksUC)
+
+	//Settlements
+	settlementRepo := settlementRepository.NewSettlementRepository(db)
+	settlementUC := settlementUsecase.NewSettlementUsecase(settlementRepo, merchantRepo)
+	settlementDelivery.NewSettlementDelivery(v1Group, settlementUC)
+	go scheduler.Every(context.Background(), "settlements", scheduler.Interval("SETTLEMENT_INTERVAL", time.Hour), settlementUC.SettleUC)
+
+	//Withdrawals
+	withdrawalRepo := withdrawalRepository.NewWithdrawalRepository(db)
+	withdrawalUC := withdrawalUsecase.NewWithdrawalUsecase(withdrawalRepo, txAuthorizer, disbursement.New(client))
+	withdrawalDelivery.NewWithdrawalDelivery(v1Group, withdrawalUC, idempotencyStore)
+
+	//Reconciliation
+	reconciliationRepo := reconciliationRepository.NewReconciliationRepository(db)
+	reconciliationUC := reconciliationUsecase.NewReconciliationUsecase(reconciliationRepo)
+	reconciliationDelivery.NewReconciliationDelivery(v1Group, reconciliationUC)
+
+	//Pockets
+	pocketRepo := pocketRepository.NewPocketRepository(db)
+	pocketUC := pocketUsecase.NewPocketUsecase(pocketRepo)
+	pocketDelivery.NewPocketDelivery(v1Group, pocketUC, idempotencyStore)
+
+	//FX
+	fxRepo := fxRepository.NewFxRepository(db)
+	fxUC := fxUsecase.NewFxUsecase(fxRepo, exchangeRate.New(db, client))
+	fxDelivery.NewFxDelivery(v1Group, fxUC, idempotencyStore)
+
+	//Statements
+	statementRepo := statementRepository.NewStatementRepository(db)
+	statementUC := statementUsecase.NewStatementUsecase(statementRepo)
+	statementDelivery.NewStatementDelivery(v1Group, statementUC)
 }
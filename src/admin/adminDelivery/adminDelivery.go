@@ -1,12 +1,14 @@
 package adminDelivery
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/adminDto"
 	"final-project-enigma/model/dto/json"
+	"final-project-enigma/pkg/ledger"
 	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
 	"final-project-enigma/pkg/validation"
 	"final-project-enigma/src/admin"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,8 +30,11 @@ func NewAdminDelivery(router *gin.RouterGroup, adminUsecase admin.AdminUsecase)
 		adminGroup.PUT("/paymentMethod/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.UpdatePaymentMethod)
 		adminGroup.DELETE("/paymentMethod/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.SoftDeletePaymentMethod)
 		adminGroup.GET("/wallet", handler.GetWalletByParams)
+		adminGroup.POST("/wallet/:id/freeze", middleware.JwtAuthWithRoles("ADMIN"), handler.FreezeWallet)
+		adminGroup.POST("/wallet/:id/unfreeze", middleware.JwtAuthWithRoles("ADMIN"), handler.UnfreezeWallet)
 		//transaction
 		adminGroup.GET("/transaction", middleware.JwtAuthWithRoles("ADMIN"), handler.GetTransaction)
+		adminGroup.POST("/transaction/:id/reverse", middleware.JwtAuthWithRoles("ADMIN"), handler.ReverseTransaction)
 	}
 }
 
@@ -41,8 +46,13 @@ func (d *adminDelivery) SavePaymentMethod(c *gin.Context) {
 			json.NewResponBadRequest(c, validationError, "bad request", "01", "02")
 			return
 		}
+		json.NewResponseError(c, "json request body required", "01", "02")
+		return
 	}
 	if err := d.adminUsecase.SavePaymentMethod(req); err != nil {
+		if paymentMethodBadRequest(c, err) {
+			return
+		}
 		json.NewResponseError(c, err.Error(), "failed to add payment method", "01")
 		return
 	}
@@ -88,11 +98,16 @@ func (d *adminDelivery) UpdatePaymentMethod(c *gin.Context) {
 			json.NewResponBadRequest(c, validationError, "bad request", "01", "02")
 			return
 		}
+		json.NewResponseError(c, "json request body required", "01", "02")
+		return
 	}
 
 	updatePaymentMethod.ID = paymentMethodID
 
 	if err := d.adminUsecase.UpdatePaymentMethod(updatePaymentMethod); err != nil {
+		if paymentMethodBadRequest(c, err) {
+			return
+		}
 		json.NewResponseError(c, err.Error(), "failed to update category", "01")
 		return
 	}
@@ -100,6 +115,20 @@ func (d *adminDelivery) UpdatePaymentMethod(c *gin.Context) {
 	json.NewResponSucces(c, updatePaymentMethod, "payment method updated successfully", "01", "05")
 }
 
+// paymentMethodBadRequest answers payment method fields the usecase
+// rejected and reports whether it did.
+func paymentMethodBadRequest(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, admin.ErrInvalidPaymentMethodAmount):
+		json.NewResponBadRequest(c, []json.ValidationField{{FieldName: "min_amount", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, admin.ErrInvalidGatewayConfig):
+		json.NewResponBadRequest(c, []json.ValidationField{{FieldName: "gateway_config", Message: err.Error()}}, "bad request", "01", "02")
+	default:
+		return false
+	}
+	return true
+}
+
 func (d *adminDelivery) SoftDeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	err := d.adminUsecase.SoftDeleteUser(userID)
@@ -163,7 +192,7 @@ func (d *adminDelivery) GetWalletByParams(c *gin.Context) {
 	}
 
 	if minBalanceStr := c.Query("min_balance"); minBalanceStr != "" {
-		minBalance, err := strconv.ParseFloat(minBalanceStr, 64)
+		minBalance, err := money.Parse(minBalanceStr)
 		if err != nil {
 			json.NewResponseError(c, "invalid min_balance format", "02", "02")
 			return
@@ -172,7 +201,7 @@ func (d *adminDelivery) GetWalletByParams(c *gin.Context) {
 	}
 
 	if maxBalanceStr := c.Query("max_balance"); maxBalanceStr != "" {
-		maxBalance, err := strconv.ParseFloat(maxBalanceStr, 64)
+		maxBalance, err := money.Parse(maxBalanceStr)
 		if err != nil {
 			json.NewResponseError(c, "invalid max_balance format", "02", "02")
 			return
@@ -208,3 +237,93 @@ func (d *adminDelivery) GetTransaction(ctx *gin.Context) {
 
 	json.NewResponSuccesPaging(ctx, resp, "Succes get transaction history", "01", "01", params.Page, totalData)
 }
+
+func (d *adminDelivery) ReverseTransaction(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+
+	var req adminDto.ReverseTransactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.TransactionId = ctx.Param("id")
+
+	resp, err := d.adminUsecase.ReverseTransactionUC(req, authHeader)
+	if err != nil {
+		switch {
+		case errors.Is(err, admin.ErrTransactionNotFound):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+		case errors.Is(err, admin.ErrTransactionNotReversible):
+			json.NewResponseConflict(ctx, err.Error(), "01", "04")
+		case errors.Is(err, ledger.ErrInsufficientBalance):
+			json.NewResponseForbidden(ctx, "recipient no longer has enough balance to reverse this transaction", "01", "05")
+		case errors.Is(err, ledger.ErrWalletFrozen):
+			json.NewResponseForbidden(ctx, "a wallet involved in this transaction is frozen", "01", "06")
+		default:
+			json.NewResponseError(ctx, err.Error(), "01", "01")
+		}
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "transaction reversed", "01", "01")
+}
+
+func (d *adminDelivery) FreezeWallet(ctx *gin.Context) {
+	var req adminDto.FreezeWalletRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := d.adminUsecase.FreezeWalletUC(ctx.Param("id"), req, ctx.GetHeader("Authorization"))
+	if err != nil {
+		walletStatusErrorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "wallet frozen", "01", "01")
+}
+
+func (d *adminDelivery) UnfreezeWallet(ctx *gin.Context) {
+	var req adminDto.UnfreezeWalletRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := d.adminUsecase.UnfreezeWalletUC(ctx.Param("id"), req, ctx.GetHeader("Authorization"))
+	if err != nil {
+		walletStatusErrorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "wallet unfrozen", "01", "01")
+}
+
+func walletStatusErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, admin.ErrStatusReasonRequired):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "reason", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, admin.ErrWalletNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, admin.ErrWalletStatusUnchanged):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
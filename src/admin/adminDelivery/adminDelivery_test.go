@@ -76,6 +76,18 @@ func (m *mockAdminUsecase) GetTransactionUC(params adminDto.GetTransactionParams
 	return []adminDto.GetTransactionResponse{}, "", nil
 }
 
+func (m *mockAdminUsecase) ReverseTransactionUC(req adminDto.ReverseTransactionRequest, authHeader string) (adminDto.ReverseTransactionResponse, error) {
+	return adminDto.ReverseTransactionResponse{}, nil
+}
+
+func (m *mockAdminUsecase) FreezeWalletUC(walletId string, req adminDto.FreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error) {
+	return adminDto.WalletStatus{}, nil
+}
+
+func (m *mockAdminUsecase) UnfreezeWalletUC(walletId string, req adminDto.UnfreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error) {
+	return adminDto.WalletStatus{}, nil
+}
+
 func TestSavePaymentMethod_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -1,9 +1,22 @@
 package admin
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/adminDto"
 )
 
+var (
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrTransactionNotReversible = errors.New("only successful transfers to other users and merchant payments can be reversed")
+
+	ErrInvalidPaymentMethodAmount = errors.New("min_amount and max_amount must be positive and min_amount must not exceed max_amount")
+	ErrInvalidGatewayConfig       = errors.New("gateway_config must be a JSON object keyed by gateway name")
+
+	ErrWalletNotFound        = errors.New("wallet not found")
+	ErrWalletStatusUnchanged = errors.New("wallet already has this status")
+	ErrStatusReasonRequired  = errors.New("a reason is required to change a wallet's status")
+)
+
 type AdminRepository interface {
 	UpdateUser(userID adminDto.User) error
 	SoftDeleteUser(userID string) error
@@ -14,6 +27,8 @@ type AdminRepository interface {
 	SoftDeletePaymentMethod(paymentMethodID string) error
 	UpdatePaymentMethod(paymenmethodID adminDto.PaymentMethod) error
 	GetTransactionRepo(params adminDto.GetTransactionParams) ([]adminDto.GetTransactionResponse, int, error)
+	ReverseTransaction(req adminDto.ReverseTransactionRequest) (adminDto.ReverseTransactionResponse, error)
+	SetWalletStatus(req adminDto.WalletStatusRequest) (adminDto.WalletStatus, error)
 }
 
 type AdminUsecase interface {
@@ -26,4 +41,7 @@ type AdminUsecase interface {
 	SoftDeletePaymentMethod(paymentMethodID string) error
 	UpdatePaymentMethod(request adminDto.UpdatePaymentRequest) error
 	GetTransactionUC(params adminDto.GetTransactionParams) ([]adminDto.GetTransactionResponse, string, error)
+	ReverseTransactionUC(req adminDto.ReverseTransactionRequest, authHeader string) (adminDto.ReverseTransactionResponse, error)
+	FreezeWalletUC(walletId string, req adminDto.FreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error)
+	UnfreezeWalletUC(walletId string, req adminDto.UnfreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error)
 }
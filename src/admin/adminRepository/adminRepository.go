@@ -4,6 +4,11 @@ import (
 	"database/sql"
 	"errors"
 	"final-project-enigma/model/dto/adminDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/admin"
 	"time"
 
 	"fmt"
@@ -218,7 +223,7 @@ func (r *adminRepo) UpdateUser(user adminDto.User) error {
 }
 
 func (r *adminRepo) GetpaymentMethodByParams(params adminDto.GetPaymentMethodParams) ([]adminDto.PaymentMethod, error) {
-	query := "SELECT id, payment_name,created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL"
+	query := "SELECT id, payment_name, gateway_code, COALESCE(channel_type, ''), enabled, min_amount, max_amount, expiry_minutes, display_order, COALESCE(icon_url, ''), gateway_config, created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL"
 	var args []interface{}
 	argIndex := 1
 
@@ -239,6 +244,7 @@ func (r *adminRepo) GetpaymentMethodByParams(params adminDto.GetPaymentMethodPar
 		args = append(args, params.CreatedAt)
 		argIndex++
 	}
+	query += " ORDER BY display_order, payment_name"
 	if params.Page != "" && params.Limit != "" {
 		page, err := strconv.Atoi(params.Page)
 		if err != nil {
@@ -262,7 +268,9 @@ func (r *adminRepo) GetpaymentMethodByParams(params adminDto.GetPaymentMethodPar
 	var paymentMethods []adminDto.PaymentMethod
 	for rows.Next() {
 		var paymentMethod adminDto.PaymentMethod
-		err := rows.Scan(&paymentMethod.ID, &paymentMethod.PaymentName, &paymentMethod.CreatedAt)
+		err := rows.Scan(&paymentMethod.ID, &paymentMethod.PaymentName, &paymentMethod.GatewayCode, &paymentMethod.ChannelType,
+			&paymentMethod.Enabled, &paymentMethod.MinAmount, &paymentMethod.MaxAmount, &paymentMethod.ExpiryMinutes,
+			&paymentMethod.DisplayOrder, &paymentMethod.IconURL, &paymentMethod.GatewayConfig, &paymentMethod.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -278,17 +286,20 @@ func (r *adminRepo) GetpaymentMethodByParams(params adminDto.GetPaymentMethodPar
 
 	return paymentMethods, nil
 }
-func (r *adminRepo) checkPaymentMethodExists(paymentName string) (bool, error) {
+
+// checkPaymentMethodExists reports whether another method than excludeID
+// already uses paymentName.
+func (r *adminRepo) checkPaymentMethodExists(paymentName, excludeID string) (bool, error) {
 	var exists bool
-	query := "SELECT EXISTS(SELECT 1 FROM payment_method WHERE LOWER(payment_name) = LOWER($1) AND deleted_at IS NULL)"
-	err := r.db.QueryRow(query, paymentName).Scan(&exists)
+	query := "SELECT EXISTS(SELECT 1 FROM payment_method WHERE LOWER(payment_name) = LOWER($1) AND deleted_at IS NULL AND id::text <> $2)"
+	err := r.db.QueryRow(query, paymentName, excludeID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
 	return exists, nil
 }
 func (r *adminRepo) SavePaymentMethod(paymentMethod adminDto.PaymentMethod) error {
-	exists, err := r.checkPaymentMethodExists(paymentMethod.PaymentName)
+	exists, err := r.checkPaymentMethodExists(paymentMethod.PaymentName, "")
 	if err != nil {
 		return err
 	}
@@ -297,8 +308,9 @@ func (r *adminRepo) SavePaymentMethod(paymentMethod adminDto.PaymentMethod) erro
 		return errors.New("payment method name already exists")
 	}
 
-	query := "INSERT INTO payment_method(payment_name) VALUES($1)"
-	_, err = r.db.Exec(query, paymentMethod.PaymentName)
+	query := "INSERT INTO payment_method(payment_name, gateway_code, channel_type, enabled, min_amount, max_amount, expiry_minutes, display_order, icon_url, gateway_config) VALUES($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), $10)"
+	_, err = r.db.Exec(query, paymentMethod.PaymentName, paymentMethod.GatewayCode, paymentMethod.ChannelType, paymentMethod.Enabled,
+		paymentMethod.MinAmount, paymentMethod.MaxAmount, paymentMethod.ExpiryMinutes, paymentMethod.DisplayOrder, paymentMethod.IconURL, []byte(paymentMethod.GatewayConfig))
 	if err != nil {
 		return err
 	}
@@ -321,7 +333,7 @@ func (r *adminRepo) SoftDeletePaymentMethod(paymentMethodID string) error {
 }
 
 func (r *adminRepo) UpdatePaymentMethod(paymentMethod adminDto.PaymentMethod) error {
-	exists, err := r.checkPaymentMethodExists(paymentMethod.PaymentName)
+	exists, err := r.checkPaymentMethodExists(paymentMethod.PaymentName, paymentMethod.ID)
 	if err != nil {
 		return err
 	}
@@ -329,8 +341,9 @@ func (r *adminRepo) UpdatePaymentMethod(paymentMethod adminDto.PaymentMethod) er
 		log.Error().Msg("payment method name already exists")
 		return errors.New("payment method name already exists")
 	}
-	query := "UPDATE payment_method SET payment_name=$1, updated_at=$2 WHERE id=$3 AND deleted_at IS NULL"
-	result, err := r.db.Exec(query, paymentMethod.PaymentName, time.Now(), paymentMethod.ID)
+	query := "UPDATE payment_method SET payment_name=$1, updated_at=$2, gateway_code=$4, channel_type=NULLIF($5, ''), enabled=$6, min_amount=$7, max_amount=$8, expiry_minutes=$9, display_order=$10, icon_url=NULLIF($11, ''), gateway_config=$12 WHERE id=$3 AND deleted_at IS NULL"
+	result, err := r.db.Exec(query, paymentMethod.PaymentName, time.Now(), paymentMethod.ID, paymentMethod.GatewayCode, paymentMethod.ChannelType, paymentMethod.Enabled,
+		paymentMethod.MinAmount, paymentMethod.MaxAmount, paymentMethod.ExpiryMinutes, paymentMethod.DisplayOrder, paymentMethod.IconURL, []byte(paymentMethod.GatewayConfig))
 	if err != nil {
 		return err
 	}
@@ -345,7 +358,7 @@ func (r *adminRepo) UpdatePaymentMethod(paymentMethod adminDto.PaymentMethod) er
 }
 func (r *adminRepo) GetWalletByParams(params adminDto.GetWalletParams) ([]adminDto.Wallet, error) {
 	query := `
-	SELECT w.id, w.user_id, w.balance, w.created_at, u.fullname, u.username
+	SELECT w.id, w.user_id, w.balance, w.status, w.created_at, u.fullname, u.username
 	FROM wallets w
 	JOIN users u ON w.user_id = u.id
 	WHERE 1=1`
@@ -417,7 +430,7 @@ func (r *adminRepo) GetWalletByParams(params adminDto.GetWalletParams) ([]adminD
 	var wallets []adminDto.Wallet
 	for rows.Next() {
 		var wallet adminDto.Wallet
-		err := rows.Scan(&wallet.ID, &wallet.User_id, &wallet.Balance, &wallet.CreatedAt, &wallet.Fullname, &wallet.Username)
+		err := rows.Scan(&wallet.ID, &wallet.User_id, &wallet.Balance, &wallet.Status, &wallet.CreatedAt, &wallet.Fullname, &wallet.Username)
 		if err != nil {
 			return nil, err
 		}
@@ -610,3 +623,167 @@ func (r *adminRepo) GetTransactionRepo(params adminDto.GetTransactionParams) ([]
 
 	return resp, totalData, nil
 }
+
+// ReverseTransaction moves the funds of a successful transfer or merchant
+// payment back through a new transaction linked by reversal_of, and marks the
+// original as reversed. The payer gets the fee back too, taken from the
+// platform wallet. A transfer can only be reversed while the recipient still
+// holds the amount. Moves between a user's own pockets, currency conversions
+// included, are not reversible: the user can move the money back themselves.
+func (r *adminRepo) ReverseTransaction(req adminDto.ReverseTransactionRequest) (adminDto.ReverseTransactionResponse, error) {
+	var reversalId string
+
+	err := dbtx.WithRetry(r.db, func(tx *sql.Tx) error {
+		var userId, status, currency string
+		var amount, fee money.Money
+		var reversalOf sql.NullString
+		lockQuery := `SELECT user_id, currency, amount, fee, status, reversal_of FROM transactions WHERE id = $1 FOR UPDATE`
+		err := tx.QueryRow(lockQuery, req.TransactionId).Scan(&userId, &currency, &amount, &fee, &status, &reversalOf)
+		if err == sql.ErrNoRows {
+			log.Error().Msg("transaction not found")
+			return admin.ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if status != "success" || reversalOf.Valid {
+			log.Error().Msg("transaction " + req.TransactionId + " is " + status + ", cannot reverse")
+			return admin.ErrTransactionNotReversible
+		}
+
+		insertQuery := `
+			INSERT INTO transactions (user_id, transaction_type, currency, amount, description, status, reversal_of, reversal_reason, reversed_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, 'success', $6, $7, $8, $9)
+			RETURNING id
+		`
+		currentTime := time.Now()
+
+		var fromWalletId, toWalletId, recipientId string
+		var ownPockets bool
+		walletTransactionQuery := `
+			SELECT wt.from_wallet_id, wt.to_wallet_id, w.user_id, fw.user_id = w.user_id
+			FROM wallet_transactions wt
+			JOIN wallets w ON w.id = wt.to_wallet_id
+			JOIN wallets fw ON fw.id = wt.from_wallet_id
+			WHERE wt.transaction_id = $1
+		`
+		err = tx.QueryRow(walletTransactionQuery, req.TransactionId).Scan(&fromWalletId, &toWalletId, &recipientId, &ownPockets)
+		switch {
+		case err == nil && ownPockets:
+			log.Error().Msg("transaction " + req.TransactionId + " moved money between the user's own pockets, cannot reverse")
+			return admin.ErrTransactionNotReversible
+		case err == nil:
+			err = tx.QueryRow(insertQuery, recipientId, "debit", currency, amount, "Transfer-Reversal", req.TransactionId, req.Reason, req.AdminId, currentTime).Scan(&reversalId)
+			if err != nil {
+				return err
+			}
+
+			reversalWalletQuery := `
+				INSERT INTO wallet_transactions (transaction_id, from_wallet_id, to_wallet_id, created_at)
+				VALUES ($1, $2, $3, $4)
+			`
+			if _, err := tx.Exec(reversalWalletQuery, reversalId, toWalletId, fromWalletId, currentTime); err != nil {
+				return err
+			}
+
+			entries := append([]ledger.Entry{
+				ledger.Debit(ledger.AccountWallet, toWalletId, amount),
+				ledger.CreditOwed(ledger.AccountWallet, fromWalletId, amount+fee),
+			}, fees.RefundEntries(fee)...)
+			err = ledger.Post(tx, reversalId, entries...)
+			if err != nil {
+				return err
+			}
+		case err == sql.ErrNoRows:
+			var merchantId, merchantWalletId, walletId string
+			merchantQuery := `
+				SELECT mt.merchant_id, m.wallet_id, w.id
+				FROM merchant_transactions mt
+				JOIN merchant m ON m.id = mt.merchant_id
+				JOIN wallets w ON w.user_id = $2 AND w.is_main
+				WHERE mt.transaction_id = $1
+			`
+			err = tx.QueryRow(merchantQuery, req.TransactionId, userId).Scan(&merchantId, &merchantWalletId, &walletId)
+			if err == sql.ErrNoRows {
+				log.Error().Msg("transaction " + req.TransactionId + " is not a transfer or merchant payment")
+				return admin.ErrTransactionNotReversible
+			}
+			if err != nil {
+				return err
+			}
+
+			err = tx.QueryRow(insertQuery, userId, "credit", currency, amount, "Merchant-Payment-Reversal", req.TransactionId, req.Reason, req.AdminId, currentTime).Scan(&reversalId)
+			if err != nil {
+				return err
+			}
+
+			reversalMerchantQuery := `
+				INSERT INTO merchant_transactions (transaction_id, merchant_id, created_at)
+				VALUES ($1, $2, $3)
+			`
+			if _, err := tx.Exec(reversalMerchantQuery, reversalId, merchantId, currentTime); err != nil {
+				return err
+			}
+
+			entries := append([]ledger.Entry{
+				ledger.Debit(ledger.AccountWallet, merchantWalletId, amount),
+				ledger.CreditOwed(ledger.AccountWallet, walletId, amount+fee),
+			}, fees.RefundEntries(fee)...)
+			err = ledger.Post(tx, reversalId, entries...)
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE transactions SET status = 'reversed' WHERE id = $1`, req.TransactionId)
+		return err
+	})
+	if err != nil {
+		return adminDto.ReverseTransactionResponse{}, err
+	}
+
+	return adminDto.ReverseTransactionResponse{
+		TransactionId:         req.TransactionId,
+		ReversalTransactionId: reversalId,
+		Reason:                req.Reason,
+	}, nil
+}
+
+// SetWalletStatus changes the status of an open wallet and records who
+// changed it and why in wallet_status_changes.
+func (r *adminRepo) SetWalletStatus(req adminDto.WalletStatusRequest) (adminDto.WalletStatus, error) {
+	resp := adminDto.WalletStatus{WalletId: req.WalletId, Status: req.Status, Reason: req.Reason}
+	err := dbtx.WithRetry(r.db, func(tx *sql.Tx) error {
+		lockQuery := `SELECT status FROM wallets WHERE id::text = $1 AND deleted_at IS NULL FOR UPDATE`
+		err := tx.QueryRow(lockQuery, req.WalletId).Scan(&resp.PreviousStatus)
+		if err == sql.ErrNoRows {
+			return admin.ErrWalletNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		if resp.PreviousStatus == req.Status {
+			return admin.ErrWalletStatusUnchanged
+		}
+
+		resp.UpdatedAt = time.Now()
+		updateQuery := `UPDATE wallets SET status = $1, status_reason = $2, updated_at = $3 WHERE id = $4`
+		if _, err := tx.Exec(updateQuery, req.Status, req.Reason, resp.UpdatedAt, req.WalletId); err != nil {
+			return fmt.Errorf("failed to update wallet status: %w", err)
+		}
+
+		historyQuery := `
+			INSERT INTO wallet_status_changes (wallet_id, from_status, to_status, reason, changed_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		_, err = tx.Exec(historyQuery, req.WalletId, resp.PreviousStatus, req.Status, req.Reason, req.AdminId, resp.UpdatedAt)
+		return err
+	})
+	if err != nil {
+		return adminDto.WalletStatus{}, err
+	}
+
+	return resp, nil
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"final-project-enigma/model/dto/adminDto"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/src/admin"
 	"testing"
 	"time"
 
@@ -195,14 +198,16 @@ func TestGetpaymentMethodByParams(t *testing.T) {
 			ID: "123",
 		}
 
-		mock.ExpectQuery("SELECT id, payment_name,created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL AND id = \\$1").
+		mock.ExpectQuery("SELECT id, payment_name, gateway_code, .+, gateway_config, created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL AND id = \\$1").
 			WithArgs("123").
-			WillReturnRows(sqlmock.NewRows([]string{"id", "payment_name", "created_at"}).
-				AddRow("123", "Credit Card", time.Now()))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "payment_name", "gateway_code", "channel_type", "enabled", "min_amount", "max_amount", "expiry_minutes", "display_order", "icon_url", "gateway_config", "created_at"}).
+				AddRow("123", "Credit Card", "credit_card", "card", true, "10000.00", nil, 1440, 1, "", []byte(`{}`), time.Now()))
 
 		paymentMethods, err := repo.GetpaymentMethodByParams(params)
 		assert.NoError(t, err)
 		assert.Len(t, paymentMethods, 1)
+		assert.Equal(t, "10000.00", paymentMethods[0].MinAmount.String())
+		assert.Nil(t, paymentMethods[0].MaxAmount)
 	})
 
 	t.Run("Payment method not found", func(t *testing.T) {
@@ -210,7 +215,7 @@ func TestGetpaymentMethodByParams(t *testing.T) {
 			PaymentName: "Credit Card",
 		}
 
-		mock.ExpectQuery("SELECT id, payment_name,created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL AND payment_name LIKE \\$1").
+		mock.ExpectQuery("SELECT id, payment_name, gateway_code, .+, gateway_config, created_at FROM payment_method WHERE 1=1 AND deleted_at IS NULL AND payment_name LIKE \\$1").
 			WithArgs("%Credit Card%").
 			WillReturnRows(sqlmock.NewRows([]string{}))
 
@@ -237,12 +242,12 @@ func TestSavePaymentMethod(t *testing.T) {
 			PaymentName: "Credit Card",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Credit Card").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Credit Card", "").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		mock.ExpectExec("INSERT INTO payment_method\\(payment_name\\) VALUES\\(\\$1\\)").
-			WithArgs("Credit Card").
+		mock.ExpectExec("INSERT INTO payment_method\\(payment_name, gateway_code, .+\\) VALUES").
+			WithArgs("Credit Card", "", "", false, nil, nil, 0, 0, "", []byte(nil)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := repo.SavePaymentMethod(paymentMethod)
@@ -254,8 +259,8 @@ func TestSavePaymentMethod(t *testing.T) {
 			PaymentName: "PayPal",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("PayPal").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("PayPal", "").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 
 		err := repo.SavePaymentMethod(paymentMethod)
@@ -268,8 +273,8 @@ func TestSavePaymentMethod(t *testing.T) {
 			PaymentName: "Debit Card",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Debit Card").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Debit Card", "").
 			WillReturnError(errors.New("database error"))
 
 		err := repo.SavePaymentMethod(paymentMethod)
@@ -282,12 +287,12 @@ func TestSavePaymentMethod(t *testing.T) {
 			PaymentName: "Bank Transfer",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Bank Transfer").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Bank Transfer", "").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		mock.ExpectExec("INSERT INTO payment_method\\(payment_name\\) VALUES\\(\\$1\\)").
-			WithArgs("Bank Transfer").
+		mock.ExpectExec("INSERT INTO payment_method\\(payment_name, gateway_code, .+\\) VALUES").
+			WithArgs("Bank Transfer", "", "", false, nil, nil, 0, 0, "", []byte(nil)).
 			WillReturnError(errors.New("database error"))
 
 		err := repo.SavePaymentMethod(paymentMethod)
@@ -358,12 +363,12 @@ func TestUpdatePaymentMethod(t *testing.T) {
 			PaymentName: "Credit Card",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Credit Card").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Credit Card", "123").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2 WHERE id=\\$3 AND deleted_at IS NULL").
-			WithArgs("Credit Card", sqlmock.AnyArg(), "123").
+		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2, .+ WHERE id=\\$3 AND deleted_at IS NULL").
+			WithArgs("Credit Card", sqlmock.AnyArg(), "123", "", "", false, nil, nil, 0, 0, "", []byte(nil)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := repo.UpdatePaymentMethod(paymentMethod)
@@ -376,8 +381,8 @@ func TestUpdatePaymentMethod(t *testing.T) {
 			PaymentName: "PayPal",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("PayPal").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("PayPal", "123").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 
 		err := repo.UpdatePaymentMethod(paymentMethod)
@@ -391,8 +396,8 @@ func TestUpdatePaymentMethod(t *testing.T) {
 			PaymentName: "Debit Card",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Debit Card").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Debit Card", "123").
 			WillReturnError(errors.New("kesalahan db"))
 
 		err := repo.UpdatePaymentMethod(paymentMethod)
@@ -406,12 +411,12 @@ func TestUpdatePaymentMethod(t *testing.T) {
 			PaymentName: "Bank Transfer",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Bank Transfer").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Bank Transfer", "123").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2 WHERE id=\\$3 AND deleted_at IS NULL").
-			WithArgs("Bank Transfer", sqlmock.AnyArg(), "123").
+		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2, .+ WHERE id=\\$3 AND deleted_at IS NULL").
+			WithArgs("Bank Transfer", sqlmock.AnyArg(), "123", "", "", false, nil, nil, 0, 0, "", []byte(nil)).
 			WillReturnError(errors.New("kesalahan db"))
 
 		err := repo.UpdatePaymentMethod(paymentMethod)
@@ -425,12 +430,12 @@ func TestUpdatePaymentMethod(t *testing.T) {
 			PaymentName: "Bitcoin",
 		}
 
-		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL\\)").
-			WithArgs("Bitcoin").
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM payment_method WHERE LOWER\\(payment_name\\) = LOWER\\(\\$1\\) AND deleted_at IS NULL AND id::text <> \\$2\\)").
+			WithArgs("Bitcoin", "999").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2 WHERE id=\\$3 AND deleted_at IS NULL").
-			WithArgs("Bitcoin", sqlmock.AnyArg(), "999").
+		mock.ExpectExec("UPDATE payment_method SET payment_name=\\$1, updated_at=\\$2, .+ WHERE id=\\$3 AND deleted_at IS NULL").
+			WithArgs("Bitcoin", sqlmock.AnyArg(), "999", "", "", false, nil, nil, 0, 0, "", []byte(nil)).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.UpdatePaymentMethod(paymentMethod)
@@ -438,3 +443,104 @@ func TestUpdatePaymentMethod(t *testing.T) {
 		assert.Equal(t, sql.ErrNoRows, err)
 	})
 }
+
+func TestReverseTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error initializing sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := &adminRepo{db}
+	req := adminDto.ReverseTransactionRequest{TransactionId: "trx1", AdminId: "admin1", Reason: "sent to wrong number"}
+
+	t.Run("Already reversed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id, currency, amount, fee, status, reversal_of FROM transactions WHERE id = \\$1 FOR UPDATE").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount", "fee", "status", "reversal_of"}).AddRow("u1", "IDR", "100.00", "0.00", "reversed", nil))
+		mock.ExpectRollback()
+
+		_, err := repo.ReverseTransaction(req)
+		assert.True(t, errors.Is(err, admin.ErrTransactionNotReversible))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Recipient no longer has the balance", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id, currency, amount, fee, status, reversal_of FROM transactions WHERE id = \\$1 FOR UPDATE").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount", "fee", "status", "reversal_of"}).AddRow("u1", "IDR", "100.00", "0.00", "success", nil))
+		mock.ExpectQuery("SELECT wt.from_wallet_id, wt.to_wallet_id, w.user_id").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"from_wallet_id", "to_wallet_id", "user_id", "own"}).AddRow("wa", "wb", "u2", false))
+		mock.ExpectQuery("INSERT INTO transactions").
+			WithArgs("u2", "debit", "IDR", int64(100), "Transfer-Reversal", "trx1", req.Reason, "admin1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("trx2"))
+		mock.ExpectExec("INSERT INTO wallet_transactions").
+			WithArgs("trx2", "wb", "wa", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT balance FROM wallets").WithArgs("wa").WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("0.00"))
+		mock.ExpectQuery("SELECT balance FROM wallets").WithArgs("wb").WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("50.00"))
+		mock.ExpectQuery("UPDATE wallets SET balance = balance -").
+			WithArgs(int64(100), sqlmock.AnyArg(), "wb").
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+		mock.ExpectRollback()
+
+		_, err := repo.ReverseTransaction(req)
+		assert.True(t, errors.Is(err, ledger.ErrInsufficientBalance))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Move between own pockets", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id, currency, amount, fee, status, reversal_of FROM transactions WHERE id = \\$1 FOR UPDATE").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount", "fee", "status", "reversal_of"}).AddRow("u1", "USD", "2500.00", "0.00", "success", nil))
+		mock.ExpectQuery("SELECT wt.from_wallet_id, wt.to_wallet_id, w.user_id").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"from_wallet_id", "to_wallet_id", "user_id", "own"}).AddRow("wa", "wc", "u1", true))
+		mock.ExpectRollback()
+
+		_, err := repo.ReverseTransaction(req)
+		assert.True(t, errors.Is(err, admin.ErrTransactionNotReversible))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Transfer fee is refunded", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id, currency, amount, fee, status, reversal_of FROM transactions WHERE id = \\$1 FOR UPDATE").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "currency", "amount", "fee", "status", "reversal_of"}).AddRow("u1", "IDR", "100.00", "5.00", "success", nil))
+		mock.ExpectQuery("SELECT wt.from_wallet_id, wt.to_wallet_id, w.user_id").
+			WithArgs("trx1").
+			WillReturnRows(sqlmock.NewRows([]string{"from_wallet_id", "to_wallet_id", "user_id", "own"}).AddRow("wa", "wb", "u2", false))
+		mock.ExpectQuery("INSERT INTO transactions").
+			WithArgs("u2", "debit", "IDR", int64(100), "Transfer-Reversal", "trx1", req.Reason, "admin1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("trx2"))
+		mock.ExpectExec("INSERT INTO wallet_transactions").
+			WithArgs("trx2", "wb", "wa", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		for _, id := range []string{fees.PlatformWalletId, "wa", "wb"} {
+			mock.ExpectQuery("SELECT balance FROM wallets").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("100.00"))
+		}
+		for _, e := range []struct {
+			walletId, entryType string
+			amount              int64
+		}{{"wb", ledger.EntryDebit, 100}, {"wa", ledger.EntryCredit, 105}, {fees.PlatformWalletId, ledger.EntryDebit, 5}} {
+			mock.ExpectQuery("UPDATE wallets").
+				WithArgs(e.amount, sqlmock.AnyArg(), e.walletId).
+				WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("0.00", "IDR", "active"))
+			mock.ExpectExec("INSERT INTO ledger_entries").
+				WithArgs("trx2", ledger.AccountWallet, e.walletId, e.entryType, e.amount, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectExec("UPDATE transactions SET status = 'reversed'").WithArgs("trx1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		resp, err := repo.ReverseTransaction(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "trx2", resp.ReversalTransactionId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,10 +1,15 @@
 package adminUsecase
 
 import (
+	"database/sql"
+	"encoding/json"
 	"final-project-enigma/model/dto/adminDto"
 	"final-project-enigma/pkg/helper/hashingPassword"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
 	"final-project-enigma/src/admin"
 	"strconv"
+	"strings"
 )
 
 type adminUC struct {
@@ -67,9 +72,101 @@ func (u *adminUC) GetWalletByParams(params adminDto.GetWalletParams) ([]adminDto
 	}
 	return wallet, nil
 }
+
+// defaultPaymentExpiryMinutes is how long a top-up can be paid when the
+// method does not say otherwise.
+const defaultPaymentExpiryMinutes = 24 * 60
+
+// paymentMethod applies the defaults of a create request.
+func paymentMethod(id string, request adminDto.CreatePaymentMethod) (adminDto.PaymentMethod, error) {
+	method := adminDto.PaymentMethod{
+		ID:            id,
+		PaymentName:   request.PaymentName,
+		GatewayCode:   request.GatewayCode,
+		ChannelType:   request.ChannelType,
+		Enabled:       true,
+		MinAmount:     request.MinAmount,
+		MaxAmount:     request.MaxAmount,
+		ExpiryMinutes: defaultPaymentExpiryMinutes,
+		DisplayOrder:  request.DisplayOrder,
+		IconURL:       request.IconURL,
+		GatewayConfig: json.RawMessage("{}"),
+	}
+	if request.Enabled != nil {
+		method.Enabled = *request.Enabled
+	}
+	if request.ExpiryMinutes > 0 {
+		method.ExpiryMinutes = request.ExpiryMinutes
+	}
+
+	if len(request.GatewayConfig) > 0 {
+		method.GatewayConfig = request.GatewayConfig
+	}
+
+	if err := checkPaymentMethod(method); err != nil {
+		return adminDto.PaymentMethod{}, err
+	}
+	return method, nil
+}
+
+// mergePaymentMethod applies the fields an update request sent to the stored
+// method.
+func mergePaymentMethod(method adminDto.PaymentMethod, request adminDto.UpdatePaymentRequest) (adminDto.PaymentMethod, error) {
+	if request.PaymentName != nil {
+		method.PaymentName = *request.PaymentName
+	}
+	if request.GatewayCode != nil {
+		method.GatewayCode = *request.GatewayCode
+	}
+	if request.ChannelType != nil {
+		method.ChannelType = *request.ChannelType
+	}
+	if request.Enabled != nil {
+		method.Enabled = *request.Enabled
+	}
+	if request.MinAmount != nil {
+		method.MinAmount = request.MinAmount
+	}
+	if request.MaxAmount != nil {
+		method.MaxAmount = request.MaxAmount
+	}
+	if request.ExpiryMinutes != nil {
+		method.ExpiryMinutes = *request.ExpiryMinutes
+	}
+	if request.DisplayOrder != nil {
+		method.DisplayOrder = *request.DisplayOrder
+	}
+	if request.IconURL != nil {
+		method.IconURL = *request.IconURL
+	}
+	if len(request.GatewayConfig) > 0 {
+		method.GatewayConfig = request.GatewayConfig
+	}
+
+	if err := checkPaymentMethod(method); err != nil {
+		return adminDto.PaymentMethod{}, err
+	}
+	return method, nil
+}
+
+// checkPaymentMethod checks the fields that binding cannot.
+func checkPaymentMethod(method adminDto.PaymentMethod) error {
+	if method.MinAmount != nil && !method.MinAmount.IsPositive() ||
+		method.MaxAmount != nil && !method.MaxAmount.IsPositive() ||
+		method.MinAmount != nil && method.MaxAmount != nil && *method.MinAmount > *method.MaxAmount {
+		return admin.ErrInvalidPaymentMethodAmount
+	}
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(method.GatewayConfig, &config); err != nil || config == nil {
+		return admin.ErrInvalidGatewayConfig
+	}
+	return nil
+}
+
 func (u *adminUC) SavePaymentMethod(request adminDto.CreatePaymentMethod) error {
-	paymenMethod := adminDto.PaymentMethod{
-		PaymentName: request.PaymentName,
+	paymenMethod, err := paymentMethod("", request)
+	if err != nil {
+		return err
 	}
 
 	if err := u.adminRepo.SavePaymentMethod(paymenMethod); err != nil {
@@ -85,10 +182,21 @@ func (u *adminUC) SoftDeletePaymentMethod(paymenmethodID string) error {
 	}
 	return nil
 }
+
+// UpdatePaymentMethod loads the stored method and changes only the fields
+// the request sent, so a client that does not know a field leaves it as is.
 func (u *adminUC) UpdatePaymentMethod(request adminDto.UpdatePaymentRequest) error {
-	UpdatePaymentMethod := adminDto.PaymentMethod{
-		ID:          request.ID,
-		PaymentName: request.PaymentName,
+	stored, err := u.adminRepo.GetpaymentMethodByParams(adminDto.GetPaymentMethodParams{ID: request.ID})
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return sql.ErrNoRows
+	}
+
+	UpdatePaymentMethod, err := mergePaymentMethod(stored[0], request)
+	if err != nil {
+		return err
 	}
 
 	if err := u.adminRepo.UpdatePaymentMethod(UpdatePaymentMethod); err != nil {
@@ -108,3 +216,39 @@ func (u *adminUC) GetTransactionUC(params adminDto.GetTransactionParams) ([]admi
 	totalDataStr := strconv.Itoa(totalData)
 	return resp, totalDataStr, nil
 }
+
+func (u *adminUC) ReverseTransactionUC(req adminDto.ReverseTransactionRequest, authHeader string) (adminDto.ReverseTransactionResponse, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return adminDto.ReverseTransactionResponse{}, err
+	}
+	req.AdminId = adminId
+
+	return u.adminRepo.ReverseTransaction(req)
+}
+
+func (u *adminUC) FreezeWalletUC(walletId string, req adminDto.FreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error) {
+	return u.setWalletStatus(walletId, req.Status, req.Reason, authHeader)
+}
+
+func (u *adminUC) UnfreezeWalletUC(walletId string, req adminDto.UnfreezeWalletRequest, authHeader string) (adminDto.WalletStatus, error) {
+	return u.setWalletStatus(walletId, ledger.WalletActive, req.Reason, authHeader)
+}
+
+func (u *adminUC) setWalletStatus(walletId, status, reason, authHeader string) (adminDto.WalletStatus, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return adminDto.WalletStatus{}, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return adminDto.WalletStatus{}, admin.ErrStatusReasonRequired
+	}
+
+	return u.adminRepo.SetWalletStatus(adminDto.WalletStatusRequest{
+		WalletId: walletId,
+		AdminId:  adminId,
+		Status:   status,
+		Reason:   reason,
+	})
+}
@@ -1,15 +1,25 @@
 package adminUsecase_test
 
 import (
+	"encoding/json"
 	"errors"
 	"final-project-enigma/model/dto/adminDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/admin"
 	"final-project-enigma/src/admin/adminUsecase"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-type mockAdminRepo struct{}
+type mockAdminRepo struct {
+	storedPaymentMethod  adminDto.PaymentMethod
+	savedPaymentMethod   adminDto.PaymentMethod
+	updatedPaymentMethod adminDto.PaymentMethod
+	walletStatus         adminDto.WalletStatusRequest
+}
 
 func (m *mockAdminRepo) SoftDeleteUser(userID string) error {
 	if userID == "error" {
@@ -36,6 +46,9 @@ func (m *mockAdminRepo) GetpaymentMethodByParams(params adminDto.GetPaymentMetho
 	if params.ID == "error" {
 		return nil, errors.New("failed to get payment methods by params")
 	}
+	if params.ID != "" && params.ID == m.storedPaymentMethod.ID {
+		return []adminDto.PaymentMethod{m.storedPaymentMethod}, nil
+	}
 	return []adminDto.PaymentMethod{}, nil
 }
 
@@ -50,6 +63,7 @@ func (m *mockAdminRepo) SavePaymentMethod(paymentMethod adminDto.PaymentMethod)
 	if paymentMethod.PaymentName == "error" {
 		return errors.New("failed to save payment method")
 	}
+	m.savedPaymentMethod = paymentMethod
 	return nil
 }
 
@@ -64,6 +78,7 @@ func (m *mockAdminRepo) UpdatePaymentMethod(paymentMethod adminDto.PaymentMethod
 	if paymentMethod.ID == "error" {
 		return errors.New("failed to update payment method")
 	}
+	m.updatedPaymentMethod = paymentMethod
 	return nil
 }
 
@@ -71,6 +86,15 @@ func (m *mockAdminRepo) GetTransactionRepo(params adminDto.GetTransactionParams)
 	return []adminDto.GetTransactionResponse{}, 0, nil
 }
 
+func (m *mockAdminRepo) ReverseTransaction(req adminDto.ReverseTransactionRequest) (adminDto.ReverseTransactionResponse, error) {
+	return adminDto.ReverseTransactionResponse{}, nil
+}
+
+func (m *mockAdminRepo) SetWalletStatus(req adminDto.WalletStatusRequest) (adminDto.WalletStatus, error) {
+	m.walletStatus = req
+	return adminDto.WalletStatus{WalletId: req.WalletId, Status: req.Status, Reason: req.Reason}, nil
+}
+
 func TestSoftDeleteUser_Success(t *testing.T) {
 	adminRepo := &mockAdminRepo{}
 	adminUsecase := adminUsecase.NewAdminUsecase(adminRepo)
@@ -120,3 +144,79 @@ func TestUpdateUser_Failure(t *testing.T) {
 	err := adminUsecase.UpdateUser(user)
 	assert.Error(t, err)
 }
+
+func TestSavePaymentMethod_Defaults(t *testing.T) {
+	adminRepo := &mockAdminRepo{}
+	adminUsecase := adminUsecase.NewAdminUsecase(adminRepo)
+
+	err := adminUsecase.SavePaymentMethod(adminDto.CreatePaymentMethod{PaymentName: "OVO", GatewayCode: "ovo", ChannelType: "ewallet"})
+	assert.NoError(t, err)
+	assert.True(t, adminRepo.savedPaymentMethod.Enabled)
+	assert.Equal(t, 24*60, adminRepo.savedPaymentMethod.ExpiryMinutes)
+	assert.Equal(t, json.RawMessage("{}"), adminRepo.savedPaymentMethod.GatewayConfig)
+}
+
+func TestSavePaymentMethod_InvalidFields(t *testing.T) {
+	adminUsecase := adminUsecase.NewAdminUsecase(&mockAdminRepo{})
+	min, max := money.Money(50000), money.Money(10000)
+
+	err := adminUsecase.SavePaymentMethod(adminDto.CreatePaymentMethod{PaymentName: "OVO", MinAmount: &min, MaxAmount: &max})
+	assert.ErrorIs(t, err, admin.ErrInvalidPaymentMethodAmount)
+
+	err = adminUsecase.SavePaymentMethod(adminDto.CreatePaymentMethod{PaymentName: "OVO", GatewayConfig: json.RawMessage(`["midtrans"]`)})
+	assert.ErrorIs(t, err, admin.ErrInvalidGatewayConfig)
+}
+
+func TestUpdatePaymentMethod_KeepsFieldsLeftOut(t *testing.T) {
+	adminRepo := &mockAdminRepo{storedPaymentMethod: adminDto.PaymentMethod{
+		ID:            "pm-1",
+		PaymentName:   "BCA Virtual Account",
+		GatewayCode:   "bca_va",
+		ChannelType:   "va",
+		Enabled:       false,
+		ExpiryMinutes: 60,
+		GatewayConfig: json.RawMessage(`{"midtrans":{"bank":"bca"}}`),
+	}}
+	adminUsecase := adminUsecase.NewAdminUsecase(adminRepo)
+
+	name := "BCA VA"
+	err := adminUsecase.UpdatePaymentMethod(adminDto.UpdatePaymentRequest{ID: "pm-1", PaymentName: &name})
+	assert.NoError(t, err)
+
+	want := adminRepo.storedPaymentMethod
+	want.PaymentName = "BCA VA"
+	assert.Equal(t, want, adminRepo.updatedPaymentMethod)
+}
+
+func TestUpdatePaymentMethod_Invalid(t *testing.T) {
+	min := money.Money(50000)
+	max := money.Money(10000)
+	adminRepo := &mockAdminRepo{storedPaymentMethod: adminDto.PaymentMethod{ID: "pm-1", PaymentName: "OVO", MinAmount: &min, GatewayConfig: json.RawMessage("{}")}}
+	adminUsecase := adminUsecase.NewAdminUsecase(adminRepo)
+
+	err := adminUsecase.UpdatePaymentMethod(adminDto.UpdatePaymentRequest{ID: "pm-1", MaxAmount: &max})
+	assert.ErrorIs(t, err, admin.ErrInvalidPaymentMethodAmount)
+
+	err = adminUsecase.UpdatePaymentMethod(adminDto.UpdatePaymentRequest{ID: "pm-2"})
+	assert.Error(t, err)
+	assert.Empty(t, adminRepo.updatedPaymentMethod.ID)
+}
+
+func TestFreezeWalletUC(t *testing.T) {
+	token, err := middleware.GenerateTokenJwt("admin-1", "admin", "ADMIN", 1)
+	assert.NoError(t, err)
+	mockRepo := &mockAdminRepo{}
+	uc := adminUsecase.NewAdminUsecase(mockRepo)
+
+	resp, err := uc.FreezeWalletUC("w1", adminDto.FreezeWalletRequest{Status: ledger.WalletFrozenDebit, Reason: " account takeover "}, "Bearer "+token)
+	assert.NoError(t, err)
+	assert.Equal(t, ledger.WalletFrozenDebit, resp.Status)
+	assert.Equal(t, adminDto.WalletStatusRequest{WalletId: "w1", AdminId: "admin-1", Status: ledger.WalletFrozenDebit, Reason: "account takeover"}, mockRepo.walletStatus)
+
+	resp, err = uc.UnfreezeWalletUC("w1", adminDto.UnfreezeWalletRequest{Reason: "cleared by compliance"}, "Bearer "+token)
+	assert.NoError(t, err)
+	assert.Equal(t, ledger.WalletActive, resp.Status)
+
+	_, err = uc.UnfreezeWalletUC("w1", adminDto.UnfreezeWalletRequest{Reason: "   "}, "Bearer "+token)
+	assert.ErrorIs(t, err, admin.ErrStatusReasonRequired)
+}
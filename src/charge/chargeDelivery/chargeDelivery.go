@@ -0,0 +1,175 @@
+package chargeDelivery
+
+import (
+	"bytes"
+	"errors"
+	"final-project-enigma/model/dto/chargeDto"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/pkg/helper/merchantSignature"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/charge"
+	"final-project-enigma/src/merchant"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+type chargeDelivery struct {
+	chargeUC   charge.ChargeUsecase
+	merchantUC merchant.MerchantUsecase
+}
+
+func NewChargeDelivery(v1Group *gin.RouterGroup, chargeUC charge.ChargeUsecase, merchantUC merchant.MerchantUsecase) {
+	handler := chargeDelivery{
+		chargeUC:   chargeUC,
+		merchantUC: merchantUC,
+	}
+
+	merchantGroup := v1Group.Group("/merchant/charges")
+	{
+		merchantGroup.POST("", handler.signed, handler.create)
+		merchantGroup.GET("/:id", handler.signed, handler.getById)
+	}
+
+	userGroup := v1Group.Group("/user/charges")
+	{
+		userGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getPending)
+		userGroup.POST("/:id/approve", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.approve)
+		userGroup.POST("/:id/decline", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.decline)
+	}
+}
+
+// signed authenticates a merchant server by the HMAC signature of the
+// request instead of a JWT.
+func (c *chargeDelivery) signed(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		json.NewResponseError(ctx, "failed to read request body", "01", "02")
+		ctx.Abort()
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	auth, err := c.merchantUC.AuthenticateUC(
+		ctx.GetHeader(merchantSignature.HeaderApiKey),
+		ctx.GetHeader(merchantSignature.HeaderTimestamp),
+		ctx.GetHeader(merchantSignature.HeaderSignature),
+		ctx.Request.Method,
+		ctx.Request.URL.Path,
+		body,
+	)
+	if err != nil {
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "01")
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set("merchantId", auth.MerchantId)
+	ctx.Set("apiKeyId", auth.ApiKeyId)
+	ctx.Next()
+}
+
+func (c *chargeDelivery) create(ctx *gin.Context) {
+	var req chargeDto.CreateChargeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.MerchantId = ctx.GetString("merchantId")
+	req.ApiKeyId = ctx.GetString("apiKeyId")
+
+	resp, err := c.chargeUC.CreateUC(req)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Charge created", "01", "01")
+}
+
+func (c *chargeDelivery) getById(ctx *gin.Context) {
+	resp, err := c.chargeUC.GetUC(ctx.GetString("merchantId"), ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get charge", "01", "01")
+}
+
+func (c *chargeDelivery) getPending(ctx *gin.Context) {
+	resp, err := c.chargeUC.GetPendingUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get charges", "01", "01")
+}
+
+func (c *chargeDelivery) approve(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req chargeDto.ApproveChargeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.Id = ctx.Param("id")
+
+	resp, err := c.chargeUC.ApproveUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Charge approved", "01", "01")
+}
+
+func (c *chargeDelivery) decline(ctx *gin.Context) {
+	if err := c.chargeUC.DeclineUC(ctx.Param("id"), ctx.GetHeader("Authorization")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Charge declined", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, charge.ErrChargeNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, charge.ErrCustomerNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrInvalidPin):
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrPinLocked):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, limits.ErrPerTransactionLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "05")
+	case errors.Is(err, limits.ErrDailyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "06")
+	case errors.Is(err, limits.ErrMonthlyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "07")
+	case errors.Is(err, limits.ErrMaxBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "08")
+	case errors.Is(err, charge.ErrDuplicateReference),
+		errors.Is(err, charge.ErrChargeNotPending),
+		errors.Is(err, charge.ErrChargeExpired):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseForbidden(ctx, err.Error(), "01", "01")
+	}
+}
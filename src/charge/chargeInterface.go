@@ -0,0 +1,39 @@
+package charge
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/chargeDto"
+	"time"
+)
+
+var (
+	ErrChargeNotFound     = errors.New("charge not found")
+	ErrCustomerNotFound   = errors.New("customer not found")
+	ErrDuplicateReference = errors.New("a charge with this reference already exists")
+	ErrChargeNotPending   = errors.New("charge is no longer pending")
+	ErrChargeExpired      = errors.New("charge has expired")
+)
+
+// CallbackSender posts a signed callback body to a merchant.
+type CallbackSender interface {
+	Send(callbackUrl, keyId, secret string, body []byte) error
+}
+
+type ChargeRepository interface {
+	Create(req chargeDto.CreateChargeRequest) (chargeDto.Charge, error)
+	GetForMerchant(merchantId, id string) (chargeDto.Charge, error)
+	GetPendingForCustomer(customerId string) ([]chargeDto.Charge, error)
+	Approve(req chargeDto.ApproveChargeRequest) (chargeDto.Charge, error)
+	Decline(id, customerId string) error
+	ClaimCallbacks(now time.Time, lease time.Duration, limit int) ([]chargeDto.Callback, error)
+	SaveCallback(chargeId string, result chargeDto.CallbackResult) error
+}
+
+type ChargeUsecase interface {
+	CreateUC(req chargeDto.CreateChargeRequest) (chargeDto.Charge, error)
+	GetUC(merchantId, id string) (chargeDto.Charge, error)
+	GetPendingUC(authHeader string) ([]chargeDto.Charge, error)
+	ApproveUC(req chargeDto.ApproveChargeRequest, authHeader string) (chargeDto.Charge, error)
+	DeclineUC(id, authHeader string) error
+	DeliverCallbacksUC(now time.Time) error
+}
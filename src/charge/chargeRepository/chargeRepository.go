@@ -0,0 +1,275 @@
+package chargeRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/chargeDto"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/dbtx"
+	chargeDomain "final-project-enigma/src/charge"
+	"final-project-enigma/src/user/userRepository"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+type chargeRepository struct {
+	db *sql.DB
+}
+
+func NewChargeRepository(db *sql.DB) chargeDomain.ChargeRepository {
+	return &chargeRepository{
+		db: db,
+	}
+}
+
+const chargeColumns = `
+	c.id, c.merchant_id, m.merchant_name, c.reference, c.amount, COALESCE(c.description, ''),
+	CASE WHEN c.status = 'pending' AND c.expires_at <= $1 THEN 'expired' ELSE c.status END,
+	COALESCE(c.transaction_id::text, ''), COALESCE(c.callback_status, ''), c.expires_at, c.completed_at, c.created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCharge(row rowScanner) (chargeDto.Charge, error) {
+	var c chargeDto.Charge
+	err := row.Scan(&c.Id, &c.MerchantId, &c.MerchantName, &c.Reference, &c.Amount, &c.Description,
+		&c.Status, &c.TransactionId, &c.CallbackStatus, &c.ExpiresAt, &c.CompletedAt, &c.CreatedAt)
+	return c, err
+}
+
+func (repo *chargeRepository) Create(req chargeDto.CreateChargeRequest) (chargeDto.Charge, error) {
+	var customerId string
+	customerQuery := `
+		SELECT id FROM users
+		WHERE phone_number = $1 AND status = 'active' AND deleted_at IS NULL AND roles IN ('USER', 'MERCHANT')
+	`
+	err := repo.db.QueryRow(customerQuery, req.CustomerPhoneNumber).Scan(&customerId)
+	if err == sql.ErrNoRows {
+		log.Error().Msg("customer not found")
+		return chargeDto.Charge{}, chargeDomain.ErrCustomerNotFound
+	}
+	if err != nil {
+		return chargeDto.Charge{}, err
+	}
+
+	var callbackUrl sql.NullString
+	if req.CallbackUrl != "" {
+		callbackUrl = sql.NullString{String: req.CallbackUrl, Valid: true}
+	}
+
+	var id string
+	insertQuery := `
+		INSERT INTO merchant_charges (merchant_id, api_key_id, customer_id, amount, reference, description, callback_url, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	err = repo.db.QueryRow(insertQuery, req.MerchantId, req.ApiKeyId, customerId, req.Amount, req.Reference,
+		req.Description, callbackUrl, req.ExpiresAt, time.Now()).Scan(&id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return chargeDto.Charge{}, chargeDomain.ErrDuplicateReference
+	}
+	if err != nil {
+		log.Error().Msg("failed to create charge: " + err.Error())
+		return chargeDto.Charge{}, fmt.Errorf("failed to create charge: %w", err)
+	}
+
+	return repo.GetForMerchant(req.MerchantId, id)
+}
+
+func (repo *chargeRepository) GetForMerchant(merchantId, id string) (chargeDto.Charge, error) {
+	query := `SELECT ` + chargeColumns + `
+		FROM merchant_charges c
+		JOIN merchant m ON m.id = c.merchant_id
+		WHERE c.id = $2 AND c.merchant_id = $3
+	`
+	charge, err := scanCharge(repo.db.QueryRow(query, time.Now(), id, merchantId))
+	if err == sql.ErrNoRows {
+		return chargeDto.Charge{}, chargeDomain.ErrChargeNotFound
+	}
+	if err != nil {
+		return chargeDto.Charge{}, fmt.Errorf("failed to get charge: %w", err)
+	}
+	return charge, nil
+}
+
+func (repo *chargeRepository) GetPendingForCustomer(customerId string) ([]chargeDto.Charge, error) {
+	query := `SELECT ` + chargeColumns + `
+		FROM merchant_charges c
+		JOIN merchant m ON m.id = c.merchant_id
+		WHERE c.customer_id = $2 AND c.status = 'pending' AND c.expires_at > $1
+		ORDER BY c.created_at DESC
+	`
+	rows, err := repo.db.Query(query, time.Now(), customerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get charges: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []chargeDto.Charge{}
+	for rows.Next() {
+		charge, err := scanCharge(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan charge: %w", err)
+		}
+		resp = append(resp, charge)
+	}
+
+	return resp, rows.Err()
+}
+
+// Approve pays a pending charge from the customer's wallet. The charge row
+// stays locked until the payment commits, so it can only be paid once, and
+// the callback is queued in the same transaction.
+func (repo *chargeRepository) Approve(req chargeDto.ApproveChargeRequest) (chargeDto.Charge, error) {
+	var merchantId string
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var status, reference, description string
+		var expiresAt time.Time
+		var payment userDto.MerchantTransactionRequest
+		lockQuery := `
+			SELECT merchant_id, status, expires_at, amount, reference, COALESCE(description, '')
+			FROM merchant_charges
+			WHERE id = $1 AND customer_id = $2
+			FOR UPDATE
+		`
+		err := tx.QueryRow(lockQuery, req.Id, req.CustomerId).Scan(&merchantId, &status, &expiresAt, &payment.Amount, &reference, &description)
+		if err == sql.ErrNoRows {
+			return chargeDomain.ErrChargeNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock charge: %w", err)
+		}
+		if status != chargeDto.StatusPending {
+			return chargeDomain.ErrChargeNotPending
+		}
+		if !expiresAt.After(time.Now()) {
+			return chargeDomain.ErrChargeExpired
+		}
+
+		payment.UserId = req.CustomerId
+		payment.MerchantId = merchantId
+		payment.Description = "Merchant-Charge"
+		if description != "" {
+			payment.Description = description
+		}
+		resp, err := userRepository.MerchantPayment(tx, payment)
+		if err != nil {
+			return err
+		}
+
+		currentTime := time.Now()
+		updateQuery := `
+			UPDATE merchant_charges
+			SET status = 'completed', transaction_id = $1, completed_at = $2,
+				callback_status = CASE WHEN callback_url IS NULL THEN NULL ELSE 'pending' END,
+				callback_next_at = $2
+			WHERE id = $3
+		`
+		if _, err := tx.Exec(updateQuery, resp.TransactionId, currentTime, req.Id); err != nil {
+			return fmt.Errorf("failed to update charge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Msg("failed to approve charge " + req.Id + ": " + err.Error())
+		return chargeDto.Charge{}, err
+	}
+
+	return repo.GetForMerchant(merchantId, req.Id)
+}
+
+func (repo *chargeRepository) Decline(id, customerId string) error {
+	now := time.Now()
+	query := `
+		UPDATE merchant_charges
+		SET status = 'declined', completed_at = $1,
+			callback_status = CASE WHEN callback_url IS NULL THEN NULL ELSE 'pending' END,
+			callback_next_at = $1
+		WHERE id = $2 AND customer_id = $3 AND status = 'pending' AND expires_at > $1
+	`
+	result, err := repo.db.Exec(query, now, id, customerId)
+	if err != nil {
+		return fmt.Errorf("failed to decline charge: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+
+	var status string
+	var expiresAt time.Time
+	err = repo.db.QueryRow(`SELECT status, expires_at FROM merchant_charges WHERE id = $1 AND customer_id = $2`, id, customerId).Scan(&status, &expiresAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return chargeDomain.ErrChargeNotFound
+	case err != nil:
+		return fmt.Errorf("failed to get charge: %w", err)
+	case status == chargeDto.StatusPending && !expiresAt.After(now):
+		return chargeDomain.ErrChargeExpired
+	default:
+		return chargeDomain.ErrChargeNotPending
+	}
+}
+
+// ClaimCallbacks leases up to limit callbacks that are due. The lease keeps a
+// second worker from posting the same callback while this one is still
+// waiting for the merchant; SaveCallback replaces it.
+func (repo *chargeRepository) ClaimCallbacks(now time.Time, lease time.Duration, limit int) ([]chargeDto.Callback, error) {
+	query := `
+		UPDATE merchant_charges c
+		SET callback_next_at = $2
+		FROM merchant_api_keys k
+		WHERE k.id = c.api_key_id AND c.id IN (
+			SELECT id FROM merchant_charges
+			WHERE callback_status = 'pending' AND callback_next_at <= $1
+			ORDER BY callback_next_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING c.id, c.callback_url, k.key_id, k.secret, c.callback_attempts,
+			c.reference, c.status, c.amount, COALESCE(c.transaction_id::text, ''), c.completed_at
+	`
+	rows, err := repo.db.Query(query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim charge callbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var callbacks []chargeDto.Callback
+	for rows.Next() {
+		var c chargeDto.Callback
+		if err := rows.Scan(&c.ChargeId, &c.Url, &c.KeyId, &c.Secret, &c.Attempts,
+			&c.Body.Reference, &c.Body.Status, &c.Body.Amount, &c.Body.TransactionId, &c.Body.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan charge callback: %w", err)
+		}
+		c.Body.ChargeId = c.ChargeId
+		callbacks = append(callbacks, c)
+	}
+
+	return callbacks, rows.Err()
+}
+
+func (repo *chargeRepository) SaveCallback(chargeId string, result chargeDto.CallbackResult) error {
+	var callbackError sql.NullString
+	if result.Error != "" {
+		callbackError = sql.NullString{String: result.Error, Valid: true}
+		if len(callbackError.String) > 255 {
+			callbackError.String = callbackError.String[:255]
+		}
+	}
+
+	query := `
+		UPDATE merchant_charges
+		SET callback_status = $1, callback_attempts = $2, callback_next_at = $3, callback_error = $4
+		WHERE id = $5
+	`
+	if _, err := repo.db.Exec(query, result.Status, result.Attempts, result.NextAt, callbackError, chargeId); err != nil {
+		return fmt.Errorf("failed to save charge callback: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package chargeUsecase
+
+import (
+	"encoding/json"
+	"final-project-enigma/model/dto/chargeDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/src/charge"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultTTL         = 15 * time.Minute
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = time.Minute
+	callbackLease      = 2 * time.Minute
+	callbackBatch      = 50
+)
+
+type chargeUC struct {
+	chargeRepo     charge.ChargeRepository
+	txAuthorizer   txauth.Authorizer
+	callbackSender charge.CallbackSender
+	ttl            time.Duration
+	maxAttempts    int
+	retryDelay     time.Duration
+}
+
+// NewChargeUsecase reads MERCHANT_CHARGE_TTL, how long a customer has to
+// approve a charge, and MERCHANT_CALLBACK_MAX_ATTEMPTS and
+// MERCHANT_CALLBACK_RETRY_DELAY, which control how a callback the merchant
+// did not accept is retried.
+func NewChargeUsecase(chargeRepo charge.ChargeRepository, txAuthorizer txauth.Authorizer, callbackSender charge.CallbackSender) charge.ChargeUsecase {
+	ttl, err := time.ParseDuration(os.Getenv("MERCHANT_CHARGE_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	maxAttempts, err := strconv.Atoi(os.Getenv("MERCHANT_CALLBACK_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	retryDelay, err := time.ParseDuration(os.Getenv("MERCHANT_CALLBACK_RETRY_DELAY"))
+	if err != nil || retryDelay <= 0 {
+		retryDelay = defaultRetryDelay
+	}
+
+	return &chargeUC{
+		chargeRepo:     chargeRepo,
+		txAuthorizer:   txAuthorizer,
+		callbackSender: callbackSender,
+		ttl:            ttl,
+		maxAttempts:    maxAttempts,
+		retryDelay:     retryDelay,
+	}
+}
+
+func (usecase *chargeUC) CreateUC(req chargeDto.CreateChargeRequest) (chargeDto.Charge, error) {
+	req.ExpiresAt = time.Now().Add(usecase.ttl)
+	return usecase.chargeRepo.Create(req)
+}
+
+func (usecase *chargeUC) GetUC(merchantId, id string) (chargeDto.Charge, error) {
+	return usecase.chargeRepo.GetForMerchant(merchantId, id)
+}
+
+func (usecase *chargeUC) GetPendingUC(authHeader string) ([]chargeDto.Charge, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.chargeRepo.GetPendingForCustomer(userId)
+}
+
+func (usecase *chargeUC) ApproveUC(req chargeDto.ApproveChargeRequest, authHeader string) (chargeDto.Charge, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return chargeDto.Charge{}, err
+	}
+	req.CustomerId = userId
+
+	if err := usecase.txAuthorizer.Authorize(req.CustomerId, req.PIN); err != nil {
+		return chargeDto.Charge{}, err
+	}
+
+	return usecase.chargeRepo.Approve(req)
+}
+
+func (usecase *chargeUC) DeclineUC(id, authHeader string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.chargeRepo.Decline(id, userId)
+}
+
+// DeliverCallbacksUC posts the result of every completed or declined charge
+// to its merchant. It is called by the background worker started from the
+// router. A failed callback is retried with a doubling delay until
+// MERCHANT_CALLBACK_MAX_ATTEMPTS is reached.
+func (usecase *chargeUC) DeliverCallbacksUC(now time.Time) error {
+	callbacks, err := usecase.chargeRepo.ClaimCallbacks(now, callbackLease, callbackBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, callback := range callbacks {
+		result := usecase.deliver(callback, now)
+		if err := usecase.chargeRepo.SaveCallback(callback.ChargeId, result); err != nil {
+			log.Error().Msg("failed to save callback of charge " + callback.ChargeId + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (usecase *chargeUC) deliver(callback chargeDto.Callback, now time.Time) chargeDto.CallbackResult {
+	result := chargeDto.CallbackResult{Attempts: callback.Attempts + 1}
+
+	body, err := json.Marshal(callback.Body)
+	if err == nil {
+		err = usecase.callbackSender.Send(callback.Url, callback.KeyId, callback.Secret, body)
+	}
+	if err == nil {
+		result.Status = chargeDto.CallbackDelivered
+		return result
+	}
+
+	log.Error().Msg("failed to deliver callback of charge " + callback.ChargeId + ": " + err.Error())
+	result.Error = err.Error()
+	if result.Attempts >= usecase.maxAttempts {
+		result.Status = chargeDto.CallbackFailed
+		return result
+	}
+
+	nextAt := now.Add(usecase.retryDelay << (result.Attempts - 1))
+	result.Status = chargeDto.CallbackPending
+	result.NextAt = &nextAt
+	return result
+}
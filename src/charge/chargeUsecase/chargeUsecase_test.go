@@ -0,0 +1,89 @@
+package chargeUsecase_test
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/chargeDto"
+	"final-project-enigma/src/charge"
+	"final-project-enigma/src/charge/chargeUsecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChargeRepo struct {
+	charge.ChargeRepository
+	callbacks []chargeDto.Callback
+	saved     []chargeDto.CallbackResult
+}
+
+func (m *mockChargeRepo) ClaimCallbacks(now time.Time, lease time.Duration, limit int) ([]chargeDto.Callback, error) {
+	return m.callbacks, nil
+}
+
+func (m *mockChargeRepo) SaveCallback(chargeId string, result chargeDto.CallbackResult) error {
+	m.saved = append(m.saved, result)
+	return nil
+}
+
+type mockSender struct {
+	err    error
+	bodies []string
+}
+
+func (m *mockSender) Send(callbackUrl, keyId, secret string, body []byte) error {
+	m.bodies = append(m.bodies, string(body))
+	return m.err
+}
+
+func TestDeliverCallbacksUC(t *testing.T) {
+	t.Setenv("MERCHANT_CALLBACK_MAX_ATTEMPTS", "3")
+	t.Setenv("MERCHANT_CALLBACK_RETRY_DELAY", "1m")
+
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	callback := chargeDto.Callback{
+		ChargeId: "c1",
+		Url:      "https://merchant.example.com/callback",
+		Body:     chargeDto.CallbackBody{ChargeId: "c1", Reference: "INV-1", Status: chargeDto.StatusCompleted},
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		repo := &mockChargeRepo{callbacks: []chargeDto.Callback{callback}}
+		sender := &mockSender{}
+		uc := chargeUsecase.NewChargeUsecase(repo, nil, sender)
+
+		assert.NoError(t, uc.DeliverCallbacksUC(now))
+		assert.Len(t, sender.bodies, 1)
+		assert.Contains(t, sender.bodies[0], `"reference":"INV-1"`)
+		assert.Equal(t, chargeDto.CallbackDelivered, repo.saved[0].Status)
+		assert.Equal(t, 1, repo.saved[0].Attempts)
+		assert.Nil(t, repo.saved[0].NextAt)
+	})
+
+	t.Run("retried with a doubling delay", func(t *testing.T) {
+		retried := callback
+		retried.Attempts = 1
+		repo := &mockChargeRepo{callbacks: []chargeDto.Callback{retried}}
+		uc := chargeUsecase.NewChargeUsecase(repo, nil, &mockSender{err: errors.New("status 500")})
+
+		assert.NoError(t, uc.DeliverCallbacksUC(now))
+		result := repo.saved[0]
+		assert.Equal(t, chargeDto.CallbackPending, result.Status)
+		assert.Equal(t, 2, result.Attempts)
+		assert.Equal(t, now.Add(2*time.Minute), *result.NextAt)
+		assert.Equal(t, "status 500", result.Error)
+	})
+
+	t.Run("failed after the last attempt", func(t *testing.T) {
+		exhausted := callback
+		exhausted.Attempts = 2
+		repo := &mockChargeRepo{callbacks: []chargeDto.Callback{exhausted}}
+		uc := chargeUsecase.NewChargeUsecase(repo, nil, &mockSender{err: errors.New("status 500")})
+
+		assert.NoError(t, uc.DeliverCallbacksUC(now))
+		result := repo.saved[0]
+		assert.Equal(t, chargeDto.CallbackFailed, result.Status)
+		assert.Equal(t, 3, result.Attempts)
+		assert.Nil(t, result.NextAt)
+	})
+}
@@ -0,0 +1,144 @@
+package fxDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/fxDto"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/pkg/exchangeRate"
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/fx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fxDelivery struct {
+	fxUC        fx.FxUsecase
+	idempotency gin.HandlerFunc
+}
+
+func NewFxDelivery(v1Group *gin.RouterGroup, fxUC fx.FxUsecase, idempotencyStore idempotency.Store) {
+	handler := fxDelivery{
+		fxUC:        fxUC,
+		idempotency: middleware.Idempotency(idempotencyStore, middleware.IdempotencyRetention()),
+	}
+
+	quoteGroup := v1Group.Group("/user/fx/quotes")
+	{
+		quoteGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.quote)
+		quoteGroup.GET("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getQuote)
+		quoteGroup.POST("/:id/convert", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.convert)
+	}
+
+	rateGroup := v1Group.Group("/admin/fx-rates")
+	{
+		rateGroup.GET("", middleware.JwtAuthWithRoles("ADMIN"), handler.getRates)
+		rateGroup.PUT("", middleware.JwtAuthWithRoles("ADMIN"), handler.setRate)
+	}
+}
+
+func (f *fxDelivery) quote(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req fxDto.QuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := f.fxUC.QuoteUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Rate locked", "01", "01")
+}
+
+func (f *fxDelivery) getQuote(ctx *gin.Context) {
+	resp, err := f.fxUC.GetQuoteUC(ctx.Param("id"), ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get quote", "01", "01")
+}
+
+func (f *fxDelivery) convert(ctx *gin.Context) {
+	resp, err := f.fxUC.ConvertUC(ctx.Param("id"), ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Conversion success", "01", "01")
+}
+
+func (f *fxDelivery) getRates(ctx *gin.Context) {
+	resp, err := f.fxUC.GetRatesUC()
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get exchange rates", "01", "01")
+}
+
+func (f *fxDelivery) setRate(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req fxDto.SetRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := f.fxUC.SetRateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Exchange rate saved", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, fx.ErrSameCurrency):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "toPocketId", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, fx.ErrAmountTooSmall):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, fx.ErrInvalidPair):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "quoteCurrency", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, exchangeRate.ErrInvalidRate):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "rate", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, fx.ErrPocketNotFound), errors.Is(err, fx.ErrQuoteNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, exchangeRate.ErrRateNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, fx.ErrQuoteExpired):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, limits.ErrMaxBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "05")
+	case errors.Is(err, ledger.ErrInsufficientBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "08")
+	case errors.Is(err, ledger.ErrWalletFrozen):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "10")
+	case errors.Is(err, fx.ErrQuoteUsed):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
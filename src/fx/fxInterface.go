@@ -0,0 +1,33 @@
+package fx
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/fxDto"
+)
+
+var (
+	ErrPocketNotFound = errors.New("pocket not found")
+	ErrSameCurrency   = errors.New("both pockets hold the same currency; use a pocket transfer instead")
+	ErrInvalidPair    = errors.New("base and quote currency must be different")
+	ErrAmountTooSmall = errors.New("amount is too small to convert")
+	ErrQuoteNotFound  = errors.New("quote not found")
+	ErrQuoteExpired   = errors.New("quote has expired")
+	ErrQuoteUsed      = errors.New("quote was already converted")
+)
+
+type FxRepository interface {
+	GetPocketCurrencies(userId string, ids ...string) (map[string]string, error)
+	CreateQuote(userId string, quote fxDto.Quote) (fxDto.Quote, error)
+	GetQuote(userId, id string) (fxDto.Quote, error)
+	Convert(userId, id string) (fxDto.Quote, error)
+	GetRates() ([]fxDto.Rate, error)
+	SetRate(req fxDto.SetRateRequest) (fxDto.Rate, error)
+}
+
+type FxUsecase interface {
+	QuoteUC(req fxDto.QuoteRequest, authHeader string) (fxDto.Quote, error)
+	GetQuoteUC(id, authHeader string) (fxDto.Quote, error)
+	ConvertUC(id, authHeader string) (fxDto.Quote, error)
+	GetRatesUC() ([]fxDto.Rate, error)
+	SetRateUC(req fxDto.SetRateRequest, authHeader string) (fxDto.Rate, error)
+}
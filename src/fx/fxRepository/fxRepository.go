@@ -0,0 +1,202 @@
+package fxRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/fxDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
+	fxDomain "final-project-enigma/src/fx"
+	pocketDomain "final-project-enigma/src/pocket"
+	"final-project-enigma/src/pocket/pocketRepository"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type fxRepository struct {
+	db *sql.DB
+}
+
+func NewFxRepository(db *sql.DB) fxDomain.FxRepository {
+	return &fxRepository{
+		db: db,
+	}
+}
+
+const quoteColumns = `
+	id, from_wallet_id, to_wallet_id, from_currency, to_currency, rate, amount, converted_amount,
+	provider, expires_at, COALESCE(transaction_id::text, ''), created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQuote(row rowScanner) (fxDto.Quote, error) {
+	var q fxDto.Quote
+	err := row.Scan(&q.Id, &q.FromPocketId, &q.ToPocketId, &q.FromCurrency, &q.ToCurrency, &q.Rate, &q.Amount,
+		&q.ConvertedAmount, &q.Provider, &q.ExpiresAt, &q.TransactionId, &q.CreatedAt)
+	return q, err
+}
+
+func (repo *fxRepository) GetPocketCurrencies(userId string, ids ...string) (map[string]string, error) {
+	currencies, err := pocketRepository.PocketCurrencies(repo.db, userId, ids...)
+	if errors.Is(err, pocketDomain.ErrPocketNotFound) {
+		return nil, fxDomain.ErrPocketNotFound
+	}
+	return currencies, err
+}
+
+func (repo *fxRepository) CreateQuote(userId string, quote fxDto.Quote) (fxDto.Quote, error) {
+	query := `
+		INSERT INTO fx_quotes (user_id, from_wallet_id, to_wallet_id, from_currency, to_currency, rate, amount,
+			converted_amount, provider, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+	err := repo.db.QueryRow(query, userId, quote.FromPocketId, quote.ToPocketId, quote.FromCurrency, quote.ToCurrency,
+		quote.Rate, quote.Amount, quote.ConvertedAmount, quote.Provider, quote.ExpiresAt, time.Now()).Scan(&quote.Id, &quote.CreatedAt)
+	if err != nil {
+		log.Error().Msg("failed to create fx quote: " + err.Error())
+		return fxDto.Quote{}, fmt.Errorf("failed to create fx quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+func (repo *fxRepository) GetQuote(userId, id string) (fxDto.Quote, error) {
+	query := `SELECT ` + quoteColumns + ` FROM fx_quotes WHERE id::text = $1 AND user_id = $2`
+	quote, err := scanQuote(repo.db.QueryRow(query, id, userId))
+	if err == sql.ErrNoRows {
+		return fxDto.Quote{}, fxDomain.ErrQuoteNotFound
+	}
+	if err != nil {
+		return fxDto.Quote{}, fmt.Errorf("failed to get fx quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// Convert carries out a quote at its locked rate. The source side of the
+// conversion is cleared through the fx account of its currency and the
+// destination side through the fx account of the other one, so the ledger
+// balances within each currency. The quote row stays locked until the
+// conversion commits, so a quote converts at most once.
+func (repo *fxRepository) Convert(userId, id string) (fxDto.Quote, error) {
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		lockQuery := `SELECT ` + quoteColumns + ` FROM fx_quotes WHERE id::text = $1 AND user_id = $2 FOR UPDATE`
+		quote, err := scanQuote(tx.QueryRow(lockQuery, id, userId))
+		if err == sql.ErrNoRows {
+			return fxDomain.ErrQuoteNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock fx quote: %w", err)
+		}
+		if quote.TransactionId != "" {
+			return fxDomain.ErrQuoteUsed
+		}
+		if !quote.ExpiresAt.After(time.Now()) {
+			return fxDomain.ErrQuoteExpired
+		}
+
+		balances, err := ledger.LockWallets(tx, quote.FromPocketId, quote.ToPocketId)
+		if errors.Is(err, ledger.ErrWalletNotFound) {
+			return fxDomain.ErrPocketNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if balances[quote.FromPocketId] < quote.Amount {
+			log.Error().Msg("insufficient balance")
+			return ledger.ErrInsufficientBalance
+		}
+		if err := limits.CheckBalance(tx, quote.ToPocketId, quote.ConvertedAmount); err != nil {
+			return err
+		}
+
+		currentTime := time.Now()
+		var transactionId string
+		transactionQuery := `
+			INSERT INTO transactions (user_id, transaction_type, currency, amount, fee, description, created_at, status)
+			VALUES ($1, 'debit', $2, $3, 0, 'Currency Conversion', $4, 'success')
+			RETURNING id
+		`
+		if err := tx.QueryRow(transactionQuery, userId, quote.FromCurrency, quote.Amount, currentTime).Scan(&transactionId); err != nil {
+			return err
+		}
+
+		// Both pockets are the user's own, so transfer limits leave the
+		// conversion out.
+		walletTransactionQuery := `
+			INSERT INTO wallet_transactions (transaction_id, from_wallet_id, to_wallet_id, created_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		if _, err := tx.Exec(walletTransactionQuery, transactionId, quote.FromPocketId, quote.ToPocketId, currentTime); err != nil {
+			return err
+		}
+
+		err = ledger.Post(tx, transactionId,
+			ledger.Debit(ledger.AccountWallet, quote.FromPocketId, quote.Amount),
+			ledger.Credit(ledger.AccountFX, quote.FromCurrency, quote.Amount),
+			ledger.Debit(ledger.AccountFX, quote.ToCurrency, quote.ConvertedAmount),
+			ledger.Credit(ledger.AccountWallet, quote.ToPocketId, quote.ConvertedAmount),
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE fx_quotes SET transaction_id = $1 WHERE id = $2`, transactionId, quote.Id)
+		return err
+	})
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+
+	return repo.GetQuote(userId, id)
+}
+
+func (repo *fxRepository) GetRates() ([]fxDto.Rate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, updated_at
+		FROM fx_rates
+		ORDER BY base_currency, quote_currency
+	`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fx rates: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []fxDto.Rate{}
+	for rows.Next() {
+		var rate fxDto.Rate
+		if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fx rate: %w", err)
+		}
+		resp = append(resp, rate)
+	}
+
+	return resp, rows.Err()
+}
+
+// SetRate adds the pair or replaces its rate.
+func (repo *fxRepository) SetRate(req fxDto.SetRateRequest) (fxDto.Rate, error) {
+	resp := fxDto.Rate{BaseCurrency: req.BaseCurrency, QuoteCurrency: req.QuoteCurrency, Rate: req.Rate}
+	query := `
+		INSERT INTO fx_rates (base_currency, quote_currency, rate, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err := repo.db.QueryRow(query, req.BaseCurrency, req.QuoteCurrency, req.Rate, req.UpdatedBy, time.Now()).Scan(&resp.UpdatedAt)
+	if err != nil {
+		log.Error().Msg("failed to set fx rate: " + err.Error())
+		return fxDto.Rate{}, fmt.Errorf("failed to set fx rate: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package fxUsecase
+
+import (
+	"final-project-enigma/model/dto/fxDto"
+	"final-project-enigma/pkg/exchangeRate"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/fx"
+	"os"
+	"time"
+)
+
+const defaultQuoteTTL = time.Minute
+
+type fxUC struct {
+	fxRepo   fx.FxRepository
+	provider exchangeRate.Provider
+	ttl      time.Duration
+}
+
+func NewFxUsecase(fxRepo fx.FxRepository, provider exchangeRate.Provider) fx.FxUsecase {
+	return &fxUC{
+		fxRepo:   fxRepo,
+		provider: provider,
+		ttl:      QuoteTTL(),
+	}
+}
+
+// QuoteTTL reads FX_QUOTE_TTL, how long a quoted rate stays locked.
+func QuoteTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("FX_QUOTE_TTL"))
+	if err != nil || ttl <= 0 {
+		return defaultQuoteTTL
+	}
+	return ttl
+}
+
+// QuoteUC prices a conversion between two of the user's pockets in different
+// currencies and locks the rate for the quote TTL.
+func (usecase *fxUC) QuoteUC(req fxDto.QuoteRequest, authHeader string) (fxDto.Quote, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+
+	currencies, err := usecase.fxRepo.GetPocketCurrencies(userId, req.FromPocketId, req.ToPocketId)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+	from, to := currencies[req.FromPocketId], currencies[req.ToPocketId]
+	if from == to {
+		return fxDto.Quote{}, fx.ErrSameCurrency
+	}
+
+	rate, err := usecase.provider.Rate(from, to)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+	converted, err := exchangeRate.Convert(req.Amount, from, to, rate)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+	if !converted.IsPositive() {
+		return fxDto.Quote{}, fx.ErrAmountTooSmall
+	}
+
+	return usecase.fxRepo.CreateQuote(userId, fxDto.Quote{
+		FromPocketId:    req.FromPocketId,
+		ToPocketId:      req.ToPocketId,
+		FromCurrency:    from,
+		ToCurrency:      to,
+		Rate:            exchangeRate.FormatRate(rate),
+		Amount:          req.Amount,
+		ConvertedAmount: converted,
+		Provider:        usecase.provider.Name(),
+		ExpiresAt:       time.Now().Add(usecase.ttl),
+	})
+}
+
+func (usecase *fxUC) GetQuoteUC(id, authHeader string) (fxDto.Quote, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+	return usecase.fxRepo.GetQuote(userId, id)
+}
+
+func (usecase *fxUC) ConvertUC(id, authHeader string) (fxDto.Quote, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return fxDto.Quote{}, err
+	}
+	return usecase.fxRepo.Convert(userId, id)
+}
+
+func (usecase *fxUC) GetRatesUC() ([]fxDto.Rate, error) {
+	return usecase.fxRepo.GetRates()
+}
+
+func (usecase *fxUC) SetRateUC(req fxDto.SetRateRequest, authHeader string) (fxDto.Rate, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return fxDto.Rate{}, err
+	}
+	if req.BaseCurrency == req.QuoteCurrency {
+		return fxDto.Rate{}, fx.ErrInvalidPair
+	}
+
+	rate, err := exchangeRate.ParseRate(req.Rate)
+	if err != nil {
+		return fxDto.Rate{}, err
+	}
+	req.Rate = exchangeRate.FormatRate(rate)
+	req.UpdatedBy = adminId
+
+	return usecase.fxRepo.SetRate(req)
+}
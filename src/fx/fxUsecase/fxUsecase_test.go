@@ -0,0 +1,93 @@
+package fxUsecase_test
+
+import (
+	"final-project-enigma/model/dto/fxDto"
+	"final-project-enigma/pkg/exchangeRate"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/fx"
+	"final-project-enigma/src/fx/fxUsecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFxRepo struct {
+	fx.FxRepository
+	currencies map[string]string
+	quote      fxDto.Quote
+	rate       fxDto.SetRateRequest
+}
+
+func (m *mockFxRepo) GetPocketCurrencies(userId string, ids ...string) (map[string]string, error) {
+	for _, id := range ids {
+		if _, ok := m.currencies[id]; !ok {
+			return nil, fx.ErrPocketNotFound
+		}
+	}
+	return m.currencies, nil
+}
+
+func (m *mockFxRepo) CreateQuote(userId string, quote fxDto.Quote) (fxDto.Quote, error) {
+	quote.Id = "q1"
+	m.quote = quote
+	return quote, nil
+}
+
+func (m *mockFxRepo) SetRate(req fxDto.SetRateRequest) (fxDto.Rate, error) {
+	m.rate = req
+	return fxDto.Rate{BaseCurrency: req.BaseCurrency, QuoteCurrency: req.QuoteCurrency, Rate: req.Rate}, nil
+}
+
+func authHeader(t *testing.T, role string) string {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", role, 1)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestQuoteUC(t *testing.T) {
+	repo := &mockFxRepo{currencies: map[string]string{"idr": "IDR", "usd": "USD", "idr2": "IDR"}}
+	uc := fxUsecase.NewFxUsecase(repo, exchangeRate.NewFake())
+
+	t.Run("locks the converted amount", func(t *testing.T) {
+		quote, err := uc.QuoteUC(fxDto.QuoteRequest{FromPocketId: "usd", ToPocketId: "idr", Amount: money.Money(1250)}, authHeader(t, "USER"))
+		assert.NoError(t, err)
+		assert.Equal(t, "q1", quote.Id)
+		assert.Equal(t, money.Money(200000), quote.ConvertedAmount)
+		assert.Equal(t, "16000.0000000000", quote.Rate)
+		assert.Equal(t, exchangeRate.ProviderFake, quote.Provider)
+		assert.True(t, quote.ExpiresAt.After(time.Now()))
+	})
+
+	t.Run("same currency", func(t *testing.T) {
+		_, err := uc.QuoteUC(fxDto.QuoteRequest{FromPocketId: "idr", ToPocketId: "idr2", Amount: money.Money(1000)}, authHeader(t, "USER"))
+		assert.ErrorIs(t, err, fx.ErrSameCurrency)
+	})
+
+	t.Run("too small to convert", func(t *testing.T) {
+		_, err := uc.QuoteUC(fxDto.QuoteRequest{FromPocketId: "idr", ToPocketId: "usd", Amount: money.Money(100)}, authHeader(t, "USER"))
+		assert.ErrorIs(t, err, fx.ErrAmountTooSmall)
+	})
+
+	t.Run("unknown pocket", func(t *testing.T) {
+		_, err := uc.QuoteUC(fxDto.QuoteRequest{FromPocketId: "idr", ToPocketId: "sgd", Amount: money.Money(1000)}, authHeader(t, "USER"))
+		assert.ErrorIs(t, err, fx.ErrPocketNotFound)
+	})
+}
+
+func TestSetRateUC(t *testing.T) {
+	repo := &mockFxRepo{}
+	uc := fxUsecase.NewFxUsecase(repo, exchangeRate.NewFake())
+
+	resp, err := uc.SetRateUC(fxDto.SetRateRequest{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "16250.5"}, authHeader(t, "ADMIN"))
+	assert.NoError(t, err)
+	assert.Equal(t, "16250.5000000000", resp.Rate)
+	assert.Equal(t, "user-1", repo.rate.UpdatedBy)
+
+	_, err = uc.SetRateUC(fxDto.SetRateRequest{BaseCurrency: "USD", QuoteCurrency: "USD", Rate: "1"}, authHeader(t, "ADMIN"))
+	assert.ErrorIs(t, err, fx.ErrInvalidPair)
+
+	_, err = uc.SetRateUC(fxDto.SetRateRequest{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "-1"}, authHeader(t, "ADMIN"))
+	assert.ErrorIs(t, err, exchangeRate.ErrInvalidRate)
+}
@@ -0,0 +1,77 @@
+package ledgerDelivery
+
+import (
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/ledgerDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/ledger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ledgerDelivery struct {
+	ledgerUC ledger.LedgerUsecase
+}
+
+func NewLedgerDelivery(v1Group *gin.RouterGroup, ledgerUC ledger.LedgerUsecase) {
+	handler := ledgerDelivery{
+		ledgerUC: ledgerUC,
+	}
+
+	ledgerGroup := v1Group.Group("/admin/ledger")
+	{
+		ledgerGroup.GET("/entries", middleware.JwtAuthWithRoles("ADMIN"), handler.getEntries)
+		ledgerGroup.GET("/trial-balance", middleware.JwtAuthWithRoles("ADMIN"), handler.getTrialBalance)
+		ledgerGroup.GET("/wallet/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.checkWalletBalance)
+		ledgerGroup.POST("/wallet/:id/rebuild", middleware.JwtAuthWithRoles("ADMIN"), handler.rebuildWalletBalance)
+	}
+}
+
+func (l *ledgerDelivery) getEntries(ctx *gin.Context) {
+	var params ledgerDto.GetLedgerEntriesParams
+	params.TransactionId = ctx.Query("trxId")
+	params.AccountType = ctx.Query("accountType")
+	params.AccountId = ctx.Query("accountId")
+	params.DateStart = ctx.Query("dateStart")
+	params.DateEnd = ctx.Query("dateEnd")
+	params.Page = ctx.Query("page")
+	params.Limit = ctx.Query("size")
+
+	resp, totalData, err := l.ledgerUC.GetEntriesUC(params)
+	if err != nil {
+		json.NewResponseForbidden(ctx, "No ledger record", "01", "02")
+		return
+	}
+
+	json.NewResponSuccesPaging(ctx, resp, "Success get ledger entries", "01", "01", params.Page, totalData)
+}
+
+func (l *ledgerDelivery) getTrialBalance(ctx *gin.Context) {
+	resp, err := l.ledgerUC.GetTrialBalanceUC()
+	if err != nil {
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get trial balance", "01", "01")
+}
+
+func (l *ledgerDelivery) checkWalletBalance(ctx *gin.Context) {
+	resp, err := l.ledgerUC.CheckWalletBalanceUC(ctx.Param("id"))
+	if err != nil {
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success check wallet balance", "01", "01")
+}
+
+func (l *ledgerDelivery) rebuildWalletBalance(ctx *gin.Context) {
+	resp, err := l.ledgerUC.RebuildWalletBalanceUC(ctx.Param("id"))
+	if err != nil {
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success rebuild wallet balance", "01", "01")
+}
@@ -0,0 +1,17 @@
+package ledger
+
+import "final-project-enigma/model/dto/ledgerDto"
+
+type LedgerRepository interface {
+	GetEntries(params ledgerDto.GetLedgerEntriesParams) ([]ledgerDto.LedgerEntry, int, error)
+	CheckWalletBalance(walletId string) (ledgerDto.WalletBalanceCheck, error)
+	RebuildWalletBalance(walletId string) (ledgerDto.WalletBalanceCheck, error)
+	GetTrialBalance() ([]ledgerDto.TrialBalance, error)
+}
+
+type LedgerUsecase interface {
+	GetEntriesUC(params ledgerDto.GetLedgerEntriesParams) ([]ledgerDto.LedgerEntry, string, error)
+	CheckWalletBalanceUC(walletId string) (ledgerDto.WalletBalanceCheck, error)
+	RebuildWalletBalanceUC(walletId string) (ledgerDto.WalletBalanceCheck, error)
+	GetTrialBalanceUC() ([]ledgerDto.TrialBalance, error)
+}
@@ -0,0 +1,182 @@
+package ledgerRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/ledgerDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/ledger"
+	ledgerDomain "final-project-enigma/src/ledger"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ledgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) ledgerDomain.LedgerRepository {
+	return &ledgerRepository{
+		db: db,
+	}
+}
+
+const walletLedgerBalanceQuery = `
+	SELECT COALESCE(SUM(CASE WHEN entry_type = 'credit' THEN amount ELSE -amount END), 0)
+	FROM ledger_entries
+	WHERE account_type = 'wallet' AND account_id = $1
+`
+
+func (repo *ledgerRepository) GetEntries(params ledgerDto.GetLedgerEntriesParams) ([]ledgerDto.LedgerEntry, int, error) {
+	baseQuery := `
+		SELECT id, transaction_id, account_type, account_id, entry_type, amount, balance_after, created_at
+		FROM ledger_entries
+		WHERE 1=1
+	`
+
+	args := []interface{}{}
+	conditionIndex := 1
+
+	addCondition := func(condition string, value interface{}) {
+		baseQuery += fmt.Sprintf(" AND %s $%d", condition, conditionIndex)
+		args = append(args, value)
+		conditionIndex++
+	}
+
+	if params.TransactionId != "" {
+		addCondition("transaction_id =", params.TransactionId)
+	}
+	if params.AccountType != "" {
+		addCondition("account_type =", params.AccountType)
+	}
+	if params.AccountId != "" {
+		addCondition("account_id =", params.AccountId)
+	}
+	if params.DateStart != "" {
+		addCondition("created_at >=", params.DateStart)
+	}
+	if params.DateEnd != "" {
+		addCondition("created_at <=", params.DateEnd+" 23:59:59.999999")
+	}
+
+	countQuery := "SELECT COUNT(*) FROM (" + baseQuery + ") sub"
+
+	finalQuery := baseQuery + " ORDER BY created_at, id"
+	if params.Page != "" && params.Limit != "" {
+		page, _ := strconv.Atoi(params.Page)
+		limit, _ := strconv.Atoi(params.Limit)
+		offset := (page - 1) * limit
+		finalQuery += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	var totalData int
+	if err := repo.db.QueryRow(countQuery, args...).Scan(&totalData); err != nil {
+		return nil, 0, fmt.Errorf("failed to get total data count: %w", err)
+	}
+
+	rows, err := repo.db.Query(finalQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get data from db: %w", err)
+	}
+	defer rows.Close()
+
+	var resp []ledgerDto.LedgerEntry
+	for rows.Next() {
+		var entry ledgerDto.LedgerEntry
+		var balanceAfter sql.NullString
+		if err := rows.Scan(&entry.Id, &entry.TransactionId, &entry.AccountType, &entry.AccountId, &entry.EntryType, &entry.Amount, &balanceAfter, &entry.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		if balanceAfter.Valid {
+			entry.BalanceAfter = balanceAfter.String
+		}
+		resp = append(resp, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate over ledger rows: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, 0, errors.New("ledger entry not found")
+	}
+
+	return resp, totalData, nil
+}
+
+func (repo *ledgerRepository) CheckWalletBalance(walletId string) (resp ledgerDto.WalletBalanceCheck, err error) {
+	query := `
+		SELECT w.id, w.balance, l.total, w.balance = l.total
+		FROM wallets w, (` + walletLedgerBalanceQuery + `) AS l(total)
+		WHERE w.id = $1
+	`
+	err = repo.db.QueryRow(query, walletId).Scan(&resp.WalletId, &resp.CachedBalance, &resp.LedgerBalance, &resp.Consistent)
+	if err == sql.ErrNoRows {
+		log.Error().Msg("wallet not found")
+		return resp, ledger.ErrWalletNotFound
+	}
+	if err != nil {
+		return resp, fmt.Errorf("failed to check wallet balance: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (repo *ledgerRepository) RebuildWalletBalance(walletId string) (resp ledgerDto.WalletBalanceCheck, err error) {
+	err = dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		balances, err := ledger.LockWallets(tx, walletId)
+		if err != nil {
+			return err
+		}
+
+		rebuildQuery := `
+			UPDATE wallets
+			SET balance = (` + walletLedgerBalanceQuery + `), updated_at = $2
+			WHERE id = $1
+			RETURNING balance
+		`
+		var ledgerBalance string
+		if err := tx.QueryRow(rebuildQuery, walletId, time.Now()).Scan(&ledgerBalance); err != nil {
+			return fmt.Errorf("failed to rebuild wallet balance: %w", err)
+		}
+
+		resp.WalletId = walletId
+		resp.CachedBalance = balances[walletId].String()
+		resp.LedgerBalance = ledgerBalance
+		resp.Consistent = true
+		return nil
+	})
+
+	return resp, err
+}
+
+func (repo *ledgerRepository) GetTrialBalance() ([]ledgerDto.TrialBalance, error) {
+	query := `
+		SELECT
+			account_type,
+			COALESCE(SUM(CASE WHEN entry_type = 'debit' THEN amount END), 0),
+			COALESCE(SUM(CASE WHEN entry_type = 'credit' THEN amount END), 0)
+		FROM ledger_entries
+		GROUP BY account_type
+		ORDER BY account_type
+	`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trial balance: %w", err)
+	}
+	defer rows.Close()
+
+	var resp []ledgerDto.TrialBalance
+	for rows.Next() {
+		var row ledgerDto.TrialBalance
+		if err := rows.Scan(&row.AccountType, &row.TotalDebit, &row.TotalCredit); err != nil {
+			return nil, fmt.Errorf("failed to scan trial balance: %w", err)
+		}
+		resp = append(resp, row)
+	}
+
+	return resp, rows.Err()
+}
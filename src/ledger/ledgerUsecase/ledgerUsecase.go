@@ -0,0 +1,36 @@
+package ledgerUsecase
+
+import (
+	"final-project-enigma/model/dto/ledgerDto"
+	"final-project-enigma/src/ledger"
+	"strconv"
+)
+
+type ledgerUC struct {
+	ledgerRepo ledger.LedgerRepository
+}
+
+func NewLedgerUsecase(ledgerRepo ledger.LedgerRepository) ledger.LedgerUsecase {
+	return &ledgerUC{ledgerRepo}
+}
+
+func (usecase *ledgerUC) GetEntriesUC(params ledgerDto.GetLedgerEntriesParams) ([]ledgerDto.LedgerEntry, string, error) {
+	resp, totalData, err := usecase.ledgerRepo.GetEntries(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp, strconv.Itoa(totalData), nil
+}
+
+func (usecase *ledgerUC) CheckWalletBalanceUC(walletId string) (ledgerDto.WalletBalanceCheck, error) {
+	return usecase.ledgerRepo.CheckWalletBalance(walletId)
+}
+
+func (usecase *ledgerUC) RebuildWalletBalanceUC(walletId string) (ledgerDto.WalletBalanceCheck, error) {
+	return usecase.ledgerRepo.RebuildWalletBalance(walletId)
+}
+
+func (usecase *ledgerUC) GetTrialBalanceUC() ([]ledgerDto.TrialBalance, error) {
+	return usecase.ledgerRepo.GetTrialBalance()
+}
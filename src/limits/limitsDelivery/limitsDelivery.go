@@ -0,0 +1,86 @@
+package limitsDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/limitsDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/limits"
+
+	"github.com/gin-gonic/gin"
+)
+
+type limitsDelivery struct {
+	limitsUC limits.LimitsUsecase
+}
+
+func NewLimitsDelivery(v1Group *gin.RouterGroup, limitsUC limits.LimitsUsecase) {
+	handler := limitsDelivery{
+		limitsUC: limitsUC,
+	}
+
+	limitsGroup := v1Group.Group("/admin/limits")
+	{
+		limitsGroup.GET("", middleware.JwtAuthWithRoles("ADMIN"), handler.getTiers)
+		limitsGroup.PUT("/:tier", middleware.JwtAuthWithRoles("ADMIN"), handler.upsertTier)
+		limitsGroup.PUT("/users/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.updateUserTier)
+	}
+}
+
+func (l *limitsDelivery) getTiers(ctx *gin.Context) {
+	resp, err := l.limitsUC.GetTiersUC()
+	if err != nil {
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get limits", "01", "01")
+}
+
+func (l *limitsDelivery) upsertTier(ctx *gin.Context) {
+	var req limitsDto.UpdateTierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.Tier = ctx.Param("tier")
+
+	resp, err := l.limitsUC.UpsertTierUC(req)
+	if err != nil {
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success update limits", "01", "01")
+}
+
+func (l *limitsDelivery) updateUserTier(ctx *gin.Context) {
+	var req limitsDto.UpdateUserTierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.UserId = ctx.Param("id")
+
+	if err := l.limitsUC.UpdateUserTierUC(req); err != nil {
+		if errors.Is(err, limits.ErrTierNotFound) || errors.Is(err, limits.ErrUserNotFound) {
+			json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+			return
+		}
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+
+	json.NewResponSucces(ctx, req, "Success update user tier", "01", "01")
+}
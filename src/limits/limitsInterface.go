@@ -0,0 +1,23 @@
+package limits
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/limitsDto"
+)
+
+var (
+	ErrTierNotFound = errors.New("account tier not found")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type LimitsRepository interface {
+	GetTiers() ([]limitsDto.TierLimits, error)
+	UpsertTier(req limitsDto.UpdateTierRequest) error
+	UpdateUserTier(req limitsDto.UpdateUserTierRequest) error
+}
+
+type LimitsUsecase interface {
+	GetTiersUC() ([]limitsDto.TierLimits, error)
+	UpsertTierUC(req limitsDto.UpdateTierRequest) (limitsDto.TierLimits, error)
+	UpdateUserTierUC(req limitsDto.UpdateUserTierRequest) error
+}
@@ -0,0 +1,135 @@
+package limitsRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/limitsDto"
+	limitsDomain "final-project-enigma/src/limits"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type limitsRepository struct {
+	db *sql.DB
+}
+
+func NewLimitsRepository(db *sql.DB) limitsDomain.LimitsRepository {
+	return &limitsRepository{
+		db: db,
+	}
+}
+
+func (repo *limitsRepository) GetTiers() ([]limitsDto.TierLimits, error) {
+	rows, err := repo.db.Query(`SELECT name, max_balance FROM account_tiers ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account tiers: %w", err)
+	}
+	defer rows.Close()
+
+	var tiers []limitsDto.TierLimits
+	index := map[string]int{}
+	for rows.Next() {
+		var tier limitsDto.TierLimits
+		if err := rows.Scan(&tier.Tier, &tier.MaxBalance); err != nil {
+			return nil, fmt.Errorf("failed to scan account tier: %w", err)
+		}
+		tier.Limits = []limitsDto.TransactionLimit{}
+		index[tier.Tier] = len(tiers)
+		tiers = append(tiers, tier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	limitQuery := `
+		SELECT tier, transaction_type, per_transaction, daily, monthly
+		FROM transaction_limits
+		ORDER BY tier, transaction_type
+	`
+	limitRows, err := repo.db.Query(limitQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction limits: %w", err)
+	}
+	defer limitRows.Close()
+
+	for limitRows.Next() {
+		var tier string
+		var limit limitsDto.TransactionLimit
+		if err := limitRows.Scan(&tier, &limit.TransactionType, &limit.PerTransaction, &limit.Daily, &limit.Monthly); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction limit: %w", err)
+		}
+		if i, ok := index[tier]; ok {
+			tiers[i].Limits = append(tiers[i].Limits, limit)
+		}
+	}
+
+	return tiers, limitRows.Err()
+}
+
+func (repo *limitsRepository) UpsertTier(req limitsDto.UpdateTierRequest) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	currentTime := time.Now()
+	tierQuery := `
+		INSERT INTO account_tiers (name, max_balance, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (name) DO UPDATE SET max_balance = EXCLUDED.max_balance, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.Exec(tierQuery, req.Tier, req.MaxBalance, currentTime); err != nil {
+		tx.Rollback()
+		log.Error().Msg("failed to save account tier: " + err.Error())
+		return fmt.Errorf("failed to save account tier: %w", err)
+	}
+
+	limitQuery := `
+		INSERT INTO transaction_limits (tier, transaction_type, per_transaction, daily, monthly, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (tier, transaction_type) DO UPDATE
+		SET per_transaction = EXCLUDED.per_transaction,
+			daily = EXCLUDED.daily,
+			monthly = EXCLUDED.monthly,
+			updated_at = EXCLUDED.updated_at
+	`
+	for _, limit := range req.Limits {
+		_, err := tx.Exec(limitQuery, req.Tier, limit.TransactionType, limit.PerTransaction, limit.Daily, limit.Monthly, currentTime)
+		if err != nil {
+			tx.Rollback()
+			log.Error().Msg("failed to save transaction limit: " + err.Error())
+			return fmt.Errorf("failed to save transaction limit: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (repo *limitsRepository) UpdateUserTier(req limitsDto.UpdateUserTierRequest) error {
+	var exists bool
+	err := repo.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM account_tiers WHERE name = $1)`, req.Tier).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Error().Msg("account tier not found")
+		return limitsDomain.ErrTierNotFound
+	}
+
+	query := `UPDATE users SET tier = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`
+	result, err := repo.db.Exec(query, req.Tier, time.Now(), req.UserId)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Error().Msg("user not found")
+		return limitsDomain.ErrUserNotFound
+	}
+
+	return nil
+}
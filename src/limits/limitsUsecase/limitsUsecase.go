@@ -0,0 +1,39 @@
+package limitsUsecase
+
+import (
+	"final-project-enigma/model/dto/limitsDto"
+	"final-project-enigma/src/limits"
+)
+
+type limitsUC struct {
+	limitsRepo limits.LimitsRepository
+}
+
+func NewLimitsUsecase(limitsRepo limits.LimitsRepository) limits.LimitsUsecase {
+	return &limitsUC{limitsRepo}
+}
+
+func (usecase *limitsUC) GetTiersUC() ([]limitsDto.TierLimits, error) {
+	return usecase.limitsRepo.GetTiers()
+}
+
+func (usecase *limitsUC) UpsertTierUC(req limitsDto.UpdateTierRequest) (limitsDto.TierLimits, error) {
+	if err := usecase.limitsRepo.UpsertTier(req); err != nil {
+		return limitsDto.TierLimits{}, err
+	}
+
+	tiers, err := usecase.limitsRepo.GetTiers()
+	if err != nil {
+		return limitsDto.TierLimits{}, err
+	}
+	for _, tier := range tiers {
+		if tier.Tier == req.Tier {
+			return tier, nil
+		}
+	}
+	return limitsDto.TierLimits{}, limits.ErrTierNotFound
+}
+
+func (usecase *limitsUC) UpdateUserTierUC(req limitsDto.UpdateUserTierRequest) error {
+	return usecase.limitsRepo.UpdateUserTier(req)
+}
@@ -0,0 +1,203 @@
+package merchantDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/merchantDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/qris"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/merchant"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQRSize = 256
+	maxQRSize     = 1024
+)
+
+type merchantDelivery struct {
+	merchantUC merchant.MerchantUsecase
+}
+
+func NewMerchantDelivery(v1Group *gin.RouterGroup, merchantUC merchant.MerchantUsecase) {
+	handler := merchantDelivery{
+		merchantUC: merchantUC,
+	}
+
+	adminGroup := v1Group.Group("/admin/merchants")
+	{
+		adminGroup.POST("", middleware.JwtAuthWithRoles("ADMIN"), handler.create)
+		adminGroup.GET("/:id/qr", middleware.JwtAuthWithRoles("ADMIN"), handler.generateQR)
+		adminGroup.GET("/:id/keys", middleware.JwtAuthWithRoles("ADMIN"), handler.getApiKeys)
+		adminGroup.POST("/:id/keys", middleware.JwtAuthWithRoles("ADMIN"), handler.rotateApiKey)
+		adminGroup.DELETE("/:id/keys/:keyId", middleware.JwtAuthWithRoles("ADMIN"), handler.revokeApiKey)
+	}
+
+	merchantGroup := v1Group.Group("/merchant")
+	{
+		merchantGroup.GET("/balance", middleware.JwtAuthWithRoles("MERCHANT"), handler.getBalance)
+		merchantGroup.GET("/payments", middleware.JwtAuthWithRoles("MERCHANT"), handler.getPayments)
+		merchantGroup.GET("/qr", middleware.JwtAuthWithRoles("MERCHANT"), handler.generateOwnQR)
+	}
+}
+
+func (m *merchantDelivery) create(ctx *gin.Context) {
+	var req merchantDto.CreateMerchantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := m.merchantUC.CreateMerchantUC(req)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Merchant created", "01", "01")
+}
+
+func (m *merchantDelivery) getApiKeys(ctx *gin.Context) {
+	resp, err := m.merchantUC.GetApiKeysUC(ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get api keys", "01", "01")
+}
+
+// rotateApiKey issues a new key pair. The merchant's other keys keep working
+// for the rotation grace period; the secret is only shown in this response.
+func (m *merchantDelivery) rotateApiKey(ctx *gin.Context) {
+	resp, err := m.merchantUC.RotateApiKeyUC(ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Api key created", "01", "01")
+}
+
+func (m *merchantDelivery) revokeApiKey(ctx *gin.Context) {
+	if err := m.merchantUC.RevokeApiKeyUC(ctx.Param("id"), ctx.Param("keyId")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Api key revoked", "01", "01")
+}
+
+func (m *merchantDelivery) getBalance(ctx *gin.Context) {
+	resp, err := m.merchantUC.GetOwnMerchantUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get merchant balance", "01", "01")
+}
+
+func (m *merchantDelivery) getPayments(ctx *gin.Context) {
+	params := merchantDto.GetPaymentParams{
+		Page:  ctx.Query("page"),
+		Limit: ctx.Query("size"),
+	}
+
+	resp, totalData, err := m.merchantUC.GetOwnPaymentsUC(ctx.GetHeader("Authorization"), params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSuccesPaging(ctx, resp, "Success get merchant payments", "01", "01", ctx.DefaultQuery("page", "1"), totalData)
+}
+
+func (m *merchantDelivery) generateQR(ctx *gin.Context) {
+	params, ok := qrParams(ctx)
+	if !ok {
+		return
+	}
+	params.MerchantId = ctx.Param("id")
+
+	resp, err := m.merchantUC.GenerateQRUC(params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	qrResponse(ctx, resp)
+}
+
+func (m *merchantDelivery) generateOwnQR(ctx *gin.Context) {
+	params, ok := qrParams(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := m.merchantUC.GenerateOwnQRUC(ctx.GetHeader("Authorization"), params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	qrResponse(ctx, resp)
+}
+
+func qrParams(ctx *gin.Context) (merchantDto.QRParams, bool) {
+	params := merchantDto.QRParams{BillNumber: ctx.Query("billNumber")}
+	if amount := ctx.Query("amount"); amount != "" {
+		parsed, err := money.Parse(amount)
+		if err != nil {
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+			return merchantDto.QRParams{}, false
+		}
+		params.Amount = parsed
+	}
+	return params, true
+}
+
+// qrResponse answers with the QR payload as JSON, or with the rendered image
+// when format=png. size sets the image width in pixels.
+func qrResponse(ctx *gin.Context, resp merchantDto.QRResponse) {
+	if ctx.Query("format") != "png" {
+		json.NewResponSucces(ctx, resp, "Success generate QR", "01", "01")
+		return
+	}
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", strconv.Itoa(defaultQRSize)))
+	if err != nil || size <= 0 || size > maxQRSize {
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "size", Message: "size must be between 1 and 1024"}}, "bad request", "01", "02")
+		return
+	}
+
+	image, err := qris.PNG(resp.Payload, size)
+	if err != nil {
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+	ctx.Data(http.StatusOK, "image/png", image)
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, merchant.ErrMerchantNotFound), errors.Is(err, merchant.ErrApiKeyNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, merchant.ErrInvalidOwner):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, merchant.ErrOwnerHasMerchant):
+		json.NewResponseConflict(ctx, err.Error(), "01", "05")
+	case errors.Is(err, merchant.ErrInvalidQR):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "billNumber", Message: err.Error()}}, "bad request", "01", "02")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
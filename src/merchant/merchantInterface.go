@@ -0,0 +1,39 @@
+package merchant
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/merchantDto"
+	"time"
+)
+
+var (
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrInvalidQR        = errors.New("a dynamic QR needs both an amount and a bill number of at most 25 characters")
+	ErrInvalidOwner     = errors.New("owner must be an active user account")
+	ErrOwnerHasMerchant = errors.New("owner already has a merchant")
+	ErrApiKeyNotFound   = errors.New("api key not found")
+	ErrInvalidSignature = errors.New("invalid api key or signature")
+)
+
+type MerchantRepository interface {
+	GetMerchantName(id string) (string, error)
+	Create(req merchantDto.CreateMerchantRequest) (merchantDto.Merchant, error)
+	GetByOwner(ownerId string) (merchantDto.Merchant, error)
+	GetPayments(params merchantDto.GetPaymentParams) ([]merchantDto.Payment, int, error)
+	CreateApiKey(merchantId, keyId, secret string, retireAt time.Time) (merchantDto.ApiKey, error)
+	GetApiKeys(merchantId string) ([]merchantDto.ApiKey, error)
+	RevokeApiKey(merchantId, keyId string) error
+	GetActiveApiKey(keyId string, now time.Time) (merchantDto.ApiKeyAuth, error)
+}
+
+type MerchantUsecase interface {
+	GenerateQRUC(params merchantDto.QRParams) (merchantDto.QRResponse, error)
+	CreateMerchantUC(req merchantDto.CreateMerchantRequest) (merchantDto.Merchant, error)
+	GetOwnMerchantUC(authHeader string) (merchantDto.Merchant, error)
+	GetOwnPaymentsUC(authHeader string, params merchantDto.GetPaymentParams) ([]merchantDto.Payment, string, error)
+	GenerateOwnQRUC(authHeader string, params merchantDto.QRParams) (merchantDto.QRResponse, error)
+	RotateApiKeyUC(merchantId string) (merchantDto.ApiKey, error)
+	GetApiKeysUC(merchantId string) ([]merchantDto.ApiKey, error)
+	RevokeApiKeyUC(merchantId, keyId string) error
+	AuthenticateUC(keyId, timestamp, signature, method, path string, body []byte) (merchantDto.ApiKeyAuth, error)
+}
@@ -0,0 +1,277 @@
+package merchantRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/merchantDto"
+	"final-project-enigma/pkg/dbtx"
+	merchantDomain "final-project-enigma/src/merchant"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type merchantRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantRepository(db *sql.DB) merchantDomain.MerchantRepository {
+	return &merchantRepository{
+		db: db,
+	}
+}
+
+func (repo *merchantRepository) GetMerchantName(id string) (string, error) {
+	var name string
+	query := `SELECT merchant_name FROM merchant WHERE id = $1 AND deleted_at IS NULL`
+	err := repo.db.QueryRow(query, id).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", merchantDomain.ErrMerchantNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get merchant: %w", err)
+	}
+	return name, nil
+}
+
+// Create opens a merchant with an empty wallet of its own and gives the owner
+// the MERCHANT role. The role adds to what a user can do: the owner keeps
+// their personal wallet and every /user route. The owner has to log in again
+// before the new role is in their token.
+func (repo *merchantRepository) Create(req merchantDto.CreateMerchantRequest) (merchantDto.Merchant, error) {
+	var resp merchantDto.Merchant
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var roles string
+		ownerQuery := `
+			SELECT roles FROM users
+			WHERE id = $1 AND status = 'active' AND deleted_at IS NULL
+			FOR UPDATE
+		`
+		err := tx.QueryRow(ownerQuery, req.OwnerId).Scan(&roles)
+		if err == sql.ErrNoRows || roles == "ADMIN" {
+			return merchantDomain.ErrInvalidOwner
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get owner: %w", err)
+		}
+
+		var hasMerchant bool
+		err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM merchant WHERE owner_id = $1)`, req.OwnerId).Scan(&hasMerchant)
+		if err != nil {
+			return fmt.Errorf("failed to check owner: %w", err)
+		}
+		if hasMerchant {
+			return merchantDomain.ErrOwnerHasMerchant
+		}
+
+		currentTime := time.Now()
+		err = tx.QueryRow(`INSERT INTO wallets (user_id, created_at, updated_at) VALUES (NULL, $1, $1) RETURNING id`, currentTime).Scan(&resp.WalletId)
+		if err != nil {
+			return fmt.Errorf("failed to create merchant wallet: %w", err)
+		}
+
+		merchantQuery := `
+			INSERT INTO merchant (merchant_name, owner_id, wallet_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING id
+		`
+		err = tx.QueryRow(merchantQuery, req.MerchantName, req.OwnerId, resp.WalletId, currentTime).Scan(&resp.Id)
+		if err != nil {
+			return fmt.Errorf("failed to create merchant: %w", err)
+		}
+
+		_, err = tx.Exec(`UPDATE users SET roles = 'MERCHANT', updated_at = $1 WHERE id = $2`, currentTime, req.OwnerId)
+		if err != nil {
+			return fmt.Errorf("failed to update owner role: %w", err)
+		}
+
+		resp.MerchantName = req.MerchantName
+		resp.OwnerId = req.OwnerId
+		resp.CreatedAt = currentTime
+		return nil
+	})
+	if err != nil {
+		log.Error().Msg("failed to create merchant: " + err.Error())
+		return merchantDto.Merchant{}, err
+	}
+
+	return resp, nil
+}
+
+func (repo *merchantRepository) GetByOwner(ownerId string) (merchantDto.Merchant, error) {
+	var resp merchantDto.Merchant
+	query := `
+		SELECT m.id, m.merchant_name, m.owner_id, m.wallet_id, w.balance, m.created_at
+		FROM merchant m
+		JOIN wallets w ON w.id = m.wallet_id
+		WHERE m.owner_id = $1 AND m.deleted_at IS NULL
+	`
+	err := repo.db.QueryRow(query, ownerId).Scan(&resp.Id, &resp.MerchantName, &resp.OwnerId, &resp.WalletId, &resp.Balance, &resp.CreatedAt)
+	if err == sql.ErrNoRows {
+		return merchantDto.Merchant{}, merchantDomain.ErrMerchantNotFound
+	}
+	if err != nil {
+		return merchantDto.Merchant{}, fmt.Errorf("failed to get merchant: %w", err)
+	}
+	return resp, nil
+}
+
+func (repo *merchantRepository) GetPayments(params merchantDto.GetPaymentParams) ([]merchantDto.Payment, int, error) {
+	var totalData int
+	countQuery := `SELECT COUNT(*) FROM merchant_transactions WHERE merchant_id = $1`
+	if err := repo.db.QueryRow(countQuery, params.MerchantId).Scan(&totalData); err != nil {
+		return nil, 0, fmt.Errorf("failed to count merchant payments: %w", err)
+	}
+
+	query := `
+		SELECT t.id, t.reversal_of IS NOT NULL, t.amount, u.fullname, COALESCE(t.description, ''),
+			COALESCE(mt.bill_reference, ''), t.status, t.created_at
+		FROM merchant_transactions mt
+		JOIN transactions t ON t.id = mt.transaction_id
+		JOIN users u ON u.id = t.user_id
+		WHERE mt.merchant_id = $1
+		ORDER BY t.created_at DESC
+	`
+	if params.Page != "" && params.Limit != "" {
+		page, _ := strconv.Atoi(params.Page)
+		limit, _ := strconv.Atoi(params.Limit)
+		offset := (page - 1) * limit
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	rows, err := repo.db.Query(query, params.MerchantId)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get merchant payments: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []merchantDto.Payment{}
+	for rows.Next() {
+		var payment merchantDto.Payment
+		var refund bool
+		if err := rows.Scan(&payment.TransactionId, &refund, &payment.Amount, &payment.PayerName, &payment.Description,
+			&payment.BillReference, &payment.Status, &payment.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan merchant payment: %w", err)
+		}
+		payment.Type = merchantDto.PaymentTypePayment
+		if refund {
+			payment.Type = merchantDto.PaymentTypeRefund
+		}
+		resp = append(resp, payment)
+	}
+
+	return resp, totalData, rows.Err()
+}
+
+// CreateApiKey adds a key for the merchant and retires its current keys at
+// retireAt, so partners can switch to the new key without downtime.
+func (repo *merchantRepository) CreateApiKey(merchantId, keyId, secret string, retireAt time.Time) (merchantDto.ApiKey, error) {
+	var resp merchantDto.ApiKey
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM merchant WHERE id = $1 AND deleted_at IS NULL)`, merchantId).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check merchant: %w", err)
+		}
+		if !exists {
+			return merchantDomain.ErrMerchantNotFound
+		}
+
+		retireQuery := `
+			UPDATE merchant_api_keys
+			SET expires_at = $1
+			WHERE merchant_id = $2 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $1)
+		`
+		if _, err := tx.Exec(retireQuery, retireAt, merchantId); err != nil {
+			return fmt.Errorf("failed to retire api keys: %w", err)
+		}
+
+		insertQuery := `
+			INSERT INTO merchant_api_keys (merchant_id, key_id, secret, created_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`
+		return tx.QueryRow(insertQuery, merchantId, keyId, secret, time.Now()).Scan(&resp.Id, &resp.CreatedAt)
+	})
+	if err != nil {
+		log.Error().Msg("failed to create api key: " + err.Error())
+		return merchantDto.ApiKey{}, err
+	}
+
+	resp.KeyId = keyId
+	resp.Secret = secret
+	resp.Status = merchantDto.ApiKeyStatusActive
+	return resp, nil
+}
+
+func (repo *merchantRepository) GetApiKeys(merchantId string) ([]merchantDto.ApiKey, error) {
+	query := `
+		SELECT id, key_id, expires_at, revoked_at, created_at
+		FROM merchant_api_keys
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := repo.db.Query(query, merchantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	resp := []merchantDto.ApiKey{}
+	for rows.Next() {
+		var key merchantDto.ApiKey
+		if err := rows.Scan(&key.Id, &key.KeyId, &key.ExpiresAt, &key.RevokedAt, &key.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		switch {
+		case key.RevokedAt != nil:
+			key.Status = merchantDto.ApiKeyStatusRevoked
+		case key.ExpiresAt == nil:
+			key.Status = merchantDto.ApiKeyStatusActive
+		case key.ExpiresAt.After(now):
+			key.Status = merchantDto.ApiKeyStatusRetiring
+		default:
+			key.Status = merchantDto.ApiKeyStatusExpired
+		}
+		resp = append(resp, key)
+	}
+
+	return resp, rows.Err()
+}
+
+func (repo *merchantRepository) RevokeApiKey(merchantId, keyId string) error {
+	query := `
+		UPDATE merchant_api_keys
+		SET revoked_at = $1
+		WHERE key_id = $2 AND merchant_id = $3 AND revoked_at IS NULL
+	`
+	result, err := repo.db.Exec(query, time.Now(), keyId, merchantId)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return merchantDomain.ErrApiKeyNotFound
+	}
+	return nil
+}
+
+func (repo *merchantRepository) GetActiveApiKey(keyId string, now time.Time) (merchantDto.ApiKeyAuth, error) {
+	var resp merchantDto.ApiKeyAuth
+	query := `
+		SELECT k.id, k.merchant_id, k.secret
+		FROM merchant_api_keys k
+		JOIN merchant m ON m.id = k.merchant_id
+		WHERE k.key_id = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > $2)
+			AND m.deleted_at IS NULL
+	`
+	err := repo.db.QueryRow(query, keyId, now).Scan(&resp.ApiKeyId, &resp.MerchantId, &resp.Secret)
+	if err == sql.ErrNoRows {
+		return merchantDto.ApiKeyAuth{}, merchantDomain.ErrApiKeyNotFound
+	}
+	if err != nil {
+		return merchantDto.ApiKeyAuth{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return resp, nil
+}
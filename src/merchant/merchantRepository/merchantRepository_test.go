@@ -0,0 +1,78 @@
+package merchantRepository
+
+import (
+	"final-project-enigma/model/dto/merchantDto"
+	"final-project-enigma/src/merchant"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreate(t *testing.T) {
+	req := merchantDto.CreateMerchantRequest{MerchantName: "Kopi Kenangan", OwnerId: "owner1"}
+
+	t.Run("admin cannot own a merchant", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT roles FROM users").
+			WithArgs("owner1").
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow("ADMIN"))
+		mock.ExpectRollback()
+
+		_, err := NewMerchantRepository(db).Create(req)
+
+		assert.ErrorIs(t, err, merchant.ErrInvalidOwner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("owner already has a merchant", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT roles FROM users").
+			WithArgs("owner1").
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow("MERCHANT"))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("owner1").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err := NewMerchantRepository(db).Create(req)
+
+		assert.ErrorIs(t, err, merchant.ErrOwnerHasMerchant)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT roles FROM users").
+			WithArgs("owner1").
+			WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow("USER"))
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs("owner1").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("INSERT INTO wallets").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet1"))
+		mock.ExpectQuery("INSERT INTO merchant").
+			WithArgs("Kopi Kenangan", "owner1", "wallet1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("merchant1"))
+		mock.ExpectExec("UPDATE users SET roles = 'MERCHANT'").
+			WithArgs(sqlmock.AnyArg(), "owner1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		resp, err := NewMerchantRepository(db).Create(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "merchant1", resp.Id)
+		assert.Equal(t, "wallet1", resp.WalletId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,163 @@
+package merchantUsecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"final-project-enigma/model/dto/merchantDto"
+	"final-project-enigma/pkg/helper/merchantSignature"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/qris"
+	"final-project-enigma/src/merchant"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	maxBillNumberLength  = 25
+	defaultPage          = "1"
+	defaultPageSize      = "10"
+	defaultRotationGrace = 24 * time.Hour
+)
+
+type merchantUC struct {
+	merchantRepo  merchant.MerchantRepository
+	rotationGrace time.Duration
+}
+
+// NewMerchantUsecase reads MERCHANT_KEY_ROTATION_GRACE, how long the previous
+// API keys keep working after a new one is issued.
+func NewMerchantUsecase(merchantRepo merchant.MerchantRepository) merchant.MerchantUsecase {
+	rotationGrace, err := time.ParseDuration(os.Getenv("MERCHANT_KEY_ROTATION_GRACE"))
+	if err != nil || rotationGrace < 0 {
+		rotationGrace = defaultRotationGrace
+	}
+
+	return &merchantUC{
+		merchantRepo:  merchantRepo,
+		rotationGrace: rotationGrace,
+	}
+}
+
+func (usecase *merchantUC) GenerateQRUC(params merchantDto.QRParams) (merchantDto.QRResponse, error) {
+	if (params.Amount > 0) != (params.BillNumber != "") || len(params.BillNumber) > maxBillNumberLength || params.Amount < 0 {
+		return merchantDto.QRResponse{}, merchant.ErrInvalidQR
+	}
+
+	name, err := usecase.merchantRepo.GetMerchantName(params.MerchantId)
+	if err != nil {
+		return merchantDto.QRResponse{}, err
+	}
+
+	payload := qris.Payload{
+		MerchantId:   params.MerchantId,
+		MerchantName: name,
+		MerchantCity: qris.City(),
+		Amount:       params.Amount,
+		BillNumber:   params.BillNumber,
+	}
+
+	qrType := merchantDto.QRTypeStatic
+	if payload.IsDynamic() {
+		qrType = merchantDto.QRTypeDynamic
+	}
+
+	return merchantDto.QRResponse{
+		MerchantId:   params.MerchantId,
+		MerchantName: name,
+		Type:         qrType,
+		Amount:       params.Amount,
+		BillNumber:   params.BillNumber,
+		Payload:      qris.Encode(payload),
+	}, nil
+}
+
+func (usecase *merchantUC) CreateMerchantUC(req merchantDto.CreateMerchantRequest) (merchantDto.Merchant, error) {
+	return usecase.merchantRepo.Create(req)
+}
+
+func (usecase *merchantUC) GetOwnMerchantUC(authHeader string) (merchantDto.Merchant, error) {
+	ownerId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return merchantDto.Merchant{}, err
+	}
+	return usecase.merchantRepo.GetByOwner(ownerId)
+}
+
+func (usecase *merchantUC) GetOwnPaymentsUC(authHeader string, params merchantDto.GetPaymentParams) ([]merchantDto.Payment, string, error) {
+	own, err := usecase.GetOwnMerchantUC(authHeader)
+	if err != nil {
+		return nil, "", err
+	}
+	params.MerchantId = own.Id
+
+	if page, err := strconv.Atoi(params.Page); err != nil || page < 1 {
+		params.Page = defaultPage
+	}
+	if limit, err := strconv.Atoi(params.Limit); err != nil || limit < 1 {
+		params.Limit = defaultPageSize
+	}
+
+	resp, totalData, err := usecase.merchantRepo.GetPayments(params)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, strconv.Itoa(totalData), nil
+}
+
+func (usecase *merchantUC) GenerateOwnQRUC(authHeader string, params merchantDto.QRParams) (merchantDto.QRResponse, error) {
+	own, err := usecase.GetOwnMerchantUC(authHeader)
+	if err != nil {
+		return merchantDto.QRResponse{}, err
+	}
+	params.MerchantId = own.Id
+	return usecase.GenerateQRUC(params)
+}
+
+func (usecase *merchantUC) RotateApiKeyUC(merchantId string) (merchantDto.ApiKey, error) {
+	keyId, err := randomToken("mk_", 16)
+	if err != nil {
+		return merchantDto.ApiKey{}, err
+	}
+	secret, err := randomToken("sk_", 32)
+	if err != nil {
+		return merchantDto.ApiKey{}, err
+	}
+
+	return usecase.merchantRepo.CreateApiKey(merchantId, keyId, secret, time.Now().Add(usecase.rotationGrace))
+}
+
+func (usecase *merchantUC) GetApiKeysUC(merchantId string) ([]merchantDto.ApiKey, error) {
+	return usecase.merchantRepo.GetApiKeys(merchantId)
+}
+
+func (usecase *merchantUC) RevokeApiKeyUC(merchantId, keyId string) error {
+	return usecase.merchantRepo.RevokeApiKey(merchantId, keyId)
+}
+
+// AuthenticateUC checks a signed partner request. An unknown key and a bad
+// signature give the same error so callers cannot probe for key ids.
+func (usecase *merchantUC) AuthenticateUC(keyId, timestamp, signature, method, path string, body []byte) (merchantDto.ApiKeyAuth, error) {
+	now := time.Now()
+	auth, err := usecase.merchantRepo.GetActiveApiKey(keyId, now)
+	if errors.Is(err, merchant.ErrApiKeyNotFound) {
+		return merchantDto.ApiKeyAuth{}, merchant.ErrInvalidSignature
+	}
+	if err != nil {
+		return merchantDto.ApiKeyAuth{}, err
+	}
+
+	if !merchantSignature.Verify(signature, auth.Secret, timestamp, method, path, body, now) {
+		return merchantDto.ApiKeyAuth{}, merchant.ErrInvalidSignature
+	}
+	return auth, nil
+}
+
+func randomToken(prefix string, size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
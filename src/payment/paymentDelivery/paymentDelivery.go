@@ -1,8 +1,10 @@
 package paymentDelivery
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/json"
 	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/validation"
 	"final-project-enigma/src/payment"
 
 	"github.com/gin-gonic/gin"
@@ -27,12 +29,28 @@ func NewPaymentDelivery(v1Group *gin.RouterGroup, paymentUC payment.PaymentUseca
 func (u *paymentDelivery) midtransStatusRequest(ctx *gin.Context) {
 	var notification userDto.MidtransNotification
 	if err := ctx.ShouldBindJSON(&notification); err != nil {
-		json.NewResponseError(ctx, "", "01", "01")
+		validationError := validation.GetValidationError(err)
+
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
 	}
 
 	err := u.paymentUC.MidtransStatusReq(notification)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "01", "01")
+		switch {
+		case errors.Is(err, payment.ErrInvalidSignature):
+			json.NewResponseUnauthorized(ctx, err.Error(), "01", "02")
+		case errors.Is(err, payment.ErrAmountMismatch):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+		case errors.Is(err, payment.ErrTransactionNotFound):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+		default:
+			json.NewResponseError(ctx, err.Error(), "01", "01")
+		}
 		return
 	}
 	json.NewResponSucces(ctx, nil, "create transaction success", "01", "01")
@@ -1,12 +1,31 @@
 package payment
 
-import "final-project-enigma/model/dto/userDto"
+import (
+	"errors"
+	"final-project-enigma/model/dto/userDto"
+	"time"
+)
+
+var (
+	ErrInvalidSignature    = errors.New("invalid signature key")
+	ErrAmountMismatch      = errors.New("gross amount does not match transaction amount")
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// StatusChecker asks the payment gateway for the current status of an
+// order.
+type StatusChecker interface {
+	TransactionStatus(orderID string) (userDto.MidtransNotification, error)
+}
 
 type PaymentRepository interface {
 	UpdateTransactionStatus(orderID string, status string) error
-	UpdateBalance(orderID, amount string) error
+	SettleTopUp(orderID, grossAmount string) error
+	GetStaleTopUps(checkedBefore time.Time, limit int) ([]userDto.PendingTopUp, error)
+	MarkStatusChecked(orderID string, checkedAt time.Time) error
 }
 
 type PaymentUsecase interface {
 	MidtransStatusReq(notification userDto.MidtransNotification) error
+	PollPendingTopUpsUC(now time.Time) error
 }
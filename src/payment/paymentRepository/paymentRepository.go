@@ -3,12 +3,16 @@ package paymentRepository
 import (
 	"database/sql"
 	"errors"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
 	"final-project-enigma/src/payment"
 	"fmt"
-	"strconv"
 	"time"
-	"github.com/rs/zerolog/log"
 
+	"github.com/rs/zerolog/log"
 )
 
 type paymentRepository struct {
@@ -23,7 +27,7 @@ func NewPaymentRepository(db *sql.DB) payment.PaymentRepository {
 
 func (repo *paymentRepository) UpdateTransactionStatus(orderID string, status string) error {
 
-	query := `UPDATE transactions SET status = $1 WHERE id = $2`
+	query := `UPDATE transactions SET status = $1 WHERE id = $2 AND status = 'pending'`
 	_, err := repo.db.Exec(query, status, orderID)
 	if err != nil {
 		log.Error().Msg("failed to update transaction status")
@@ -33,32 +37,103 @@ func (repo *paymentRepository) UpdateTransactionStatus(orderID string, status st
 	return err
 }
 
-func (repo *paymentRepository) UpdateBalance(orderID, amountStr string) error {
-	amount, err := strconv.ParseFloat(amountStr, 64)
+func (repo *paymentRepository) SettleTopUp(orderID, grossAmount string) error {
+	notifiedAmount, err := money.Parse(grossAmount)
 	if err != nil {
-		log.Error().Msg("invalid amount: %v" + err.Error())
-		return fmt.Errorf("invalid amount: %v", err)
+		log.Error().Msg("invalid gross amount: " + err.Error())
+		return payment.ErrAmountMismatch
 	}
 
-	var userID string
-	query := `SELECT user_id FROM transactions WHERE id = $1`
-	err = repo.db.QueryRow(query, orderID).Scan(&userID)
-	if err != nil {
-		return err
-	}
+	return dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var status, walletID string
+		var amount, fee money.Money
+		query := `
+			SELECT t.status, t.amount, t.fee, w.id
+			FROM transactions t
+			JOIN topup_transactions tt ON tt.transaction_id = t.id
+			JOIN wallets w ON w.user_id = t.user_id AND w.is_main
+			WHERE t.id = $1
+			FOR UPDATE OF t
+		`
+		err := tx.QueryRow(query, orderID).Scan(&status, &amount, &fee, &walletID)
+		if err == sql.ErrNoRows {
+			log.Error().Msg("top up transaction not found")
+			return payment.ErrTransactionNotFound
+		}
+		if err != nil {
+			log.Error().Msg("failed to get top up transaction: " + err.Error())
+			return err
+		}
+
+		// The user is charged the top-up amount plus its fee.
+		if amount+fee != notifiedAmount {
+			log.Error().Msg("gross amount does not match transaction " + orderID)
+			return payment.ErrAmountMismatch
+		}
+
+		// Only the first pending -> success transition credits the wallet, a
+		// repeated settlement notification is acknowledged without effect.
+		if status != "pending" {
+			return nil
+		}
+
+		updateQuery := `UPDATE transactions SET status = 'success' WHERE id = $1`
+		if _, err := tx.Exec(updateQuery, orderID); err != nil {
+			log.Error().Msg("failed to update transaction status")
+			return errors.New("failed to update transaction status")
+		}
 
-	var walletID string
-	query = `SELECT id FROM wallets WHERE user_id = $1`
-	err = repo.db.QueryRow(query, userID).Scan(&walletID)
+		settledQuery := `UPDATE topup_transactions SET settled_at = $1 WHERE transaction_id = $2`
+		if _, err := tx.Exec(settledQuery, time.Now(), orderID); err != nil {
+			return fmt.Errorf("failed to update top up: %w", err)
+		}
+
+		// The user has already paid, so the top-up is credited even when the
+		// wallet was frozen after it was opened; the money stays frozen with
+		// the rest of the balance.
+		entries := append([]ledger.Entry{
+			ledger.Debit(ledger.AccountPaymentGateway, ledger.GatewayMidtrans, amount+fee),
+			ledger.CreditOwed(ledger.AccountWallet, walletID, amount),
+		}, fees.Entries(fee)...)
+		return ledger.Post(tx, orderID, entries...)
+	})
+}
+
+// GetStaleTopUps returns pending top-ups created and last checked before
+// checkedBefore, oldest first.
+func (repo *paymentRepository) GetStaleTopUps(checkedBefore time.Time, limit int) ([]userDto.PendingTopUp, error) {
+	query := `
+		SELECT t.id, t.created_at
+		FROM transactions t
+		JOIN topup_transactions tt ON tt.transaction_id = t.id
+		WHERE t.status = 'pending'
+			AND t.created_at < $1
+			AND (tt.status_checked_at IS NULL OR tt.status_checked_at < $1)
+		ORDER BY t.created_at
+		LIMIT $2
+	`
+	rows, err := repo.db.Query(query, checkedBefore, limit)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get pending top ups: %w", err)
 	}
+	defer rows.Close()
 
-	query = `UPDATE wallets SET balance = balance + $1, updated_at = $2 WHERE id = $3`
-	_, err = repo.db.Exec(query, amount, time.Now(), walletID)
-	if err != nil {
-		return err
+	var resp []userDto.PendingTopUp
+	for rows.Next() {
+		var topUp userDto.PendingTopUp
+		if err := rows.Scan(&topUp.TransactionId, &topUp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending top up: %w", err)
+		}
+		resp = append(resp, topUp)
 	}
 
+	return resp, rows.Err()
+}
+
+func (repo *paymentRepository) MarkStatusChecked(orderID string, checkedAt time.Time) error {
+	query := `UPDATE topup_transactions SET status_checked_at = $1 WHERE transaction_id = $2`
+	if _, err := repo.db.Exec(query, checkedAt, orderID); err != nil {
+		return fmt.Errorf("failed to update top up status check: %w", err)
+	}
 	return nil
 }
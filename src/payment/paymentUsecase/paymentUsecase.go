@@ -1,34 +1,80 @@
 package paymentUsecase
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/helper/midtransSignature"
+	"final-project-enigma/pkg/helper/midtransStatus"
 	"final-project-enigma/src/payment"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPollAfter = 15 * time.Minute
+	defaultExpiry    = 24 * time.Hour
+	pollBatch        = 50
 )
 
 type paymentUC struct {
-	paymentRepo payment.PaymentRepository
+	paymentRepo   payment.PaymentRepository
+	statusChecker payment.StatusChecker
+	pollAfter     time.Duration
+	expiry        time.Duration
 }
 
-func NewPaymentUsecase(paymentRepo payment.PaymentRepository) payment.PaymentUsecase {
-	return &paymentUC{paymentRepo}
+// NewPaymentUsecase reads TOPUP_POLL_AFTER, how long a top-up may stay
+// pending before the gateway is asked for its status, and TOPUP_EXPIRY, the
+// Snap expiry after which a top-up the gateway never saw is cancelled.
+func NewPaymentUsecase(paymentRepo payment.PaymentRepository, statusChecker payment.StatusChecker) payment.PaymentUsecase {
+	pollAfter, err := time.ParseDuration(os.Getenv("TOPUP_POLL_AFTER"))
+	if err != nil || pollAfter <= 0 {
+		pollAfter = defaultPollAfter
+	}
+
+	expiry, err := time.ParseDuration(os.Getenv("TOPUP_EXPIRY"))
+	if err != nil || expiry <= 0 {
+		expiry = defaultExpiry
+	}
+
+	return &paymentUC{
+		paymentRepo:   paymentRepo,
+		statusChecker: statusChecker,
+		pollAfter:     pollAfter,
+		expiry:        expiry,
+	}
 }
 
 func (usecase *paymentUC) MidtransStatusReq(notification userDto.MidtransNotification) error {
 
+	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
+	if !midtransSignature.Verify(notification.SignatureKey, notification.OrderID, notification.StatusCode, notification.GrossAmount, serverKey) {
+		log.Error().Msg("invalid midtrans signature for order " + notification.OrderID)
+		return payment.ErrInvalidSignature
+	}
+
+	return usecase.applyStatus(notification)
+}
+
+// applyStatus moves a top-up on by the gateway's status. A card payment
+// that failed to process ends like a denied one; any other status, such as
+// "authorize", leaves the top-up pending so a later settlement still credits
+// it.
+func (usecase *paymentUC) applyStatus(notification userDto.MidtransNotification) error {
 	switch notification.TransactionStatus {
 	case "capture":
-		if notification.FraudStatus == "challenge" {
-		} else if notification.FraudStatus == "accept" {
-			usecase.paymentRepo.UpdateTransactionStatus(notification.OrderID, "settlement")
+		if notification.FraudStatus == "accept" {
+			if err := usecase.paymentRepo.SettleTopUp(notification.OrderID, notification.GrossAmount); err != nil {
+				return err
+			}
 		}
 	case "settlement":
-		if err := usecase.paymentRepo.UpdateTransactionStatus(notification.OrderID, "success"); err != nil {
+		if err := usecase.paymentRepo.SettleTopUp(notification.OrderID, notification.GrossAmount); err != nil {
 			return err
 		}
-		if err := usecase.paymentRepo.UpdateBalance(notification.OrderID, notification.GrossAmount); err != nil {
-			return err
-		}
-	case "deny":
+	case "deny", "failure":
 		if err := usecase.paymentRepo.UpdateTransactionStatus(notification.OrderID, "deny"); err != nil {
 			return err
 		}
@@ -41,8 +87,51 @@ func (usecase *paymentUC) MidtransStatusReq(notification userDto.MidtransNotific
 			return err
 		}
 	default:
-		usecase.paymentRepo.UpdateTransactionStatus(notification.OrderID, notification.TransactionStatus)
+		log.Info().Msg("top up " + notification.OrderID + " left pending on status " + notification.TransactionStatus)
 	}
 
 	return nil
 }
+
+// PollPendingTopUpsUC asks the gateway about top-ups whose notification
+// never arrived and applies what it reports. It is called by the background
+// worker started from the router.
+func (usecase *paymentUC) PollPendingTopUpsUC(now time.Time) error {
+	stale, err := usecase.paymentRepo.GetStaleTopUps(now.Add(-usecase.pollAfter), pollBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, topUp := range stale {
+		if err := usecase.pollTopUp(topUp, now); err != nil {
+			log.Error().Msg("failed to poll top up " + topUp.TransactionId + ": " + err.Error())
+			continue
+		}
+		if err := usecase.paymentRepo.MarkStatusChecked(topUp.TransactionId, now); err != nil {
+			log.Error().Msg(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// pollTopUp only cancels a top-up on its own when the gateway has no record
+// of it and the Snap page has expired, since then it can no longer be paid.
+// One the gateway still reports as pending is left for the gateway to
+// expire, so a late payment is never lost.
+func (usecase *paymentUC) pollTopUp(topUp userDto.PendingTopUp, now time.Time) error {
+	status, err := usecase.statusChecker.TransactionStatus(topUp.TransactionId)
+	if errors.Is(err, midtransStatus.ErrOrderNotFound) {
+		if now.Sub(topUp.CreatedAt) < usecase.expiry {
+			return nil
+		}
+		log.Info().Msg("top up " + topUp.TransactionId + " expired before a payment was made")
+		return usecase.paymentRepo.UpdateTransactionStatus(topUp.TransactionId, "cancel")
+	}
+	if err != nil {
+		return err
+	}
+
+	status.OrderID = topUp.TransactionId
+	return usecase.applyStatus(status)
+}
@@ -0,0 +1,115 @@
+package paymentUsecase_test
+
+import (
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/helper/midtransSignature"
+	"final-project-enigma/pkg/helper/midtransStatus"
+	"final-project-enigma/src/payment"
+	"final-project-enigma/src/payment/paymentUsecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPaymentRepo struct {
+	settled  []string
+	statuses map[string]string
+	stale    []userDto.PendingTopUp
+	checked  []string
+}
+
+func (m *mockPaymentRepo) UpdateTransactionStatus(orderID string, status string) error {
+	m.statuses[orderID] = status
+	return nil
+}
+
+func (m *mockPaymentRepo) SettleTopUp(orderID, grossAmount string) error {
+	m.settled = append(m.settled, orderID)
+	return nil
+}
+
+func (m *mockPaymentRepo) GetStaleTopUps(checkedBefore time.Time, limit int) ([]userDto.PendingTopUp, error) {
+	return m.stale, nil
+}
+
+func (m *mockPaymentRepo) MarkStatusChecked(orderID string, checkedAt time.Time) error {
+	m.checked = append(m.checked, orderID)
+	return nil
+}
+
+func signedNotification(status, serverKey string) userDto.MidtransNotification {
+	notification := userDto.MidtransNotification{
+		TransactionStatus: status,
+		OrderID:           "order-1",
+		StatusCode:        "200",
+		GrossAmount:       "100000.00",
+	}
+	notification.SignatureKey = midtransSignature.Generate(notification.OrderID, notification.StatusCode, notification.GrossAmount, serverKey)
+	return notification
+}
+
+func TestMidtransStatusReq_Settlement(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &mockPaymentRepo{statuses: map[string]string{}}
+	usecase := paymentUsecase.NewPaymentUsecase(repo, nil)
+
+	err := usecase.MidtransStatusReq(signedNotification("settlement", "server-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-1"}, repo.settled)
+}
+
+func TestMidtransStatusReq_UnknownStatusStaysPending(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &mockPaymentRepo{statuses: map[string]string{}}
+	usecase := paymentUsecase.NewPaymentUsecase(repo, nil)
+
+	assert.NoError(t, usecase.MidtransStatusReq(signedNotification("authorize", "server-key")))
+	assert.Empty(t, repo.statuses)
+
+	assert.NoError(t, usecase.MidtransStatusReq(signedNotification("settlement", "server-key")))
+	assert.Equal(t, []string{"order-1"}, repo.settled)
+}
+
+func TestMidtransStatusReq_Failure(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &mockPaymentRepo{statuses: map[string]string{}}
+	usecase := paymentUsecase.NewPaymentUsecase(repo, nil)
+
+	assert.NoError(t, usecase.MidtransStatusReq(signedNotification("failure", "server-key")))
+	assert.Equal(t, map[string]string{"order-1": "deny"}, repo.statuses)
+}
+
+func TestMidtransStatusReq_InvalidSignature(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &mockPaymentRepo{statuses: map[string]string{}}
+	usecase := paymentUsecase.NewPaymentUsecase(repo, nil)
+
+	err := usecase.MidtransStatusReq(signedNotification("settlement", "forged-key"))
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	assert.Empty(t, repo.settled)
+}
+
+func TestPollPendingTopUpsUC(t *testing.T) {
+	t.Setenv("TOPUP_EXPIRY", "24h")
+
+	now := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+	repo := &mockPaymentRepo{
+		statuses: map[string]string{},
+		stale: []userDto.PendingTopUp{
+			{TransactionId: "paid", CreatedAt: now.Add(-time.Hour)},
+			{TransactionId: "unopened", CreatedAt: now.Add(-time.Hour)},
+			{TransactionId: "abandoned", CreatedAt: now.Add(-25 * time.Hour)},
+			{TransactionId: "waiting", CreatedAt: now.Add(-25 * time.Hour)},
+		},
+	}
+	checker := midtransStatus.NewFake()
+	checker.Statuses["paid"] = userDto.MidtransNotification{TransactionStatus: "settlement", GrossAmount: "100000.00"}
+	checker.Statuses["waiting"] = userDto.MidtransNotification{TransactionStatus: "pending", GrossAmount: "100000.00"}
+	usecase := paymentUsecase.NewPaymentUsecase(repo, checker)
+
+	assert.NoError(t, usecase.PollPendingTopUpsUC(now))
+	assert.Equal(t, []string{"paid"}, repo.settled)
+	assert.Equal(t, map[string]string{"abandoned": "cancel", "waiting": "pending"}, repo.statuses)
+	assert.Equal(t, []string{"paid", "unopened", "abandoned", "waiting"}, repo.checked)
+}
@@ -0,0 +1,30 @@
+package paymentMethodDelivery
+
+import (
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/src/paymentMethod"
+
+	"github.com/gin-gonic/gin"
+)
+
+type paymentMethodDelivery struct {
+	paymentMethodUC paymentMethod.PaymentMethodUsecase
+}
+
+func NewPaymentMethodDelivery(v1Group *gin.RouterGroup, paymentMethodUC paymentMethod.PaymentMethodUsecase) {
+	handler := paymentMethodDelivery{
+		paymentMethodUC: paymentMethodUC,
+	}
+
+	v1Group.GET("/payment-methods", handler.getActive)
+}
+
+func (p *paymentMethodDelivery) getActive(ctx *gin.Context) {
+	resp, err := p.paymentMethodUC.GetActiveUC()
+	if err != nil {
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get payment methods", "01", "01")
+}
@@ -0,0 +1,11 @@
+package paymentMethod
+
+import "final-project-enigma/model/dto/paymentMethodDto"
+
+type PaymentMethodRepository interface {
+	GetActive() ([]paymentMethodDto.PaymentMethod, error)
+}
+
+type PaymentMethodUsecase interface {
+	GetActiveUC() ([]paymentMethodDto.PaymentMethod, error)
+}
@@ -0,0 +1,42 @@
+package paymentMethodRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/paymentMethodDto"
+	paymentMethodDomain "final-project-enigma/src/paymentMethod"
+	"fmt"
+)
+
+type paymentMethodRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentMethodRepository(db *sql.DB) paymentMethodDomain.PaymentMethodRepository {
+	return &paymentMethodRepository{
+		db: db,
+	}
+}
+
+func (repo *paymentMethodRepository) GetActive() ([]paymentMethodDto.PaymentMethod, error) {
+	query := `
+		SELECT id, payment_name, COALESCE(channel_type, ''), min_amount, max_amount, expiry_minutes, COALESCE(icon_url, '')
+		FROM payment_method
+		WHERE enabled AND deleted_at IS NULL
+		ORDER BY display_order, payment_name
+	`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment methods: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []paymentMethodDto.PaymentMethod{}
+	for rows.Next() {
+		var m paymentMethodDto.PaymentMethod
+		if err := rows.Scan(&m.Id, &m.Name, &m.ChannelType, &m.MinAmount, &m.MaxAmount, &m.ExpiryMinutes, &m.IconURL); err != nil {
+			return nil, fmt.Errorf("failed to scan payment method: %w", err)
+		}
+		resp = append(resp, m)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,20 @@
+package paymentMethodUsecase
+
+import (
+	"final-project-enigma/model/dto/paymentMethodDto"
+	"final-project-enigma/src/paymentMethod"
+)
+
+type paymentMethodUC struct {
+	paymentMethodRepo paymentMethod.PaymentMethodRepository
+}
+
+func NewPaymentMethodUsecase(paymentMethodRepo paymentMethod.PaymentMethodRepository) paymentMethod.PaymentMethodUsecase {
+	return &paymentMethodUC{
+		paymentMethodRepo: paymentMethodRepo,
+	}
+}
+
+func (usecase *paymentMethodUC) GetActiveUC() ([]paymentMethodDto.PaymentMethod, error) {
+	return usecase.paymentMethodRepo.GetActive()
+}
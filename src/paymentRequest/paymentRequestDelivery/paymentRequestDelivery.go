@@ -0,0 +1,139 @@
+package paymentRequestDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/paymentRequestDto"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/paymentRequest"
+
+	"github.com/gin-gonic/gin"
+)
+
+type paymentRequestDelivery struct {
+	requestUC paymentRequest.PaymentRequestUsecase
+}
+
+func NewPaymentRequestDelivery(v1Group *gin.RouterGroup, requestUC paymentRequest.PaymentRequestUsecase) {
+	handler := paymentRequestDelivery{
+		requestUC: requestUC,
+	}
+
+	requestGroup := v1Group.Group("/user/requests")
+	{
+		requestGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.create)
+		requestGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getAll)
+		requestGroup.POST("/:id/accept", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.accept)
+		requestGroup.POST("/:id/decline", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.decline)
+		requestGroup.DELETE("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.cancel)
+	}
+}
+
+func (p *paymentRequestDelivery) create(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req paymentRequestDto.CreatePaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := p.requestUC.CreateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Payment request created", "01", "01")
+}
+
+func (p *paymentRequestDelivery) getAll(ctx *gin.Context) {
+	params := paymentRequestDto.GetPaymentRequestParams{
+		Direction: ctx.Query("direction"),
+		Status:    ctx.Query("status"),
+	}
+
+	resp, err := p.requestUC.GetUC(params, ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get payment requests", "01", "01")
+}
+
+func (p *paymentRequestDelivery) accept(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req paymentRequestDto.AcceptPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.Id = ctx.Param("id")
+
+	resp, err := p.requestUC.AcceptUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Payment request accepted", "01", "01")
+}
+
+func (p *paymentRequestDelivery) decline(ctx *gin.Context) {
+	if err := p.requestUC.DeclineUC(ctx.Param("id"), ctx.GetHeader("Authorization")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Payment request declined", "01", "01")
+}
+
+func (p *paymentRequestDelivery) cancel(ctx *gin.Context) {
+	if err := p.requestUC.CancelUC(ctx.Param("id"), ctx.GetHeader("Authorization")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Payment request cancelled", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, paymentRequest.ErrRequestNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, paymentRequest.ErrPayerNotFound),
+		errors.Is(err, paymentRequest.ErrSelfRequest),
+		errors.Is(err, paymentRequest.ErrInvalidDirection):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrInvalidPin):
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrPinLocked):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, limits.ErrPerTransactionLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "05")
+	case errors.Is(err, limits.ErrDailyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "06")
+	case errors.Is(err, limits.ErrMonthlyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "07")
+	case errors.Is(err, limits.ErrMaxBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "08")
+	case errors.Is(err, paymentRequest.ErrRequestNotPending),
+		errors.Is(err, paymentRequest.ErrRequestExpired):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseForbidden(ctx, err.Error(), "01", "01")
+	}
+}
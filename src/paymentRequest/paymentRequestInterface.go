@@ -0,0 +1,37 @@
+package paymentRequest
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/paymentRequestDto"
+	"final-project-enigma/pkg/money"
+)
+
+// MinAmount is the smallest amount that can be requested. It matches the
+// min=5 CreatePaymentRequest is bound with, and also holds for the shares a
+// split bill requests.
+const MinAmount money.Money = 5
+
+var (
+	ErrPayerNotFound     = errors.New("payer not found")
+	ErrSelfRequest       = errors.New("cannot request money from yourself")
+	ErrRequestNotFound   = errors.New("payment request not found")
+	ErrRequestNotPending = errors.New("payment request is no longer pending")
+	ErrRequestExpired    = errors.New("payment request has expired")
+	ErrInvalidDirection  = errors.New("direction must be incoming or outgoing")
+)
+
+type PaymentRequestRepository interface {
+	Create(req paymentRequestDto.CreatePaymentRequest) (paymentRequestDto.PaymentRequest, error)
+	GetByUser(params paymentRequestDto.GetPaymentRequestParams) ([]paymentRequestDto.PaymentRequest, error)
+	Accept(req paymentRequestDto.AcceptPaymentRequest) (paymentRequestDto.PaymentRequest, error)
+	Decline(id, payerId string) error
+	Cancel(id, requesterId string) error
+}
+
+type PaymentRequestUsecase interface {
+	CreateUC(req paymentRequestDto.CreatePaymentRequest, authHeader string) (paymentRequestDto.PaymentRequest, error)
+	GetUC(params paymentRequestDto.GetPaymentRequestParams, authHeader string) ([]paymentRequestDto.PaymentRequest, error)
+	AcceptUC(req paymentRequestDto.AcceptPaymentRequest, authHeader string) (paymentRequestDto.PaymentRequest, error)
+	DeclineUC(id, authHeader string) error
+	CancelUC(id, authHeader string) error
+}
@@ -0,0 +1,238 @@
+package paymentRequestRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/paymentRequestDto"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/dbtx"
+	paymentRequestDomain "final-project-enigma/src/paymentRequest"
+	"final-project-enigma/src/user/userRepository"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type paymentRequestRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRequestRepository(db *sql.DB) paymentRequestDomain.PaymentRequestRepository {
+	return &paymentRequestRepository{
+		db: db,
+	}
+}
+
+// Requests are never updated when they expire, so the status is derived from
+// expires_at whenever a pending request is read.
+const requestColumns = `
+	pr.id, pr.requester_id, r.fullname, r.phone_number, pr.payer_id, p.fullname, p.phone_number,
+	pr.amount, COALESCE(pr.note, ''),
+	CASE WHEN pr.status = 'pending' AND pr.expires_at <= $1 THEN 'expired' ELSE pr.status END,
+	COALESCE(pr.transaction_id::text, ''), COALESCE(pr.split_bill_id::text, ''), pr.expires_at, pr.responded_at, pr.created_at
+`
+
+const requestJoins = `
+	FROM payment_requests pr
+	JOIN users r ON r.id = pr.requester_id
+	JOIN users p ON p.id = pr.payer_id
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRequest(row rowScanner) (paymentRequestDto.PaymentRequest, error) {
+	var r paymentRequestDto.PaymentRequest
+	err := row.Scan(&r.Id, &r.RequesterId, &r.RequesterName, &r.RequesterPhone, &r.PayerId, &r.PayerName, &r.PayerPhone,
+		&r.Amount, &r.Note, &r.Status, &r.TransactionId, &r.SplitBillId, &r.ExpiresAt, &r.RespondedAt, &r.CreatedAt)
+	return r, err
+}
+
+// Create finds the payer by req.PayerType, or by phone number and then by
+// username when no type is given, since one user's username can be another
+// user's phone number.
+func (repo *paymentRequestRepository) Create(req paymentRequestDto.CreatePaymentRequest) (paymentRequestDto.PaymentRequest, error) {
+	var payerId string
+	payerQuery := `
+		SELECT id FROM users
+		WHERE ((phone_number = $1 AND $2 <> 'username') OR (username = $1 AND $2 <> 'phone'))
+			AND status = 'active' AND deleted_at IS NULL
+		ORDER BY phone_number = $1 DESC
+		LIMIT 1
+	`
+	err := repo.db.QueryRow(payerQuery, req.Payer, req.PayerType).Scan(&payerId)
+	if err == sql.ErrNoRows {
+		log.Error().Msg("payer not found")
+		return paymentRequestDto.PaymentRequest{}, paymentRequestDomain.ErrPayerNotFound
+	}
+	if err != nil {
+		return paymentRequestDto.PaymentRequest{}, err
+	}
+	if payerId == req.RequesterId {
+		log.Error().Msg("requester and payer cannot be the same")
+		return paymentRequestDto.PaymentRequest{}, paymentRequestDomain.ErrSelfRequest
+	}
+
+	var id string
+	insertQuery := `
+		INSERT INTO payment_requests (requester_id, payer_id, amount, note, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err = repo.db.QueryRow(insertQuery, req.RequesterId, payerId, req.Amount, req.Note, req.ExpiresAt, time.Now()).Scan(&id)
+	if err != nil {
+		log.Error().Msg("failed to create payment request: " + err.Error())
+		return paymentRequestDto.PaymentRequest{}, fmt.Errorf("failed to create payment request: %w", err)
+	}
+
+	return repo.getById(id)
+}
+
+func (repo *paymentRequestRepository) getById(id string) (paymentRequestDto.PaymentRequest, error) {
+	query := `SELECT ` + requestColumns + requestJoins + `WHERE pr.id = $2`
+	request, err := scanRequest(repo.db.QueryRow(query, time.Now(), id))
+	if err == sql.ErrNoRows {
+		return paymentRequestDto.PaymentRequest{}, paymentRequestDomain.ErrRequestNotFound
+	}
+	if err != nil {
+		return paymentRequestDto.PaymentRequest{}, fmt.Errorf("failed to get payment request: %w", err)
+	}
+	return request, nil
+}
+
+// GetByUser lists the requests the user received (incoming) or sent
+// (outgoing), newest first. Status filters on the derived status, so
+// "expired" and "pending" never overlap.
+func (repo *paymentRequestRepository) GetByUser(params paymentRequestDto.GetPaymentRequestParams) ([]paymentRequestDto.PaymentRequest, error) {
+	userColumn := "pr.payer_id"
+	if params.Direction == "outgoing" {
+		userColumn = "pr.requester_id"
+	}
+
+	query := `SELECT * FROM (SELECT ` + requestColumns + requestJoins + `WHERE ` + userColumn + ` = $2) requests`
+	args := []interface{}{time.Now(), params.UserId}
+	if params.Status != "" {
+		query += ` WHERE status = $3`
+		args = append(args, params.Status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment requests: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []paymentRequestDto.PaymentRequest{}
+	for rows.Next() {
+		request, err := scanRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment request: %w", err)
+		}
+		resp = append(resp, request)
+	}
+
+	return resp, rows.Err()
+}
+
+// Accept pays a pending request with a wallet transfer from the payer to the
+// requester. The request row stays locked until the transfer commits, so it
+// can only be paid once.
+func (repo *paymentRequestRepository) Accept(req paymentRequestDto.AcceptPaymentRequest) (paymentRequestDto.PaymentRequest, error) {
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var request paymentRequestDto.PaymentRequest
+		lockQuery := `
+			SELECT pr.status, pr.expires_at, pr.amount, COALESCE(pr.note, ''), r.phone_number
+			FROM payment_requests pr
+			JOIN users r ON r.id = pr.requester_id
+			WHERE pr.id = $1 AND pr.payer_id = $2
+			FOR UPDATE OF pr
+		`
+		err := tx.QueryRow(lockQuery, req.Id, req.PayerId).Scan(&request.Status, &request.ExpiresAt, &request.Amount, &request.Note, &request.RequesterPhone)
+		if err == sql.ErrNoRows {
+			return paymentRequestDomain.ErrRequestNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock payment request: %w", err)
+		}
+		if request.Status != paymentRequestDto.StatusPending {
+			return paymentRequestDomain.ErrRequestNotPending
+		}
+		if !request.ExpiresAt.After(time.Now()) {
+			return paymentRequestDomain.ErrRequestExpired
+		}
+
+		description := request.Note
+		if description == "" {
+			description = "Payment-Request"
+		}
+		transfer, err := userRepository.WalletTransfer(tx, userDto.WalletTransactionRequest{
+			UserId:               req.PayerId,
+			RecipientPhoneNumber: request.RequesterPhone,
+			Amount:               request.Amount,
+			Description:          description,
+		})
+		if err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE payment_requests
+			SET status = 'accepted', transaction_id = $1, responded_at = $2
+			WHERE id = $3
+		`
+		if _, err := tx.Exec(updateQuery, transfer.TransactionId, time.Now(), req.Id); err != nil {
+			return fmt.Errorf("failed to update payment request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Msg("failed to accept payment request " + req.Id + ": " + err.Error())
+		return paymentRequestDto.PaymentRequest{}, err
+	}
+
+	return repo.getById(req.Id)
+}
+
+func (repo *paymentRequestRepository) Decline(id, payerId string) error {
+	return repo.close(id, "payer_id", payerId, paymentRequestDto.StatusDeclined)
+}
+
+func (repo *paymentRequestRepository) Cancel(id, requesterId string) error {
+	return repo.close(id, "requester_id", requesterId, paymentRequestDto.StatusCancelled)
+}
+
+// close moves a pending, unexpired request owned by userId through userColumn
+// to status. When nothing is updated it looks the request up again to tell a
+// missing request from one that was already answered or has expired.
+func (repo *paymentRequestRepository) close(id, userColumn, userId, status string) error {
+	now := time.Now()
+	query := `
+		UPDATE payment_requests
+		SET status = $1, responded_at = $2
+		WHERE id = $3 AND ` + userColumn + ` = $4 AND status = 'pending' AND expires_at > $2
+	`
+	result, err := repo.db.Exec(query, status, now, id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to update payment request: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return nil
+	}
+
+	var current string
+	var expiresAt time.Time
+	lookupQuery := `SELECT status, expires_at FROM payment_requests WHERE id = $1 AND ` + userColumn + ` = $2`
+	err = repo.db.QueryRow(lookupQuery, id, userId).Scan(&current, &expiresAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return paymentRequestDomain.ErrRequestNotFound
+	case err != nil:
+		return fmt.Errorf("failed to get payment request: %w", err)
+	case current == paymentRequestDto.StatusPending && !expiresAt.After(now):
+		return paymentRequestDomain.ErrRequestExpired
+	default:
+		return paymentRequestDomain.ErrRequestNotPending
+	}
+}
@@ -0,0 +1,81 @@
+package paymentRequestRepository
+
+import (
+	"final-project-enigma/model/dto/paymentRequestDto"
+	"final-project-enigma/src/paymentRequest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecline(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		expiresAt time.Time
+		found     bool
+		want      error
+	}{
+		{name: "already accepted", status: "accepted", expiresAt: time.Now().Add(time.Hour), found: true, want: paymentRequest.ErrRequestNotPending},
+		{name: "expired", status: "pending", expiresAt: time.Now().Add(-time.Hour), found: true, want: paymentRequest.ErrRequestExpired},
+		{name: "not found", want: paymentRequest.ErrRequestNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := NewPaymentRequestRepository(db)
+
+			mock.ExpectExec("UPDATE payment_requests").
+				WithArgs("declined", sqlmock.AnyArg(), "req1", "payer1").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			rows := sqlmock.NewRows([]string{"status", "expires_at"})
+			if tt.found {
+				rows.AddRow(tt.status, tt.expiresAt)
+			}
+			mock.ExpectQuery("SELECT status, expires_at FROM payment_requests").
+				WithArgs("req1", "payer1").
+				WillReturnRows(rows)
+
+			assert.ErrorIs(t, repo.Decline("req1", "payer1"), tt.want)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		repo := NewPaymentRequestRepository(db)
+
+		mock.ExpectExec("UPDATE payment_requests").
+			WithArgs("declined", sqlmock.AnyArg(), "req1", "payer1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Decline("req1", "payer1"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAcceptExpired(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := NewPaymentRequestRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pr.status, pr.expires_at, pr.amount").
+		WithArgs("req1", "payer1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "expires_at", "amount", "note", "phone_number"}).
+			AddRow("pending", time.Now().Add(-time.Minute), "50000.00", "", "08123"))
+	mock.ExpectRollback()
+
+	_, err := repo.Accept(paymentRequestDto.AcceptPaymentRequest{Id: "req1", PayerId: "payer1"})
+
+	assert.ErrorIs(t, err, paymentRequest.ErrRequestExpired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,94 @@
+package paymentRequestUsecase
+
+import (
+	"final-project-enigma/model/dto/paymentRequestDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/src/paymentRequest"
+	"os"
+	"time"
+)
+
+const defaultTTL = 72 * time.Hour
+
+type paymentRequestUC struct {
+	requestRepo  paymentRequest.PaymentRequestRepository
+	txAuthorizer txauth.Authorizer
+	ttl          time.Duration
+}
+
+func NewPaymentRequestUsecase(requestRepo paymentRequest.PaymentRequestRepository, txAuthorizer txauth.Authorizer) paymentRequest.PaymentRequestUsecase {
+	return &paymentRequestUC{
+		requestRepo:  requestRepo,
+		txAuthorizer: txAuthorizer,
+		ttl:          TTL(),
+	}
+}
+
+// TTL reads PAYMENT_REQUEST_TTL, how long a request can be accepted after it
+// is created.
+func TTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("PAYMENT_REQUEST_TTL"))
+	if err != nil || ttl <= 0 {
+		return defaultTTL
+	}
+	return ttl
+}
+
+func (usecase *paymentRequestUC) CreateUC(req paymentRequestDto.CreatePaymentRequest, authHeader string) (paymentRequestDto.PaymentRequest, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return paymentRequestDto.PaymentRequest{}, err
+	}
+	req.RequesterId = userId
+	req.ExpiresAt = time.Now().Add(usecase.ttl)
+
+	return usecase.requestRepo.Create(req)
+}
+
+func (usecase *paymentRequestUC) GetUC(params paymentRequestDto.GetPaymentRequestParams, authHeader string) ([]paymentRequestDto.PaymentRequest, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	params.UserId = userId
+
+	if params.Direction == "" {
+		params.Direction = "incoming"
+	}
+	if params.Direction != "incoming" && params.Direction != "outgoing" {
+		return nil, paymentRequest.ErrInvalidDirection
+	}
+
+	return usecase.requestRepo.GetByUser(params)
+}
+
+func (usecase *paymentRequestUC) AcceptUC(req paymentRequestDto.AcceptPaymentRequest, authHeader string) (paymentRequestDto.PaymentRequest, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return paymentRequestDto.PaymentRequest{}, err
+	}
+	req.PayerId = userId
+
+	if err := usecase.txAuthorizer.Authorize(req.PayerId, req.PIN); err != nil {
+		return paymentRequestDto.PaymentRequest{}, err
+	}
+
+	return usecase.requestRepo.Accept(req)
+}
+
+func (usecase *paymentRequestUC) DeclineUC(id, authHeader string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.requestRepo.Decline(id, userId)
+}
+
+func (usecase *paymentRequestUC) CancelUC(id, authHeader string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.requestRepo.Cancel(id, userId)
+}
@@ -0,0 +1,122 @@
+package pocketDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/pocketDto"
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/pocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pocketDelivery struct {
+	pocketUC    pocket.PocketUsecase
+	idempotency gin.HandlerFunc
+}
+
+func NewPocketDelivery(v1Group *gin.RouterGroup, pocketUC pocket.PocketUsecase, idempotencyStore idempotency.Store) {
+	handler := pocketDelivery{
+		pocketUC:    pocketUC,
+		idempotency: middleware.Idempotency(idempotencyStore, middleware.IdempotencyRetention()),
+	}
+
+	pocketGroup := v1Group.Group("/user/pockets")
+	{
+		pocketGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.create)
+		pocketGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getAll)
+		pocketGroup.POST("/transfer", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.transfer)
+		pocketGroup.DELETE("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.close)
+	}
+}
+
+func (p *pocketDelivery) create(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req pocketDto.CreatePocketRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := p.pocketUC.CreateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Pocket created", "01", "01")
+}
+
+func (p *pocketDelivery) getAll(ctx *gin.Context) {
+	resp, err := p.pocketUC.GetBalanceUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get pockets", "01", "01")
+}
+
+func (p *pocketDelivery) transfer(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req pocketDto.TransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := p.pocketUC.TransferUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Pocket transfer success", "01", "01")
+}
+
+func (p *pocketDelivery) close(ctx *gin.Context) {
+	if err := p.pocketUC.CloseUC(ctx.Param("id"), ctx.GetHeader("Authorization")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Pocket closed", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pocket.ErrInvalidPocketName):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "name", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, pocket.ErrSamePocket):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "toPocketId", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, pocket.ErrPocketNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, pocket.ErrMainPocket):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, pocket.ErrPocketNotEmpty):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, ledger.ErrCurrencyMismatch):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "toPocketId", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, ledger.ErrInsufficientBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "08")
+	case errors.Is(err, ledger.ErrWalletFrozen):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "10")
+	case errors.Is(err, pocket.ErrDuplicatePocketName):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,29 @@
+package pocket
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/pocketDto"
+)
+
+var (
+	ErrPocketNotFound      = errors.New("pocket not found")
+	ErrInvalidPocketName   = errors.New("pocket name must not be blank")
+	ErrDuplicatePocketName = errors.New("a pocket with this name already exists")
+	ErrPocketNotEmpty      = errors.New("pocket must be empty before it can be closed")
+	ErrMainPocket          = errors.New("the main pocket cannot be closed")
+	ErrSamePocket          = errors.New("source and destination pockets must be different")
+)
+
+type PocketRepository interface {
+	Create(req pocketDto.CreatePocketRequest) (pocketDto.Pocket, error)
+	GetByUser(userId string) ([]pocketDto.Pocket, error)
+	Transfer(req pocketDto.TransferRequest) (pocketDto.TransferResponse, error)
+	Close(userId, id string) error
+}
+
+type PocketUsecase interface {
+	CreateUC(req pocketDto.CreatePocketRequest, authHeader string) (pocketDto.Pocket, error)
+	GetBalanceUC(authHeader string) (pocketDto.Balance, error)
+	TransferUC(req pocketDto.TransferRequest, authHeader string) (pocketDto.TransferResponse, error)
+	CloseUC(id, authHeader string) error
+}
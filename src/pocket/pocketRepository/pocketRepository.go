@@ -0,0 +1,206 @@
+package pocketRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/pocketDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/ledger"
+	pocketDomain "final-project-enigma/src/pocket"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+type pocketRepository struct {
+	db *sql.DB
+}
+
+func NewPocketRepository(db *sql.DB) pocketDomain.PocketRepository {
+	return &pocketRepository{
+		db: db,
+	}
+}
+
+func (repo *pocketRepository) Create(req pocketDto.CreatePocketRequest) (pocketDto.Pocket, error) {
+	resp := pocketDto.Pocket{Name: req.Name, Currency: req.Currency}
+	query := `
+		INSERT INTO wallets (user_id, name, is_main, currency, created_at, updated_at)
+		VALUES ($1, $2, FALSE, $3, $4, $4)
+		RETURNING id, created_at
+	`
+	err := repo.db.QueryRow(query, req.UserId, req.Name, req.Currency, time.Now()).Scan(&resp.Id, &resp.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return pocketDto.Pocket{}, pocketDomain.ErrDuplicatePocketName
+	}
+	if err != nil {
+		log.Error().Msg("failed to create pocket: " + err.Error())
+		return pocketDto.Pocket{}, fmt.Errorf("failed to create pocket: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetByUser lists the user's open pockets, main pocket first.
+func (repo *pocketRepository) GetByUser(userId string) ([]pocketDto.Pocket, error) {
+	query := `
+		SELECT id, name, is_main, currency, balance, created_at
+		FROM wallets
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY is_main DESC, created_at
+	`
+	rows, err := repo.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pockets: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []pocketDto.Pocket{}
+	for rows.Next() {
+		var p pocketDto.Pocket
+		if err := rows.Scan(&p.Id, &p.Name, &p.IsMain, &p.Currency, &p.Balance, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pocket: %w", err)
+		}
+		resp = append(resp, p)
+	}
+
+	return resp, rows.Err()
+}
+
+// Transfer moves money between two of the user's own pockets. It is recorded
+// like a wallet transfer without a fee, so it shows up in the history, but it
+// is not checked against the transfer limits since the money never leaves
+// the user. Both pockets must hold the same currency; moving money between
+// currencies is a conversion.
+func (repo *pocketRepository) Transfer(req pocketDto.TransferRequest) (pocketDto.TransferResponse, error) {
+	resp := pocketDto.TransferResponse{
+		FromPocketId: req.FromPocketId,
+		ToPocketId:   req.ToPocketId,
+		Amount:       req.Amount,
+	}
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		currencies, err := PocketCurrencies(tx, req.UserId, req.FromPocketId, req.ToPocketId)
+		if err != nil {
+			return err
+		}
+		currency := currencies[req.FromPocketId]
+		if currencies[req.ToPocketId] != currency {
+			return ledger.ErrCurrencyMismatch
+		}
+
+		balances, err := ledger.LockWallets(tx, req.FromPocketId, req.ToPocketId)
+		if errors.Is(err, ledger.ErrWalletNotFound) {
+			return pocketDomain.ErrPocketNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if balances[req.FromPocketId] < req.Amount {
+			log.Error().Msg("insufficient balance")
+			return ledger.ErrInsufficientBalance
+		}
+
+		currentTime := time.Now()
+		transactionQuery := `
+			INSERT INTO transactions (user_id, transaction_type, currency, amount, fee, description, created_at, status)
+			VALUES ($1, 'debit', $2, $3, 0, 'Pocket Transfer', $4, 'success')
+			RETURNING id
+		`
+		if err := tx.QueryRow(transactionQuery, req.UserId, currency, req.Amount, currentTime).Scan(&resp.TransactionId); err != nil {
+			return err
+		}
+
+		walletTransactionQuery := `
+			INSERT INTO wallet_transactions (transaction_id, from_wallet_id, to_wallet_id, created_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		if _, err := tx.Exec(walletTransactionQuery, resp.TransactionId, req.FromPocketId, req.ToPocketId, currentTime); err != nil {
+			return err
+		}
+
+		return ledger.Post(tx, resp.TransactionId,
+			ledger.Debit(ledger.AccountWallet, req.FromPocketId, req.Amount),
+			ledger.Credit(ledger.AccountWallet, req.ToPocketId, req.Amount),
+		)
+	})
+	if err != nil {
+		return pocketDto.TransferResponse{}, err
+	}
+
+	return resp, nil
+}
+
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// PocketCurrencies returns the currency of each of the given open pockets of
+// the user, or ErrPocketNotFound when any of them is not one. q may be a
+// *sql.DB or a *sql.Tx.
+func PocketCurrencies(q querier, userId string, ids ...string) (map[string]string, error) {
+	query := `SELECT id, currency FROM wallets WHERE id::text = ANY($1) AND user_id = $2 AND deleted_at IS NULL`
+	rows, err := q.Query(query, pq.Array(ids), userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pockets: %w", err)
+	}
+	defer rows.Close()
+
+	currencies := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, currency string
+		if err := rows.Scan(&id, &currency); err != nil {
+			return nil, fmt.Errorf("failed to scan pocket: %w", err)
+		}
+		currencies[id] = currency
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := currencies[id]; !ok {
+			return nil, pocketDomain.ErrPocketNotFound
+		}
+	}
+
+	return currencies, nil
+}
+
+// Close hides an empty pocket. The main pocket stays open for as long as the
+// user exists.
+func (repo *pocketRepository) Close(userId, id string) error {
+	return dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var walletId string
+		var isMain bool
+		query := `SELECT id, is_main FROM wallets WHERE id::text = $1 AND user_id = $2 AND deleted_at IS NULL`
+		err := tx.QueryRow(query, id, userId).Scan(&walletId, &isMain)
+		if err == sql.ErrNoRows {
+			return pocketDomain.ErrPocketNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get pocket: %w", err)
+		}
+		if isMain {
+			return pocketDomain.ErrMainPocket
+		}
+
+		balances, err := ledger.LockWallets(tx, walletId)
+		if errors.Is(err, ledger.ErrWalletNotFound) {
+			return pocketDomain.ErrPocketNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if balances[walletId] != 0 {
+			return pocketDomain.ErrPocketNotEmpty
+		}
+
+		currentTime := time.Now()
+		if _, err := tx.Exec(`UPDATE wallets SET deleted_at = $1, updated_at = $1 WHERE id = $2`, currentTime, walletId); err != nil {
+			return fmt.Errorf("failed to close pocket: %w", err)
+		}
+		return nil
+	})
+}
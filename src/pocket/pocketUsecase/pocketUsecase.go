@@ -0,0 +1,78 @@
+package pocketUsecase
+
+import (
+	"final-project-enigma/model/dto/pocketDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/pocket"
+	"strings"
+)
+
+type pocketUC struct {
+	pocketRepo pocket.PocketRepository
+}
+
+func NewPocketUsecase(pocketRepo pocket.PocketRepository) pocket.PocketUsecase {
+	return &pocketUC{
+		pocketRepo: pocketRepo,
+	}
+}
+
+func (usecase *pocketUC) CreateUC(req pocketDto.CreatePocketRequest, authHeader string) (pocketDto.Pocket, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return pocketDto.Pocket{}, err
+	}
+	req.UserId = userId
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return pocketDto.Pocket{}, pocket.ErrInvalidPocketName
+	}
+	if req.Currency == "" {
+		req.Currency = money.Currency
+	}
+
+	return usecase.pocketRepo.Create(req)
+}
+
+// GetBalanceUC returns every open pocket of the user along with their totals
+// per currency.
+func (usecase *pocketUC) GetBalanceUC(authHeader string) (pocketDto.Balance, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return pocketDto.Balance{}, err
+	}
+
+	pockets, err := usecase.pocketRepo.GetByUser(userId)
+	if err != nil {
+		return pocketDto.Balance{}, err
+	}
+
+	resp := pocketDto.Balance{Totals: map[string]money.Money{}, Pockets: pockets}
+	for _, p := range pockets {
+		resp.Totals[p.Currency] += p.Balance
+	}
+	return resp, nil
+}
+
+func (usecase *pocketUC) TransferUC(req pocketDto.TransferRequest, authHeader string) (pocketDto.TransferResponse, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return pocketDto.TransferResponse{}, err
+	}
+	req.UserId = userId
+
+	if req.FromPocketId == req.ToPocketId {
+		return pocketDto.TransferResponse{}, pocket.ErrSamePocket
+	}
+
+	return usecase.pocketRepo.Transfer(req)
+}
+
+func (usecase *pocketUC) CloseUC(id, authHeader string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.pocketRepo.Close(userId, id)
+}
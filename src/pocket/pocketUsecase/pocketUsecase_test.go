@@ -0,0 +1,88 @@
+package pocketUsecase_test
+
+import (
+	"final-project-enigma/model/dto/pocketDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/pocket"
+	"final-project-enigma/src/pocket/pocketUsecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPocketRepo struct {
+	pocket.PocketRepository
+	pockets     []pocketDto.Pocket
+	created     pocketDto.CreatePocketRequest
+	transferred bool
+}
+
+func (m *mockPocketRepo) Create(req pocketDto.CreatePocketRequest) (pocketDto.Pocket, error) {
+	m.created = req
+	return pocketDto.Pocket{Id: "p1", Name: req.Name}, nil
+}
+
+func (m *mockPocketRepo) GetByUser(userId string) ([]pocketDto.Pocket, error) {
+	return m.pockets, nil
+}
+
+func (m *mockPocketRepo) Transfer(req pocketDto.TransferRequest) (pocketDto.TransferResponse, error) {
+	m.transferred = true
+	return pocketDto.TransferResponse{TransactionId: "t1"}, nil
+}
+
+func authHeader(t *testing.T) string {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", "USER", 1)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestCreateUC(t *testing.T) {
+	repo := &mockPocketRepo{}
+	uc := pocketUsecase.NewPocketUsecase(repo)
+
+	resp, err := uc.CreateUC(pocketDto.CreatePocketRequest{Name: "  Holiday "}, authHeader(t))
+	assert.NoError(t, err)
+	assert.Equal(t, "Holiday", resp.Name)
+	assert.Equal(t, "user-1", repo.created.UserId)
+	assert.Equal(t, "IDR", repo.created.Currency)
+
+	_, err = uc.CreateUC(pocketDto.CreatePocketRequest{Name: "   "}, authHeader(t))
+	assert.ErrorIs(t, err, pocket.ErrInvalidPocketName)
+}
+
+func TestGetBalanceUC(t *testing.T) {
+	repo := &mockPocketRepo{pockets: []pocketDto.Pocket{
+		{Id: "w1", Name: "Main", IsMain: true, Currency: "IDR", Balance: money.Money(150000)},
+		{Id: "w2", Name: "Holiday", Currency: "IDR", Balance: money.Money(50000)},
+		{Id: "w3", Name: "Trip", Currency: "USD", Balance: money.Money(1250)},
+	}}
+	uc := pocketUsecase.NewPocketUsecase(repo)
+
+	resp, err := uc.GetBalanceUC(authHeader(t))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]money.Money{"IDR": 200000, "USD": 1250}, resp.Totals)
+	assert.Len(t, resp.Pockets, 3)
+}
+
+func TestTransferUC(t *testing.T) {
+	t.Run("same pocket is rejected", func(t *testing.T) {
+		repo := &mockPocketRepo{}
+		uc := pocketUsecase.NewPocketUsecase(repo)
+
+		_, err := uc.TransferUC(pocketDto.TransferRequest{FromPocketId: "w1", ToPocketId: "w1", Amount: 1000}, authHeader(t))
+		assert.ErrorIs(t, err, pocket.ErrSamePocket)
+		assert.False(t, repo.transferred)
+	})
+
+	t.Run("different pockets", func(t *testing.T) {
+		repo := &mockPocketRepo{}
+		uc := pocketUsecase.NewPocketUsecase(repo)
+
+		resp, err := uc.TransferUC(pocketDto.TransferRequest{FromPocketId: "w1", ToPocketId: "w2", Amount: 1000}, authHeader(t))
+		assert.NoError(t, err)
+		assert.Equal(t, "t1", resp.TransactionId)
+		assert.True(t, repo.transferred)
+	})
+}
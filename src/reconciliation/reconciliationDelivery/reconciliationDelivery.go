@@ -0,0 +1,116 @@
+package reconciliationDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/reconciliationDto"
+	"final-project-enigma/pkg/gatewayReport"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/reconciliation"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reconciliationDelivery struct {
+	reconciliationUC reconciliation.ReconciliationUsecase
+}
+
+func NewReconciliationDelivery(v1Group *gin.RouterGroup, reconciliationUC reconciliation.ReconciliationUsecase) {
+	handler := reconciliationDelivery{
+		reconciliationUC: reconciliationUC,
+	}
+
+	adminGroup := v1Group.Group("/admin/reconciliations")
+	{
+		adminGroup.POST("", middleware.JwtAuthWithRoles("ADMIN"), handler.createRun)
+		adminGroup.GET("", middleware.JwtAuthWithRoles("ADMIN"), handler.getRuns)
+		adminGroup.GET("/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.getRun)
+		adminGroup.POST("/:id/items/:itemId/resolve", middleware.JwtAuthWithRoles("ADMIN"), handler.resolveItem)
+	}
+}
+
+// createRun takes the settlement report as the multipart field "file" and
+// its business date as the form field "date".
+func (r *reconciliationDelivery) createRun(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "file", Message: "settlement report file is required"}}, "bad request", "01", "02")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		json.NewResponseError(ctx, "failed to open file", "01", "01")
+		return
+	}
+	defer file.Close()
+
+	resp, err := r.reconciliationUC.CreateRunUC(fileHeader.Filename, file, ctx.PostForm("date"), ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Reconciliation run created", "01", "01")
+}
+
+func (r *reconciliationDelivery) getRuns(ctx *gin.Context) {
+	params := reconciliationDto.GetRunParams{
+		Page:  ctx.Query("page"),
+		Limit: ctx.Query("size"),
+	}
+
+	resp, totalData, err := r.reconciliationUC.GetRunsUC(params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSuccesPaging(ctx, resp, "Success get reconciliation runs", "01", "01", ctx.DefaultQuery("page", "1"), totalData)
+}
+
+func (r *reconciliationDelivery) getRun(ctx *gin.Context) {
+	resp, err := r.reconciliationUC.GetRunUC(ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get reconciliation run", "01", "01")
+}
+
+func (r *reconciliationDelivery) resolveItem(ctx *gin.Context) {
+	var req reconciliationDto.ResolveItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := r.reconciliationUC.ResolveItemUC(ctx.Param("id"), ctx.Param("itemId"), ctx.GetHeader("Authorization"), req)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Reconciliation item resolved", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, reconciliation.ErrRunNotFound), errors.Is(err, reconciliation.ErrItemNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, reconciliation.ErrInvalidReportDate):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "date", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, gatewayReport.ErrMissingColumn), errors.Is(err, gatewayReport.ErrInvalidLine), errors.Is(err, gatewayReport.ErrEmptyReport):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "file", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, reconciliation.ErrItemResolved):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,31 @@
+package reconciliation
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/reconciliationDto"
+	"io"
+	"time"
+)
+
+var (
+	ErrRunNotFound       = errors.New("reconciliation run not found")
+	ErrItemNotFound      = errors.New("reconciliation item not found")
+	ErrItemResolved      = errors.New("reconciliation item is already resolved")
+	ErrInvalidReportDate = errors.New("report date must be formatted as YYYY-MM-DD")
+)
+
+type ReconciliationRepository interface {
+	GetTopUps(orderIds []string) ([]reconciliationDto.TopUp, error)
+	GetSettledTopUps(from, to time.Time) ([]reconciliationDto.TopUp, error)
+	CreateRun(run reconciliationDto.Run) (reconciliationDto.Run, error)
+	GetRuns(params reconciliationDto.GetRunParams) ([]reconciliationDto.Run, int, error)
+	GetRun(id string) (reconciliationDto.Run, error)
+	ResolveItem(runId, itemId, adminId, note string) (reconciliationDto.Item, error)
+}
+
+type ReconciliationUsecase interface {
+	CreateRunUC(fileName string, report io.Reader, reportDate, authHeader string) (reconciliationDto.Run, error)
+	GetRunsUC(params reconciliationDto.GetRunParams) ([]reconciliationDto.Run, string, error)
+	GetRunUC(id string) (reconciliationDto.Run, error)
+	ResolveItemUC(runId, itemId, authHeader string, req reconciliationDto.ResolveItemRequest) (reconciliationDto.Item, error)
+}
@@ -0,0 +1,225 @@
+package reconciliationRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/reconciliationDto"
+	"final-project-enigma/pkg/dbtx"
+	reconciliationDomain "final-project-enigma/src/reconciliation"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type reconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) reconciliationDomain.ReconciliationRepository {
+	return &reconciliationRepository{
+		db: db,
+	}
+}
+
+func scanTopUps(rows *sql.Rows) ([]reconciliationDto.TopUp, error) {
+	defer rows.Close()
+
+	var resp []reconciliationDto.TopUp
+	for rows.Next() {
+		var topUp reconciliationDto.TopUp
+		if err := rows.Scan(&topUp.TransactionId, &topUp.Amount, &topUp.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan top up: %w", err)
+		}
+		resp = append(resp, topUp)
+	}
+	return resp, rows.Err()
+}
+
+// GetTopUps returns the top-ups among the given order ids. Order ids that
+// are not top-up transactions, including ones that are not even UUIDs, are
+// left out.
+func (repo *reconciliationRepository) GetTopUps(orderIds []string) ([]reconciliationDto.TopUp, error) {
+	query := `
+		SELECT t.id, t.amount + t.fee, t.status
+		FROM topup_transactions tt
+		JOIN transactions t ON t.id = tt.transaction_id
+		WHERE t.id::text = ANY($1)
+	`
+	rows, err := repo.db.Query(query, pq.Array(orderIds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top ups: %w", err)
+	}
+	return scanTopUps(rows)
+}
+
+// GetSettledTopUps returns the successful top-ups settled in [from, to). A
+// top-up created just before midnight and paid after it belongs to the
+// settlement report of the day it was paid.
+func (repo *reconciliationRepository) GetSettledTopUps(from, to time.Time) ([]reconciliationDto.TopUp, error) {
+	query := `
+		SELECT t.id, t.amount + t.fee, t.status
+		FROM topup_transactions tt
+		JOIN transactions t ON t.id = tt.transaction_id
+		WHERE t.status = 'success' AND tt.settled_at >= $1 AND tt.settled_at < $2
+	`
+	rows, err := repo.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settled top ups: %w", err)
+	}
+	return scanTopUps(rows)
+}
+
+func (repo *reconciliationRepository) CreateRun(run reconciliationDto.Run) (reconciliationDto.Run, error) {
+	var runId string
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		runQuery := `
+			INSERT INTO reconciliation_runs (file_name, report_date, total_lines, matched_count, issue_count, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`
+		err := tx.QueryRow(runQuery, run.FileName, run.ReportDate, run.TotalLines, run.MatchedCount, len(run.Items), run.CreatedBy, time.Now()).Scan(&runId)
+		if err != nil {
+			return fmt.Errorf("failed to create reconciliation run: %w", err)
+		}
+
+		itemQuery := `
+			INSERT INTO reconciliation_items (run_id, order_id, issue_type, report_amount, local_amount, report_status, local_status)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+		`
+		for _, item := range run.Items {
+			_, err := tx.Exec(itemQuery, runId, item.OrderId, item.IssueType, item.ReportAmount, item.LocalAmount, item.ReportStatus, item.LocalStatus)
+			if err != nil {
+				return fmt.Errorf("failed to create reconciliation item: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return reconciliationDto.Run{}, err
+	}
+
+	return repo.GetRun(runId)
+}
+
+const runColumns = `
+	r.id, r.file_name, to_char(r.report_date, 'YYYY-MM-DD'), r.total_lines, r.matched_count, r.issue_count,
+	(SELECT COUNT(*) FROM reconciliation_items i WHERE i.run_id = r.id AND i.resolved_at IS NULL),
+	r.created_by, r.created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRun(row rowScanner) (reconciliationDto.Run, error) {
+	var r reconciliationDto.Run
+	err := row.Scan(&r.Id, &r.FileName, &r.ReportDate, &r.TotalLines, &r.MatchedCount, &r.IssueCount,
+		&r.OpenCount, &r.CreatedBy, &r.CreatedAt)
+	return r, err
+}
+
+const itemColumns = `
+	id, order_id, issue_type, report_amount, local_amount, COALESCE(report_status, ''), COALESCE(local_status, ''),
+	COALESCE(resolved_by::text, ''), resolved_at, COALESCE(resolution_note, '')
+`
+
+func scanItem(row rowScanner) (reconciliationDto.Item, error) {
+	var i reconciliationDto.Item
+	err := row.Scan(&i.Id, &i.OrderId, &i.IssueType, &i.ReportAmount, &i.LocalAmount, &i.ReportStatus, &i.LocalStatus,
+		&i.ResolvedBy, &i.ResolvedAt, &i.ResolutionNote)
+	return i, err
+}
+
+func (repo *reconciliationRepository) GetRuns(params reconciliationDto.GetRunParams) ([]reconciliationDto.Run, int, error) {
+	var totalData int
+	if err := repo.db.QueryRow(`SELECT COUNT(*) FROM reconciliation_runs`).Scan(&totalData); err != nil {
+		return nil, 0, fmt.Errorf("failed to count reconciliation runs: %w", err)
+	}
+
+	query := `SELECT ` + runColumns + `
+		FROM reconciliation_runs r
+		ORDER BY r.report_date DESC, r.created_at DESC
+	`
+	if params.Page != "" && params.Limit != "" {
+		page, _ := strconv.Atoi(params.Page)
+		limit, _ := strconv.Atoi(params.Limit)
+		offset := (page - 1) * limit
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get reconciliation runs: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []reconciliationDto.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan reconciliation run: %w", err)
+		}
+		resp = append(resp, run)
+	}
+	return resp, totalData, rows.Err()
+}
+
+// GetRun returns a run with all of its items, unresolved ones first.
+func (repo *reconciliationRepository) GetRun(id string) (reconciliationDto.Run, error) {
+	query := `SELECT ` + runColumns + ` FROM reconciliation_runs r WHERE r.id::text = $1`
+	run, err := scanRun(repo.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return reconciliationDto.Run{}, reconciliationDomain.ErrRunNotFound
+	}
+	if err != nil {
+		return reconciliationDto.Run{}, fmt.Errorf("failed to get reconciliation run: %w", err)
+	}
+
+	itemQuery := `SELECT ` + itemColumns + `
+		FROM reconciliation_items
+		WHERE run_id = $1
+		ORDER BY resolved_at IS NOT NULL, issue_type, order_id
+	`
+	rows, err := repo.db.Query(itemQuery, run.Id)
+	if err != nil {
+		return reconciliationDto.Run{}, fmt.Errorf("failed to get reconciliation items: %w", err)
+	}
+	defer rows.Close()
+
+	run.Items = []reconciliationDto.Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return reconciliationDto.Run{}, fmt.Errorf("failed to scan reconciliation item: %w", err)
+		}
+		run.Items = append(run.Items, item)
+	}
+	return run, rows.Err()
+}
+
+func (repo *reconciliationRepository) ResolveItem(runId, itemId, adminId, note string) (reconciliationDto.Item, error) {
+	query := `
+		UPDATE reconciliation_items
+		SET resolved_by = $3, resolved_at = $4, resolution_note = $5
+		WHERE run_id::text = $1 AND id::text = $2 AND resolved_at IS NULL
+		RETURNING ` + itemColumns
+	item, err := scanItem(repo.db.QueryRow(query, runId, itemId, adminId, time.Now(), note))
+	if err == nil {
+		return item, nil
+	}
+	if err != sql.ErrNoRows {
+		return reconciliationDto.Item{}, fmt.Errorf("failed to resolve reconciliation item: %w", err)
+	}
+
+	var resolved bool
+	existsQuery := `SELECT resolved_at IS NOT NULL FROM reconciliation_items WHERE run_id::text = $1 AND id::text = $2`
+	err = repo.db.QueryRow(existsQuery, runId, itemId).Scan(&resolved)
+	if err == sql.ErrNoRows {
+		return reconciliationDto.Item{}, reconciliationDomain.ErrItemNotFound
+	}
+	if err != nil {
+		return reconciliationDto.Item{}, fmt.Errorf("failed to get reconciliation item: %w", err)
+	}
+	return reconciliationDto.Item{}, reconciliationDomain.ErrItemResolved
+}
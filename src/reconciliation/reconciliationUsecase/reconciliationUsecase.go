@@ -0,0 +1,171 @@
+package reconciliationUsecase
+
+import (
+	"final-project-enigma/model/dto/reconciliationDto"
+	"final-project-enigma/pkg/gatewayReport"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/reconciliation"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPage     = "1"
+	defaultPageSize = "10"
+)
+
+type reconciliationUC struct {
+	reconciliationRepo reconciliation.ReconciliationRepository
+}
+
+func NewReconciliationUsecase(reconciliationRepo reconciliation.ReconciliationRepository) reconciliation.ReconciliationUsecase {
+	return &reconciliationUC{
+		reconciliationRepo: reconciliationRepo,
+	}
+}
+
+// CreateRunUC parses a gateway settlement report for the given business
+// date, matches it against local top-ups and stores the result as a run.
+func (usecase *reconciliationUC) CreateRunUC(fileName string, report io.Reader, reportDate, authHeader string) (reconciliationDto.Run, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return reconciliationDto.Run{}, err
+	}
+	from, err := time.ParseInLocation("2006-01-02", reportDate, time.Local)
+	if err != nil {
+		return reconciliationDto.Run{}, reconciliation.ErrInvalidReportDate
+	}
+
+	lines, err := gatewayReport.Parse(report)
+	if err != nil {
+		return reconciliationDto.Run{}, err
+	}
+
+	orderIds := make([]string, 0, len(lines))
+	for _, line := range lines {
+		orderIds = append(orderIds, line.OrderId)
+	}
+	locals, err := usecase.reconciliationRepo.GetTopUps(orderIds)
+	if err != nil {
+		return reconciliationDto.Run{}, err
+	}
+	settled, err := usecase.reconciliationRepo.GetSettledTopUps(from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return reconciliationDto.Run{}, err
+	}
+
+	matched, items := Reconcile(lines, locals, settled)
+	return usecase.reconciliationRepo.CreateRun(reconciliationDto.Run{
+		FileName:     fileName,
+		ReportDate:   reportDate,
+		TotalLines:   len(lines),
+		MatchedCount: matched,
+		CreatedBy:    adminId,
+		Items:        items,
+	})
+}
+
+// localStatus maps a gateway transaction status to the status the top-up
+// should have locally, following how payment notifications are applied.
+func localStatus(gatewayStatus string) string {
+	switch gatewayStatus {
+	case "settlement", "capture":
+		return "success"
+	case "cancel", "expire":
+		return "cancel"
+	default:
+		return gatewayStatus
+	}
+}
+
+// Reconcile matches report lines against the local top-ups they refer to
+// and returns how many lines matched along with an item per discrepancy.
+// settled are the top-ups that succeeded locally on the report date; any of
+// them absent from the report is flagged as well. A line with both a wrong
+// amount and a wrong status is reported as an amount mismatch.
+func Reconcile(lines []gatewayReport.Line, locals, settled []reconciliationDto.TopUp) (int, []reconciliationDto.Item) {
+	byId := make(map[string]reconciliationDto.TopUp, len(locals))
+	for _, topUp := range locals {
+		byId[topUp.TransactionId] = topUp
+	}
+
+	matched := 0
+	reported := make(map[string]bool, len(lines))
+	items := []reconciliationDto.Item{}
+	for _, line := range lines {
+		reported[line.OrderId] = true
+		reportAmount := line.GrossAmount
+		item := reconciliationDto.Item{
+			OrderId:      line.OrderId,
+			ReportAmount: &reportAmount,
+			ReportStatus: line.Status,
+		}
+
+		local, found := byId[line.OrderId]
+		if !found {
+			item.IssueType = reconciliationDto.IssueMissingLocally
+			items = append(items, item)
+			continue
+		}
+		localAmount := local.Amount
+		item.LocalAmount = &localAmount
+		item.LocalStatus = local.Status
+
+		switch {
+		case local.Amount != line.GrossAmount:
+			item.IssueType = reconciliationDto.IssueAmountMismatch
+		case local.Status != localStatus(line.Status):
+			item.IssueType = reconciliationDto.IssueStatusMismatch
+		default:
+			matched++
+			continue
+		}
+		items = append(items, item)
+	}
+
+	var missing []reconciliationDto.Item
+	for _, topUp := range settled {
+		if reported[topUp.TransactionId] {
+			continue
+		}
+		localAmount := topUp.Amount
+		missing = append(missing, reconciliationDto.Item{
+			OrderId:     topUp.TransactionId,
+			IssueType:   reconciliationDto.IssueMissingInReport,
+			LocalAmount: &localAmount,
+			LocalStatus: topUp.Status,
+		})
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].OrderId < missing[j].OrderId })
+
+	return matched, append(items, missing...)
+}
+
+func (usecase *reconciliationUC) GetRunsUC(params reconciliationDto.GetRunParams) ([]reconciliationDto.Run, string, error) {
+	if page, err := strconv.Atoi(params.Page); err != nil || page < 1 {
+		params.Page = defaultPage
+	}
+	if limit, err := strconv.Atoi(params.Limit); err != nil || limit < 1 {
+		params.Limit = defaultPageSize
+	}
+
+	resp, totalData, err := usecase.reconciliationRepo.GetRuns(params)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, strconv.Itoa(totalData), nil
+}
+
+func (usecase *reconciliationUC) GetRunUC(id string) (reconciliationDto.Run, error) {
+	return usecase.reconciliationRepo.GetRun(id)
+}
+
+func (usecase *reconciliationUC) ResolveItemUC(runId, itemId, authHeader string, req reconciliationDto.ResolveItemRequest) (reconciliationDto.Item, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return reconciliationDto.Item{}, err
+	}
+	return usecase.reconciliationRepo.ResolveItem(runId, itemId, adminId, req.Note)
+}
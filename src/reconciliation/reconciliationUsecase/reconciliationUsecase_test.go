@@ -0,0 +1,91 @@
+package reconciliationUsecase_test
+
+import (
+	"final-project-enigma/model/dto/reconciliationDto"
+	"final-project-enigma/pkg/gatewayReport"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/reconciliation"
+	"final-project-enigma/src/reconciliation/reconciliationUsecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	lines := []gatewayReport.Line{
+		{Number: 2, OrderId: "t1", GrossAmount: 101000, Status: "settlement"},
+		{Number: 3, OrderId: "t2", GrossAmount: 50000, Status: "settlement"},
+		{Number: 4, OrderId: "t3", GrossAmount: 20000, Status: "expire"},
+		{Number: 5, OrderId: "t4", GrossAmount: 30000, Status: "settlement"},
+		{Number: 6, OrderId: "t5", GrossAmount: 40000, Status: "expire"},
+	}
+	locals := []reconciliationDto.TopUp{
+		{TransactionId: "t1", Amount: 101000, Status: "success"},
+		{TransactionId: "t2", Amount: 51000, Status: "success"},
+		{TransactionId: "t3", Amount: 20000, Status: "success"},
+		{TransactionId: "t5", Amount: 40000, Status: "cancel"},
+	}
+	settled := []reconciliationDto.TopUp{
+		{TransactionId: "t1", Amount: 101000, Status: "success"},
+		{TransactionId: "t6", Amount: 15000, Status: "success"},
+	}
+
+	matched, items := reconciliationUsecase.Reconcile(lines, locals, settled)
+	assert.Equal(t, 2, matched)
+
+	var issues []string
+	for _, item := range items {
+		issues = append(issues, item.OrderId+":"+item.IssueType)
+	}
+	assert.Equal(t, []string{
+		"t2:" + reconciliationDto.IssueAmountMismatch,
+		"t3:" + reconciliationDto.IssueStatusMismatch,
+		"t4:" + reconciliationDto.IssueMissingLocally,
+		"t6:" + reconciliationDto.IssueMissingInReport,
+	}, issues)
+
+	assert.Equal(t, "50000.00", items[0].ReportAmount.String())
+	assert.Equal(t, "51000.00", items[0].LocalAmount.String())
+	assert.Nil(t, items[2].LocalAmount)
+	assert.Nil(t, items[3].ReportAmount)
+}
+
+type mockReconciliationRepo struct {
+	reconciliation.ReconciliationRepository
+	from, to time.Time
+	run      reconciliationDto.Run
+}
+
+func (m *mockReconciliationRepo) GetTopUps(orderIds []string) ([]reconciliationDto.TopUp, error) {
+	return []reconciliationDto.TopUp{{TransactionId: "t1", Amount: 101000, Status: "success"}}, nil
+}
+
+func (m *mockReconciliationRepo) GetSettledTopUps(from, to time.Time) ([]reconciliationDto.TopUp, error) {
+	m.from, m.to = from, to
+	return nil, nil
+}
+
+func (m *mockReconciliationRepo) CreateRun(run reconciliationDto.Run) (reconciliationDto.Run, error) {
+	m.run = run
+	return run, nil
+}
+
+func TestCreateRunUC(t *testing.T) {
+	token, err := middleware.GenerateTokenJwt("admin-1", "admin", "ADMIN", 1)
+	assert.NoError(t, err)
+	repo := &mockReconciliationRepo{}
+	uc := reconciliationUsecase.NewReconciliationUsecase(repo)
+
+	report := "order_id,gross_amount,transaction_status\nt1,101000.00,settlement\n"
+	_, err = uc.CreateRunUC("settlement.csv", strings.NewReader(report), "2024-03-01", "Bearer "+token)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.run.TotalLines)
+	assert.Equal(t, 1, repo.run.MatchedCount)
+	assert.Equal(t, "admin-1", repo.run.CreatedBy)
+	assert.Equal(t, 24*time.Hour, repo.to.Sub(repo.from))
+
+	_, err = uc.CreateRunUC("settlement.csv", strings.NewReader(report), "01-03-2024", "Bearer "+token)
+	assert.ErrorIs(t, err, reconciliation.ErrInvalidReportDate)
+}
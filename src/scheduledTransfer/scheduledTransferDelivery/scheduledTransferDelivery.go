@@ -0,0 +1,124 @@
+package scheduledTransferDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/scheduledTransferDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/scheduledTransfer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scheduledTransferDelivery struct {
+	scheduleUC scheduledTransfer.ScheduledTransferUsecase
+}
+
+func NewScheduledTransferDelivery(v1Group *gin.RouterGroup, scheduleUC scheduledTransfer.ScheduledTransferUsecase) {
+	handler := scheduledTransferDelivery{
+		scheduleUC: scheduleUC,
+	}
+
+	scheduleGroup := v1Group.Group("/user/scheduled-transfers")
+	{
+		scheduleGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.create)
+		scheduleGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getAll)
+		scheduleGroup.GET("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getById)
+		scheduleGroup.PUT("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.update)
+		scheduleGroup.DELETE("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.cancel)
+	}
+}
+
+func (s *scheduledTransferDelivery) create(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req scheduledTransferDto.CreateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := s.scheduleUC.CreateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Scheduled transfer created", "01", "01")
+}
+
+func (s *scheduledTransferDelivery) getAll(ctx *gin.Context) {
+	resp, err := s.scheduleUC.GetAllUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get scheduled transfers", "01", "01")
+}
+
+func (s *scheduledTransferDelivery) getById(ctx *gin.Context) {
+	resp, err := s.scheduleUC.GetByIdUC(ctx.GetHeader("Authorization"), ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get scheduled transfer", "01", "01")
+}
+
+func (s *scheduledTransferDelivery) update(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req scheduledTransferDto.UpdateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+	req.Id = ctx.Param("id")
+
+	resp, err := s.scheduleUC.UpdateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Scheduled transfer updated", "01", "01")
+}
+
+func (s *scheduledTransferDelivery) cancel(ctx *gin.Context) {
+	if err := s.scheduleUC.CancelUC(ctx.GetHeader("Authorization"), ctx.Param("id")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Scheduled transfer cancelled", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, scheduledTransfer.ErrNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, scheduledTransfer.ErrInvalidSchedule),
+		errors.Is(err, scheduledTransfer.ErrRecipientNotFound),
+		errors.Is(err, scheduledTransfer.ErrRecipientIsSender),
+		errors.Is(err, scheduledTransfer.ErrScheduleNotEditable):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrInvalidPin):
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrPinLocked):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,42 @@
+package scheduledTransfer
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/scheduledTransferDto"
+	"final-project-enigma/model/dto/userDto"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("scheduled transfer not found")
+	ErrInvalidSchedule     = errors.New("start date must be in the future and before the end date")
+	ErrRecipientNotFound   = errors.New("recipient not found")
+	ErrRecipientIsSender   = errors.New("sender and recipient cannot be the same")
+	ErrScheduleNotEditable = errors.New("only active or paused schedules can be changed")
+	ErrRunSuperseded       = errors.New("scheduled transfer changed since it was claimed")
+)
+
+// Notifier tells a user what happened to one of their scheduled transfers.
+type Notifier interface {
+	Notify(email, subject, message string) error
+}
+
+type ScheduledTransferRepository interface {
+	Create(req scheduledTransferDto.CreateScheduledTransferRequest) (scheduledTransferDto.ScheduledTransfer, error)
+	GetByUser(userId string) ([]scheduledTransferDto.ScheduledTransfer, error)
+	GetById(userId, id string) (scheduledTransferDto.ScheduledTransfer, error)
+	Update(schedule scheduledTransferDto.ScheduledTransfer) error
+	Cancel(userId, id string) error
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]scheduledTransferDto.ScheduledTransfer, error)
+	Run(schedule scheduledTransferDto.ScheduledTransfer, outcome func(userDto.WalletTransactionResponse, error) scheduledTransferDto.RunResult) (scheduledTransferDto.RunResult, error)
+	GetUserEmail(userId string) (string, error)
+}
+
+type ScheduledTransferUsecase interface {
+	CreateUC(req scheduledTransferDto.CreateScheduledTransferRequest, authHeader string) (scheduledTransferDto.ScheduledTransfer, error)
+	GetAllUC(authHeader string) ([]scheduledTransferDto.ScheduledTransfer, error)
+	GetByIdUC(authHeader, id string) (scheduledTransferDto.ScheduledTransfer, error)
+	UpdateUC(req scheduledTransferDto.UpdateScheduledTransferRequest, authHeader string) (scheduledTransferDto.ScheduledTransfer, error)
+	CancelUC(authHeader, id string) error
+	RunDueUC(now time.Time) error
+}
@@ -0,0 +1,291 @@
+package scheduledTransferRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/scheduledTransferDto"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/dbtx"
+	scheduledTransferDomain "final-project-enigma/src/scheduledTransfer"
+	"final-project-enigma/src/user/userRepository"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type scheduledTransferRepository struct {
+	db *sql.DB
+}
+
+func NewScheduledTransferRepository(db *sql.DB) scheduledTransferDomain.ScheduledTransferRepository {
+	return &scheduledTransferRepository{
+		db: db,
+	}
+}
+
+const scheduleColumns = `
+	id, user_id, recipient_phone_number, amount, COALESCE(description, ''), frequency,
+	start_at, end_at, next_run_at, retry_at, retry_count, status
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchedule(row rowScanner) (scheduledTransferDto.ScheduledTransfer, error) {
+	var s scheduledTransferDto.ScheduledTransfer
+	err := row.Scan(&s.Id, &s.UserId, &s.RecipientPhoneNumber, &s.Amount, &s.Description, &s.Frequency,
+		&s.StartAt, &s.EndAt, &s.NextRunAt, &s.RetryAt, &s.RetryCount, &s.Status)
+	return s, err
+}
+
+func (repo *scheduledTransferRepository) Create(req scheduledTransferDto.CreateScheduledTransferRequest) (scheduledTransferDto.ScheduledTransfer, error) {
+	var recipientId string
+	recipientQuery := `
+		SELECT id FROM users
+		WHERE phone_number = $1 AND status = 'active' AND deleted_at IS NULL
+	`
+	err := repo.db.QueryRow(recipientQuery, req.RecipientPhoneNumber).Scan(&recipientId)
+	if err == sql.ErrNoRows {
+		log.Error().Msg("recipient not found")
+		return scheduledTransferDto.ScheduledTransfer{}, scheduledTransferDomain.ErrRecipientNotFound
+	}
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+	if recipientId == req.UserId {
+		log.Error().Msg("sender and recipient cannot be the same")
+		return scheduledTransferDto.ScheduledTransfer{}, scheduledTransferDomain.ErrRecipientIsSender
+	}
+
+	query := `
+		INSERT INTO scheduled_transfers (user_id, recipient_phone_number, amount, description, frequency, start_at, end_at, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $6, $8, $8)
+		RETURNING ` + scheduleColumns
+	row := repo.db.QueryRow(query, req.UserId, req.RecipientPhoneNumber, req.Amount, req.Description, req.Frequency, req.StartAt, req.EndAt, time.Now())
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		log.Error().Msg("failed to create scheduled transfer: " + err.Error())
+		return scheduledTransferDto.ScheduledTransfer{}, fmt.Errorf("failed to create scheduled transfer: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (repo *scheduledTransferRepository) GetByUser(userId string) ([]scheduledTransferDto.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + `
+		FROM scheduled_transfers
+		WHERE user_id = $1 AND status <> 'cancelled'
+		ORDER BY created_at DESC
+	`
+	rows, err := repo.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfers: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []scheduledTransferDto.ScheduledTransfer{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %w", err)
+		}
+		resp = append(resp, schedule)
+	}
+
+	return resp, rows.Err()
+}
+
+func (repo *scheduledTransferRepository) GetById(userId, id string) (scheduledTransferDto.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + `
+		FROM scheduled_transfers
+		WHERE id = $1 AND user_id = $2 AND status <> 'cancelled'
+	`
+	schedule, err := scanSchedule(repo.db.QueryRow(query, id, userId))
+	if err == sql.ErrNoRows {
+		return scheduledTransferDto.ScheduledTransfer{}, scheduledTransferDomain.ErrNotFound
+	}
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+
+	runQuery := `
+		SELECT id, COALESCE(transaction_id::text, ''), occurrence_at, attempt, status, COALESCE(message, ''), created_at
+		FROM scheduled_transfer_runs
+		WHERE scheduled_transfer_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := repo.db.Query(runQuery, id)
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, fmt.Errorf("failed to get scheduled transfer runs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run scheduledTransferDto.Run
+		if err := rows.Scan(&run.Id, &run.TransactionId, &run.OccurrenceAt, &run.Attempt, &run.Status, &run.Message, &run.CreatedAt); err != nil {
+			return scheduledTransferDto.ScheduledTransfer{}, fmt.Errorf("failed to scan scheduled transfer run: %w", err)
+		}
+		schedule.Runs = append(schedule.Runs, run)
+	}
+
+	return schedule, rows.Err()
+}
+
+func (repo *scheduledTransferRepository) Update(schedule scheduledTransferDto.ScheduledTransfer) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET amount = $1, description = $2, end_at = $3, next_run_at = $4, retry_at = $5, retry_count = $6, status = $7, updated_at = $8
+		WHERE id = $9 AND user_id = $10
+	`
+	result, err := repo.db.Exec(query, schedule.Amount, schedule.Description, schedule.EndAt, schedule.NextRunAt,
+		schedule.RetryAt, schedule.RetryCount, schedule.Status, time.Now(), schedule.Id, schedule.UserId)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return scheduledTransferDomain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repo *scheduledTransferRepository) Cancel(userId, id string) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = 'cancelled', next_run_at = NULL, retry_at = NULL, updated_at = $1
+		WHERE id = $2 AND user_id = $3 AND status IN ('active', 'paused')
+	`
+	result, err := repo.db.Exec(query, time.Now(), id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled transfer: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return scheduledTransferDomain.ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue leases up to limit active schedules whose run or retry time has
+// passed. The lease keeps a second executor from picking up the same rows
+// while this one is still transferring; Run releases it.
+func (repo *scheduledTransferRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]scheduledTransferDto.ScheduledTransfer, error) {
+	query := `
+		UPDATE scheduled_transfers
+		SET locked_until = $2
+		WHERE id IN (
+			SELECT id FROM scheduled_transfers
+			WHERE status = 'active'
+				AND COALESCE(retry_at, next_run_at) <= $1
+				AND (locked_until IS NULL OR locked_until < $1)
+			ORDER BY COALESCE(retry_at, next_run_at)
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduleColumns
+	rows, err := repo.db.Query(query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due scheduled transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var due []scheduledTransferDto.ScheduledTransfer
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %w", err)
+		}
+		due = append(due, schedule)
+	}
+
+	return due, rows.Err()
+}
+
+// Run pays the claimed occurrence of schedule and records the run in one DB
+// transaction, so an occurrence is never paid without its run being saved.
+// The schedule row is locked first and the run is given up with
+// ErrRunSuperseded when it changed since it was claimed, e.g. when the lease
+// expired and another executor already paid it. outcome turns the result of
+// the transfer into the run to save; a failed transfer is rolled back to a
+// savepoint so its run can still be saved.
+func (repo *scheduledTransferRepository) Run(schedule scheduledTransferDto.ScheduledTransfer, outcome func(userDto.WalletTransactionResponse, error) scheduledTransferDto.RunResult) (scheduledTransferDto.RunResult, error) {
+	var result scheduledTransferDto.RunResult
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var status string
+		var nextRunAt sql.NullTime
+		var retryCount int
+		lockQuery := `SELECT status, next_run_at, retry_count FROM scheduled_transfers WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRow(lockQuery, schedule.Id).Scan(&status, &nextRunAt, &retryCount); err != nil {
+			return fmt.Errorf("failed to lock scheduled transfer: %w", err)
+		}
+		sameOccurrence := nextRunAt.Valid == (schedule.NextRunAt != nil) &&
+			(!nextRunAt.Valid || nextRunAt.Time.Equal(*schedule.NextRunAt))
+		if status != scheduledTransferDto.StatusActive || !sameOccurrence || retryCount != schedule.RetryCount {
+			return scheduledTransferDomain.ErrRunSuperseded
+		}
+
+		if _, err := tx.Exec(`SAVEPOINT scheduled_transfer`); err != nil {
+			return err
+		}
+		// The same path as a manual transfer, so limits, fees and the ledger
+		// all apply. The PIN was checked when the schedule was created.
+		resp, err := userRepository.WalletTransfer(tx, userDto.WalletTransactionRequest{
+			UserId:               schedule.UserId,
+			RecipientPhoneNumber: schedule.RecipientPhoneNumber,
+			Amount:               schedule.Amount,
+			Description:          schedule.Description,
+		})
+		if dbtx.IsRetryable(err) {
+			return err
+		}
+		if err != nil {
+			if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT scheduled_transfer`); rollbackErr != nil {
+				return rollbackErr
+			}
+		}
+
+		result = outcome(resp, err)
+		return saveRun(tx, schedule.Id, result)
+	})
+	if err != nil {
+		return scheduledTransferDto.RunResult{}, err
+	}
+	return result, nil
+}
+
+func saveRun(tx *sql.Tx, scheduleId string, result scheduledTransferDto.RunResult) error {
+	var transactionId sql.NullString
+	if result.Run.TransactionId != "" {
+		transactionId = sql.NullString{String: result.Run.TransactionId, Valid: true}
+	}
+	runQuery := `
+		INSERT INTO scheduled_transfer_runs (scheduled_transfer_id, transaction_id, occurrence_at, attempt, status, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := tx.Exec(runQuery, scheduleId, transactionId, result.Run.OccurrenceAt, result.Run.Attempt, result.Run.Status, result.Run.Message, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save scheduled transfer run: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE scheduled_transfers
+		SET next_run_at = $1, retry_at = $2, retry_count = $3, status = $4,
+			locked_until = NULL, updated_at = $5
+		WHERE id = $6
+	`
+	_, err = tx.Exec(updateQuery, result.NextRunAt, result.RetryAt, result.RetryCount, result.Status, time.Now(), scheduleId)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transfer: %w", err)
+	}
+	return nil
+}
+
+func (repo *scheduledTransferRepository) GetUserEmail(userId string) (string, error) {
+	var email string
+	err := repo.db.QueryRow(`SELECT email FROM users WHERE id = $1`, userId).Scan(&email)
+	if err != nil {
+		return "", err
+	}
+	return email, nil
+}
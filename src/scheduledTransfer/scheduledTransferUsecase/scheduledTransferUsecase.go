@@ -0,0 +1,295 @@
+package scheduledTransferUsecase
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/scheduledTransferDto"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/src/scheduledTransfer"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Hour
+	claimLease        = 5 * time.Minute
+	claimBatch        = 50
+)
+
+type scheduledTransferUC struct {
+	scheduleRepo scheduledTransfer.ScheduledTransferRepository
+	txAuthorizer txauth.Authorizer
+	notifier     scheduledTransfer.Notifier
+	maxRetries   int
+	retryDelay   time.Duration
+}
+
+// NewScheduledTransferUsecase reads SCHEDULED_TRANSFER_MAX_RETRIES and
+// SCHEDULED_TRANSFER_RETRY_DELAY, which control how often a run that hits an
+// insufficient balance is retried before that occurrence is skipped.
+func NewScheduledTransferUsecase(scheduleRepo scheduledTransfer.ScheduledTransferRepository, txAuthorizer txauth.Authorizer, notifier scheduledTransfer.Notifier) scheduledTransfer.ScheduledTransferUsecase {
+	maxRetries, err := strconv.Atoi(os.Getenv("SCHEDULED_TRANSFER_MAX_RETRIES"))
+	if err != nil || maxRetries < 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	retryDelay, err := time.ParseDuration(os.Getenv("SCHEDULED_TRANSFER_RETRY_DELAY"))
+	if err != nil || retryDelay <= 0 {
+		retryDelay = defaultRetryDelay
+	}
+
+	return &scheduledTransferUC{
+		scheduleRepo: scheduleRepo,
+		txAuthorizer: txAuthorizer,
+		notifier:     notifier,
+		maxRetries:   maxRetries,
+		retryDelay:   retryDelay,
+	}
+}
+
+func (usecase *scheduledTransferUC) CreateUC(req scheduledTransferDto.CreateScheduledTransferRequest, authHeader string) (scheduledTransferDto.ScheduledTransfer, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+	req.UserId = userId
+
+	if req.StartAt.Before(time.Now()) || (req.EndAt != nil && req.EndAt.Before(req.StartAt)) {
+		return scheduledTransferDto.ScheduledTransfer{}, scheduledTransfer.ErrInvalidSchedule
+	}
+	if req.Description == "" {
+		req.Description = "Scheduled-Transfer"
+	}
+
+	if err := usecase.txAuthorizer.Authorize(req.UserId, req.PIN); err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+
+	return usecase.scheduleRepo.Create(req)
+}
+
+func (usecase *scheduledTransferUC) GetAllUC(authHeader string) ([]scheduledTransferDto.ScheduledTransfer, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.scheduleRepo.GetByUser(userId)
+}
+
+func (usecase *scheduledTransferUC) GetByIdUC(authHeader, id string) (scheduledTransferDto.ScheduledTransfer, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+	return usecase.scheduleRepo.GetById(userId, id)
+}
+
+func (usecase *scheduledTransferUC) UpdateUC(req scheduledTransferDto.UpdateScheduledTransferRequest, authHeader string) (scheduledTransferDto.ScheduledTransfer, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+
+	schedule, err := usecase.scheduleRepo.GetById(userId, req.Id)
+	if err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+	if schedule.Status != scheduledTransferDto.StatusActive && schedule.Status != scheduledTransferDto.StatusPaused {
+		return scheduledTransferDto.ScheduledTransfer{}, scheduledTransfer.ErrScheduleNotEditable
+	}
+
+	if err := usecase.txAuthorizer.Authorize(userId, req.PIN); err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+
+	if req.Amount != nil {
+		schedule.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		schedule.Description = *req.Description
+	}
+	if req.EndAt != nil {
+		if req.EndAt.Before(time.Now()) {
+			return scheduledTransferDto.ScheduledTransfer{}, scheduledTransfer.ErrInvalidSchedule
+		}
+		schedule.EndAt = req.EndAt
+	}
+
+	// Resuming skips the occurrences that passed while the schedule was
+	// paused instead of paying them all at once.
+	now := time.Now()
+	if req.Status == scheduledTransferDto.StatusActive && schedule.Status == scheduledTransferDto.StatusPaused &&
+		schedule.NextRunAt != nil && schedule.NextRunAt.Before(now) && schedule.Frequency != scheduledTransferDto.FrequencyOnce {
+		schedule.NextRunAt = NextOccurrence(schedule, *schedule.NextRunAt, now)
+		schedule.RetryAt = nil
+		schedule.RetryCount = 0
+	}
+	if req.Status != "" {
+		schedule.Status = req.Status
+	}
+	if schedule.NextRunAt == nil || (schedule.EndAt != nil && schedule.NextRunAt.After(*schedule.EndAt)) {
+		schedule.NextRunAt = nil
+		schedule.Status = scheduledTransferDto.StatusCompleted
+	}
+
+	if err := usecase.scheduleRepo.Update(schedule); err != nil {
+		return scheduledTransferDto.ScheduledTransfer{}, err
+	}
+	return schedule, nil
+}
+
+func (usecase *scheduledTransferUC) CancelUC(authHeader, id string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.scheduleRepo.Cancel(userId, id)
+}
+
+// RunDueUC executes every schedule that is due at now. It is called by the
+// background executor started from the router.
+func (usecase *scheduledTransferUC) RunDueUC(now time.Time) error {
+	due, err := usecase.scheduleRepo.ClaimDue(now, claimLease, claimBatch)
+	if err != nil {
+		return err
+	}
+
+	// A run that fails to save is rolled back with its transfer, so the
+	// occurrence is paid once the lease expires and it is claimed again.
+	for _, schedule := range due {
+		result, err := usecase.scheduleRepo.Run(schedule, func(resp userDto.WalletTransactionResponse, err error) scheduledTransferDto.RunResult {
+			return usecase.outcome(schedule, now, resp, err)
+		})
+		if err != nil {
+			log.Error().Msg("failed to run scheduled transfer " + schedule.Id + ": " + err.Error())
+			continue
+		}
+		usecase.notify(schedule, result)
+	}
+
+	return nil
+}
+
+// outcome decides what a run of schedule comes to given the result of its
+// transfer: the next occurrence, a retry or the end of the schedule.
+func (usecase *scheduledTransferUC) outcome(schedule scheduledTransferDto.ScheduledTransfer, now time.Time, resp userDto.WalletTransactionResponse, err error) scheduledTransferDto.RunResult {
+	occurrence := now
+	if schedule.NextRunAt != nil {
+		occurrence = *schedule.NextRunAt
+	}
+
+	result := scheduledTransferDto.RunResult{
+		Run: scheduledTransferDto.Run{
+			OccurrenceAt: occurrence,
+			Attempt:      schedule.RetryCount + 1,
+		},
+		Status: scheduledTransferDto.StatusActive,
+	}
+
+	switch {
+	case err == nil:
+		result.Run.Status = scheduledTransferDto.RunSuccess
+		result.Run.TransactionId = resp.TransactionId
+	case errors.Is(err, ledger.ErrInsufficientBalance) && schedule.RetryCount < usecase.maxRetries:
+		retryAt := now.Add(usecase.retryDelay)
+		result.Run.Status = scheduledTransferDto.RunRetrying
+		result.Run.Message = err.Error()
+		result.NextRunAt = schedule.NextRunAt
+		result.RetryAt = &retryAt
+		result.RetryCount = schedule.RetryCount + 1
+		return result
+	case errors.Is(err, ledger.ErrInsufficientBalance):
+		result.Run.Status = scheduledTransferDto.RunSkipped
+		result.Run.Message = err.Error()
+	default:
+		result.Run.Status = scheduledTransferDto.RunFailed
+		result.Run.Message = err.Error()
+	}
+
+	result.NextRunAt = NextOccurrence(schedule, occurrence, now)
+	if result.NextRunAt == nil {
+		result.Status = scheduledTransferDto.StatusCompleted
+	}
+	return result
+}
+
+func (usecase *scheduledTransferUC) notify(schedule scheduledTransferDto.ScheduledTransfer, result scheduledTransferDto.RunResult) {
+	email, err := usecase.scheduleRepo.GetUserEmail(schedule.UserId)
+	if err != nil {
+		log.Error().Msg("failed to get email for scheduled transfer " + schedule.Id + ": " + err.Error())
+		return
+	}
+
+	amount := "Rp" + schedule.Amount.Format(money.Currency) + " to " + schedule.RecipientPhoneNumber
+	var subject, message string
+	switch result.Run.Status {
+	case scheduledTransferDto.RunSuccess:
+		subject = "Scheduled transfer sent"
+		message = "Your scheduled transfer of " + amount + " was sent. Transaction ID: " + result.Run.TransactionId
+	case scheduledTransferDto.RunRetrying:
+		subject = "Scheduled transfer delayed"
+		message = "Your scheduled transfer of " + amount + " could not be sent because your balance is insufficient. " +
+			"We will try again at " + result.RetryAt.Format("02-01-2006 15:04") + "."
+	case scheduledTransferDto.RunSkipped:
+		subject = "Scheduled transfer skipped"
+		message = "Your scheduled transfer of " + amount + " was skipped after several attempts because your balance is insufficient."
+	default:
+		subject = "Scheduled transfer failed"
+		message = "Your scheduled transfer of " + amount + " failed: " + result.Run.Message
+	}
+	if result.NextRunAt != nil && result.Run.Status != scheduledTransferDto.RunRetrying {
+		message += "\nNext transfer: " + result.NextRunAt.Format("02-01-2006 15:04") + "."
+	}
+
+	if err := usecase.notifier.Notify(email, subject, message); err != nil {
+		log.Error().Msg("failed to notify scheduled transfer " + schedule.Id + ": " + err.Error())
+	}
+}
+
+// NextOccurrence returns the first occurrence of schedule after both
+// occurrence and now, or nil when the schedule has no more runs. Monthly
+// schedules keep the day of month of StartAt and fall back to the last day of
+// shorter months.
+func NextOccurrence(schedule scheduledTransferDto.ScheduledTransfer, occurrence, now time.Time) *time.Time {
+	var next time.Time
+	switch schedule.Frequency {
+	case scheduledTransferDto.FrequencyWeekly:
+		next = occurrence.AddDate(0, 0, 7)
+		for !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+	case scheduledTransferDto.FrequencyMonthly:
+		start := schedule.StartAt
+		months := (occurrence.Year()-start.Year())*12 + int(occurrence.Month()-start.Month()) + 1
+		next = monthlyOccurrence(start, months)
+		for !next.After(now) {
+			months++
+			next = monthlyOccurrence(start, months)
+		}
+	default:
+		return nil
+	}
+
+	if schedule.EndAt != nil && next.After(*schedule.EndAt) {
+		return nil
+	}
+	return &next
+}
+
+func monthlyOccurrence(start time.Time, months int) time.Time {
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := start.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
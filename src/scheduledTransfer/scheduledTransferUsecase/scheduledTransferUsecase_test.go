@@ -0,0 +1,147 @@
+package scheduledTransferUsecase_test
+
+import (
+	"final-project-enigma/model/dto/scheduledTransferDto"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/src/scheduledTransfer"
+	"final-project-enigma/src/scheduledTransfer/scheduledTransferUsecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockScheduleRepo struct {
+	scheduledTransfer.ScheduledTransferRepository
+	due         []scheduledTransferDto.ScheduledTransfer
+	transferErr error
+	runErr      error
+	saved       []scheduledTransferDto.RunResult
+}
+
+func (m *mockScheduleRepo) ClaimDue(now time.Time, lease time.Duration, limit int) ([]scheduledTransferDto.ScheduledTransfer, error) {
+	return m.due, nil
+}
+
+func (m *mockScheduleRepo) Run(schedule scheduledTransferDto.ScheduledTransfer, outcome func(userDto.WalletTransactionResponse, error) scheduledTransferDto.RunResult) (scheduledTransferDto.RunResult, error) {
+	if m.runErr != nil {
+		return scheduledTransferDto.RunResult{}, m.runErr
+	}
+	resp := userDto.WalletTransactionResponse{TransactionId: "trx1"}
+	if m.transferErr != nil {
+		resp = userDto.WalletTransactionResponse{}
+	}
+	result := outcome(resp, m.transferErr)
+	m.saved = append(m.saved, result)
+	return result, nil
+}
+
+func (m *mockScheduleRepo) GetUserEmail(userId string) (string, error) {
+	return "user@example.com", nil
+}
+
+type mockNotifier struct {
+	subjects []string
+	messages []string
+}
+
+func (m *mockNotifier) Notify(email, subject, message string) error {
+	m.subjects = append(m.subjects, subject)
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	monthly := scheduledTransferDto.ScheduledTransfer{Frequency: scheduledTransferDto.FrequencyMonthly, StartAt: date(2024, time.January, 31)}
+
+	next := scheduledTransferUsecase.NextOccurrence(monthly, date(2024, time.January, 31), date(2024, time.January, 31))
+	assert.Equal(t, date(2024, time.February, 29), *next)
+
+	next = scheduledTransferUsecase.NextOccurrence(monthly, date(2024, time.February, 29), date(2024, time.February, 29))
+	assert.Equal(t, date(2024, time.March, 31), *next)
+
+	weekly := scheduledTransferDto.ScheduledTransfer{Frequency: scheduledTransferDto.FrequencyWeekly, StartAt: date(2024, time.January, 1)}
+	next = scheduledTransferUsecase.NextOccurrence(weekly, date(2024, time.January, 1), date(2024, time.January, 16))
+	assert.Equal(t, date(2024, time.January, 22), *next)
+
+	endAt := date(2024, time.January, 20)
+	weekly.EndAt = &endAt
+	assert.Nil(t, scheduledTransferUsecase.NextOccurrence(weekly, date(2024, time.January, 15), date(2024, time.January, 15)))
+
+	once := scheduledTransferDto.ScheduledTransfer{Frequency: scheduledTransferDto.FrequencyOnce}
+	assert.Nil(t, scheduledTransferUsecase.NextOccurrence(once, date(2024, time.January, 1), date(2024, time.January, 1)))
+}
+
+func TestRunDueUC(t *testing.T) {
+	t.Setenv("SCHEDULED_TRANSFER_MAX_RETRIES", "2")
+	t.Setenv("SCHEDULED_TRANSFER_RETRY_DELAY", "1h")
+
+	now := date(2024, time.March, 1)
+	nextRun := now
+	weekly := scheduledTransferDto.ScheduledTransfer{
+		Id:                   "s1",
+		UserId:               "u1",
+		RecipientPhoneNumber: "081234567890",
+		Amount:               150000,
+		Frequency:            scheduledTransferDto.FrequencyWeekly,
+		StartAt:              date(2024, time.February, 23),
+		NextRunAt:            &nextRun,
+		Status:               scheduledTransferDto.StatusActive,
+	}
+
+	t.Run("success advances to the next occurrence", func(t *testing.T) {
+		repo := &mockScheduleRepo{due: []scheduledTransferDto.ScheduledTransfer{weekly}}
+		notifier := &mockNotifier{}
+		uc := scheduledTransferUsecase.NewScheduledTransferUsecase(repo, nil, notifier)
+
+		assert.NoError(t, uc.RunDueUC(now))
+		assert.Len(t, repo.saved, 1)
+		assert.Equal(t, scheduledTransferDto.RunSuccess, repo.saved[0].Run.Status)
+		assert.Equal(t, "trx1", repo.saved[0].Run.TransactionId)
+		assert.Equal(t, date(2024, time.March, 8), *repo.saved[0].NextRunAt)
+		assert.Equal(t, []string{"Scheduled transfer sent"}, notifier.subjects)
+		assert.Contains(t, notifier.messages[0], "Rp150,000 to 081234567890")
+	})
+
+	t.Run("insufficient balance retries", func(t *testing.T) {
+		repo := &mockScheduleRepo{due: []scheduledTransferDto.ScheduledTransfer{weekly}, transferErr: ledger.ErrInsufficientBalance}
+		uc := scheduledTransferUsecase.NewScheduledTransferUsecase(repo, nil, &mockNotifier{})
+
+		assert.NoError(t, uc.RunDueUC(now))
+		result := repo.saved[0]
+		assert.Equal(t, scheduledTransferDto.RunRetrying, result.Run.Status)
+		assert.Equal(t, 1, result.RetryCount)
+		assert.Equal(t, now.Add(time.Hour), *result.RetryAt)
+		assert.Equal(t, now, *result.NextRunAt)
+	})
+
+	t.Run("insufficient balance skips after the last retry", func(t *testing.T) {
+		exhausted := weekly
+		exhausted.RetryCount = 2
+		repo := &mockScheduleRepo{due: []scheduledTransferDto.ScheduledTransfer{exhausted}, transferErr: ledger.ErrInsufficientBalance}
+		uc := scheduledTransferUsecase.NewScheduledTransferUsecase(repo, nil, &mockNotifier{})
+
+		assert.NoError(t, uc.RunDueUC(now.Add(2*time.Hour)))
+		result := repo.saved[0]
+		assert.Equal(t, scheduledTransferDto.RunSkipped, result.Run.Status)
+		assert.Equal(t, 3, result.Run.Attempt)
+		assert.Equal(t, 0, result.RetryCount)
+		assert.Nil(t, result.RetryAt)
+		assert.Equal(t, date(2024, time.March, 8), *result.NextRunAt)
+	})
+
+	t.Run("a run that cannot be saved is not notified", func(t *testing.T) {
+		repo := &mockScheduleRepo{due: []scheduledTransferDto.ScheduledTransfer{weekly}, runErr: scheduledTransfer.ErrRunSuperseded}
+		notifier := &mockNotifier{}
+		uc := scheduledTransferUsecase.NewScheduledTransferUsecase(repo, nil, notifier)
+
+		assert.NoError(t, uc.RunDueUC(now))
+		assert.Empty(t, repo.saved)
+		assert.Empty(t, notifier.subjects)
+	})
+}
@@ -0,0 +1,105 @@
+package settlementDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/settlementDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/merchant"
+	"final-project-enigma/src/settlement"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type settlementDelivery struct {
+	settlementUC settlement.SettlementUsecase
+}
+
+func NewSettlementDelivery(v1Group *gin.RouterGroup, settlementUC settlement.SettlementUsecase) {
+	handler := settlementDelivery{
+		settlementUC: settlementUC,
+	}
+
+	adminGroup := v1Group.Group("/admin/settlements")
+	{
+		adminGroup.GET("", middleware.JwtAuthWithRoles("ADMIN"), handler.getAll)
+		adminGroup.POST("/:id/approve", middleware.JwtAuthWithRoles("ADMIN"), handler.approve)
+	}
+
+	merchantGroup := v1Group.Group("/merchant/settlements")
+	{
+		merchantGroup.GET("", middleware.JwtAuthWithRoles("MERCHANT"), handler.getOwn)
+		merchantGroup.GET("/:id/report", middleware.JwtAuthWithRoles("MERCHANT"), handler.getOwnReport)
+	}
+}
+
+func (s *settlementDelivery) getAll(ctx *gin.Context) {
+	params := settlementDto.GetBatchParams{
+		MerchantId: ctx.Query("merchantId"),
+		Status:     ctx.Query("status"),
+		Page:       ctx.Query("page"),
+		Limit:      ctx.Query("size"),
+	}
+
+	resp, totalData, err := s.settlementUC.GetBatchesUC(params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSuccesPaging(ctx, resp, "Success get settlement batches", "01", "01", ctx.DefaultQuery("page", "1"), totalData)
+}
+
+func (s *settlementDelivery) approve(ctx *gin.Context) {
+	resp, err := s.settlementUC.ApproveUC(ctx.Param("id"), ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Settlement batch approved", "01", "01")
+}
+
+func (s *settlementDelivery) getOwn(ctx *gin.Context) {
+	params := settlementDto.GetBatchParams{
+		Status: ctx.Query("status"),
+		Page:   ctx.Query("page"),
+		Limit:  ctx.Query("size"),
+	}
+
+	resp, totalData, err := s.settlementUC.GetOwnBatchesUC(ctx.GetHeader("Authorization"), params)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSuccesPaging(ctx, resp, "Success get settlement batches", "01", "01", ctx.DefaultQuery("page", "1"), totalData)
+}
+
+func (s *settlementDelivery) getOwnReport(ctx *gin.Context) {
+	fileName, report, err := s.settlementUC.GetOwnReportUC(ctx.GetHeader("Authorization"), ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	ctx.Data(http.StatusOK, "text/csv", report)
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, settlement.ErrBatchNotFound), errors.Is(err, merchant.ErrMerchantNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, settlement.ErrInvalidStatus):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "status", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, settlement.ErrBatchNotPending):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	case errors.Is(err, ledger.ErrInsufficientBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,29 @@
+package settlement
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/settlementDto"
+	"time"
+)
+
+var (
+	ErrBatchNotFound   = errors.New("settlement batch not found")
+	ErrBatchNotPending = errors.New("settlement batch is already approved")
+	ErrInvalidStatus   = errors.New("invalid settlement status")
+)
+
+type SettlementRepository interface {
+	CreateBatches(before time.Time) ([]settlementDto.Batch, error)
+	GetBatches(params settlementDto.GetBatchParams) ([]settlementDto.Batch, int, error)
+	GetBatch(merchantId, id string) (settlementDto.Batch, error)
+	GetEntries(batchId string) ([]settlementDto.Entry, error)
+	Approve(req settlementDto.ApproveBatchRequest) (settlementDto.Batch, error)
+}
+
+type SettlementUsecase interface {
+	SettleUC(now time.Time) error
+	GetBatchesUC(params settlementDto.GetBatchParams) ([]settlementDto.Batch, string, error)
+	ApproveUC(id, authHeader string) (settlementDto.Batch, error)
+	GetOwnBatchesUC(authHeader string, params settlementDto.GetBatchParams) ([]settlementDto.Batch, string, error)
+	GetOwnReportUC(authHeader, id string) (string, []byte, error)
+}
@@ -0,0 +1,314 @@
+package settlementRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/settlementDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
+	settlementDomain "final-project-enigma/src/settlement"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type settlementRepository struct {
+	db *sql.DB
+}
+
+func NewSettlementRepository(db *sql.DB) settlementDomain.SettlementRepository {
+	return &settlementRepository{
+		db: db,
+	}
+}
+
+const batchColumns = `
+	b.id, b.merchant_id, m.merchant_name, to_char(b.business_date, 'YYYY-MM-DD'), b.transaction_count,
+	b.gross_amount, b.refund_amount, b.fee_amount, b.net_amount, b.status,
+	COALESCE(b.transaction_id::text, ''), b.approved_at, b.created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBatch(row rowScanner) (settlementDto.Batch, error) {
+	var b settlementDto.Batch
+	err := row.Scan(&b.Id, &b.MerchantId, &b.MerchantName, &b.BusinessDate, &b.TransactionCount,
+		&b.GrossAmount, &b.RefundAmount, &b.FeeAmount, &b.NetAmount, &b.Status,
+		&b.TransactionId, &b.ApprovedAt, &b.CreatedAt)
+	return b, err
+}
+
+type unsettledDay struct {
+	merchantId   string
+	businessDate string
+	count        int
+	gross        money.Money
+	refund       money.Money
+}
+
+// CreateBatches groups every unsettled merchant transaction booked before
+// the cutoff into one batch per merchant and business day. A day that
+// another worker already settled is skipped through the unique
+// (merchant_id, business_date) constraint.
+func (repo *settlementRepository) CreateBatches(before time.Time) ([]settlementDto.Batch, error) {
+	var created []string
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		created = nil
+
+		query := `
+			SELECT mt.merchant_id, to_char(mt.created_at, 'YYYY-MM-DD'), COUNT(*),
+				COALESCE(SUM(t.amount) FILTER (WHERE t.reversal_of IS NULL), 0),
+				COALESCE(SUM(t.amount) FILTER (WHERE t.reversal_of IS NOT NULL), 0)
+			FROM merchant_transactions mt
+			JOIN transactions t ON t.id = mt.transaction_id
+			WHERE mt.settlement_batch_id IS NULL AND mt.created_at < $1
+			GROUP BY 1, 2
+			ORDER BY 2, 1
+		`
+		rows, err := tx.Query(query, before)
+		if err != nil {
+			return fmt.Errorf("failed to get unsettled merchant transactions: %w", err)
+		}
+		var days []unsettledDay
+		for rows.Next() {
+			var day unsettledDay
+			if err := rows.Scan(&day.merchantId, &day.businessDate, &day.count, &day.gross, &day.refund); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan unsettled merchant transactions: %w", err)
+			}
+			days = append(days, day)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, day := range days {
+			// Refunds can outweigh a day's payments; such a batch has no fee
+			// and a negative net that nothing is paid out for.
+			total := day.gross - day.refund
+			var fee money.Money
+			if total > 0 {
+				fee, err = fees.Quote(tx, fees.TransactionMerchantSettlement, "", total)
+				if err != nil {
+					return err
+				}
+				if fee > total {
+					fee = total
+				}
+			}
+
+			var batchId string
+			insertQuery := `
+				INSERT INTO settlement_batches (merchant_id, business_date, transaction_count, gross_amount, refund_amount, fee_amount, net_amount, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (merchant_id, business_date) DO NOTHING
+				RETURNING id
+			`
+			err = tx.QueryRow(insertQuery, day.merchantId, day.businessDate, day.count, day.gross, day.refund, fee, total-fee, time.Now()).Scan(&batchId)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create settlement batch: %w", err)
+			}
+
+			updateQuery := `
+				UPDATE merchant_transactions
+				SET settlement_batch_id = $1
+				WHERE merchant_id = $2 AND settlement_batch_id IS NULL
+					AND to_char(created_at, 'YYYY-MM-DD') = $3 AND created_at < $4
+			`
+			if _, err := tx.Exec(updateQuery, batchId, day.merchantId, day.businessDate, before); err != nil {
+				return fmt.Errorf("failed to assign settlement batch: %w", err)
+			}
+			created = append(created, batchId)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batches := []settlementDto.Batch{}
+	for _, id := range created {
+		batch, err := repo.GetBatch("", id)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+func (repo *settlementRepository) GetBatches(params settlementDto.GetBatchParams) ([]settlementDto.Batch, int, error) {
+	var conditions []string
+	var args []interface{}
+	if params.MerchantId != "" {
+		args = append(args, params.MerchantId)
+		conditions = append(conditions, fmt.Sprintf("b.merchant_id = $%d", len(args)))
+	}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		conditions = append(conditions, fmt.Sprintf("b.status = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var totalData int
+	countQuery := `SELECT COUNT(*) FROM settlement_batches b ` + where
+	if err := repo.db.QueryRow(countQuery, args...).Scan(&totalData); err != nil {
+		return nil, 0, fmt.Errorf("failed to count settlement batches: %w", err)
+	}
+
+	query := `SELECT ` + batchColumns + `
+		FROM settlement_batches b
+		JOIN merchant m ON m.id = b.merchant_id
+		` + where + `
+		ORDER BY b.business_date DESC, m.merchant_name
+	`
+	if params.Page != "" && params.Limit != "" {
+		page, _ := strconv.Atoi(params.Page)
+		limit, _ := strconv.Atoi(params.Limit)
+		offset := (page - 1) * limit
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get settlement batches: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []settlementDto.Batch{}
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan settlement batch: %w", err)
+		}
+		resp = append(resp, batch)
+	}
+
+	return resp, totalData, rows.Err()
+}
+
+// GetBatch returns batch id. An empty merchantId matches any merchant.
+func (repo *settlementRepository) GetBatch(merchantId, id string) (settlementDto.Batch, error) {
+	query := `SELECT ` + batchColumns + `
+		FROM settlement_batches b
+		JOIN merchant m ON m.id = b.merchant_id
+		WHERE b.id = $1 AND ($2 = '' OR b.merchant_id::text = $2)
+	`
+	batch, err := scanBatch(repo.db.QueryRow(query, id, merchantId))
+	if err == sql.ErrNoRows {
+		return settlementDto.Batch{}, settlementDomain.ErrBatchNotFound
+	}
+	if err != nil {
+		return settlementDto.Batch{}, fmt.Errorf("failed to get settlement batch: %w", err)
+	}
+	return batch, nil
+}
+
+func (repo *settlementRepository) GetEntries(batchId string) ([]settlementDto.Entry, error) {
+	query := `
+		SELECT t.id, t.reversal_of IS NOT NULL, COALESCE(mt.bill_reference, ''), COALESCE(t.description, ''), t.amount, t.created_at
+		FROM merchant_transactions mt
+		JOIN transactions t ON t.id = mt.transaction_id
+		WHERE mt.settlement_batch_id = $1
+		ORDER BY t.created_at
+	`
+	rows, err := repo.db.Query(query, batchId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement entries: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []settlementDto.Entry{}
+	for rows.Next() {
+		var entry settlementDto.Entry
+		var refund bool
+		if err := rows.Scan(&entry.TransactionId, &refund, &entry.BillReference, &entry.Description, &entry.Amount, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement entry: %w", err)
+		}
+		entry.Type = settlementDto.EntryTypePayment
+		if refund {
+			entry.Type = settlementDto.EntryTypeRefund
+		}
+		resp = append(resp, entry)
+	}
+
+	return resp, rows.Err()
+}
+
+// Approve pays a pending batch out of the merchant wallet: the net amount
+// goes to the settlement account and the fee to the platform wallet. The
+// payout transaction is booked on the approving admin, as every transaction
+// needs a user.
+func (repo *settlementRepository) Approve(req settlementDto.ApproveBatchRequest) (settlementDto.Batch, error) {
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var merchantId, walletId, status string
+		var gross, refund, fee, net money.Money
+		lockQuery := `
+			SELECT b.merchant_id, m.wallet_id, b.status, b.gross_amount, b.refund_amount, b.fee_amount, b.net_amount
+			FROM settlement_batches b
+			JOIN merchant m ON m.id = b.merchant_id
+			WHERE b.id = $1
+			FOR UPDATE OF b
+		`
+		err := tx.QueryRow(lockQuery, req.Id).Scan(&merchantId, &walletId, &status, &gross, &refund, &fee, &net)
+		if err == sql.ErrNoRows {
+			return settlementDomain.ErrBatchNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if status != settlementDto.StatusPending {
+			log.Error().Msg("settlement batch " + req.Id + " is " + status + ", cannot approve")
+			return settlementDomain.ErrBatchNotPending
+		}
+
+		currentTime := time.Now()
+		var transactionId sql.NullString
+		if payout := gross - refund; payout > 0 {
+			transactionQuery := `
+				INSERT INTO transactions (user_id, transaction_type, amount, fee, description, created_at, status)
+				VALUES ($1, 'debit', $2, $3, 'Merchant-Settlement', $4, 'success')
+				RETURNING id
+			`
+			if err := tx.QueryRow(transactionQuery, req.AdminId, payout, fee, currentTime).Scan(&transactionId); err != nil {
+				return err
+			}
+
+			entries := []ledger.Entry{ledger.Debit(ledger.AccountWallet, walletId, payout)}
+			if net > 0 {
+				entries = append(entries, ledger.Credit(ledger.AccountSettlement, merchantId, net))
+			}
+			entries = append(entries, fees.Entries(fee)...)
+			if err := ledger.Post(tx, transactionId.String, entries...); err != nil {
+				return err
+			}
+		}
+
+		updateQuery := `
+			UPDATE settlement_batches
+			SET status = 'approved', transaction_id = $1, approved_by = $2, approved_at = $3
+			WHERE id = $4
+		`
+		_, err = tx.Exec(updateQuery, transactionId, req.AdminId, currentTime, req.Id)
+		return err
+	})
+	if err != nil {
+		return settlementDto.Batch{}, err
+	}
+
+	return repo.GetBatch("", req.Id)
+}
@@ -0,0 +1,154 @@
+package settlementUsecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"final-project-enigma/model/dto/settlementDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/merchant"
+	"final-project-enigma/src/settlement"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPage     = "1"
+	defaultPageSize = "10"
+)
+
+type settlementUC struct {
+	settlementRepo settlement.SettlementRepository
+	merchantRepo   merchant.MerchantRepository
+}
+
+func NewSettlementUsecase(settlementRepo settlement.SettlementRepository, merchantRepo merchant.MerchantRepository) settlement.SettlementUsecase {
+	return &settlementUC{
+		settlementRepo: settlementRepo,
+		merchantRepo:   merchantRepo,
+	}
+}
+
+// SettleUC creates the batches of every business day that ended before now.
+// It is called by the background worker started from the router.
+func (usecase *settlementUC) SettleUC(now time.Time) error {
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	batches, err := usecase.settlementRepo.CreateBatches(cutoff)
+	if err != nil {
+		return err
+	}
+	if len(batches) > 0 {
+		log.Info().Msg("created " + strconv.Itoa(len(batches)) + " settlement batches")
+	}
+	return nil
+}
+
+func (usecase *settlementUC) GetBatchesUC(params settlementDto.GetBatchParams) ([]settlementDto.Batch, string, error) {
+	if params.Status != "" && params.Status != settlementDto.StatusPending && params.Status != settlementDto.StatusApproved {
+		return nil, "", settlement.ErrInvalidStatus
+	}
+	if page, err := strconv.Atoi(params.Page); err != nil || page < 1 {
+		params.Page = defaultPage
+	}
+	if limit, err := strconv.Atoi(params.Limit); err != nil || limit < 1 {
+		params.Limit = defaultPageSize
+	}
+
+	resp, totalData, err := usecase.settlementRepo.GetBatches(params)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, strconv.Itoa(totalData), nil
+}
+
+func (usecase *settlementUC) ApproveUC(id, authHeader string) (settlementDto.Batch, error) {
+	adminId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return settlementDto.Batch{}, err
+	}
+	return usecase.settlementRepo.Approve(settlementDto.ApproveBatchRequest{Id: id, AdminId: adminId})
+}
+
+func (usecase *settlementUC) GetOwnBatchesUC(authHeader string, params settlementDto.GetBatchParams) ([]settlementDto.Batch, string, error) {
+	ownerId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, "", err
+	}
+	own, err := usecase.merchantRepo.GetByOwner(ownerId)
+	if err != nil {
+		return nil, "", err
+	}
+	params.MerchantId = own.Id
+
+	return usecase.GetBatchesUC(params)
+}
+
+// GetOwnReportUC returns the file name and CSV report of one of the
+// caller's batches: every settled transaction followed by the totals.
+func (usecase *settlementUC) GetOwnReportUC(authHeader, id string) (string, []byte, error) {
+	ownerId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return "", nil, err
+	}
+	own, err := usecase.merchantRepo.GetByOwner(ownerId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	batch, err := usecase.settlementRepo.GetBatch(own.Id, id)
+	if err != nil {
+		return "", nil, err
+	}
+	entries, err := usecase.settlementRepo.GetEntries(batch.Id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	report, err := Report(batch, entries)
+	if err != nil {
+		return "", nil, err
+	}
+	return "settlement-" + batch.BusinessDate + ".csv", report, nil
+}
+
+// Report renders a batch and its entries as CSV. Refunds are listed with a
+// negative amount so the amount column adds up to gross minus refunds.
+func Report(batch settlementDto.Batch, entries []settlementDto.Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"transaction_id", "created_at", "type", "bill_reference", "description", "amount"},
+	}
+	for _, entry := range entries {
+		amount := entry.Amount
+		if entry.Type == settlementDto.EntryTypeRefund {
+			amount = -amount
+		}
+		records = append(records, []string{
+			entry.TransactionId,
+			entry.CreatedAt.Format("2006-01-02 15:04:05"),
+			entry.Type,
+			entry.BillReference,
+			entry.Description,
+			strconv.FormatInt(int64(amount), 10),
+		})
+	}
+	records = append(records,
+		[]string{},
+		[]string{"merchant", batch.MerchantName},
+		[]string{"business_date", batch.BusinessDate},
+		[]string{"status", batch.Status},
+		[]string{"transactions", strconv.Itoa(batch.TransactionCount)},
+		[]string{"gross", strconv.FormatInt(int64(batch.GrossAmount), 10)},
+		[]string{"refunds", strconv.FormatInt(int64(batch.RefundAmount), 10)},
+		[]string{"fee", strconv.FormatInt(int64(batch.FeeAmount), 10)},
+		[]string{"net", strconv.FormatInt(int64(batch.NetAmount), 10)},
+	)
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package settlementUsecase_test
+
+import (
+	"final-project-enigma/model/dto/settlementDto"
+	"final-project-enigma/src/settlement"
+	"final-project-enigma/src/settlement/settlementUsecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSettlementRepo struct {
+	settlement.SettlementRepository
+	before time.Time
+}
+
+func (m *mockSettlementRepo) CreateBatches(before time.Time) ([]settlementDto.Batch, error) {
+	m.before = before
+	return nil, nil
+}
+
+func TestSettleUC(t *testing.T) {
+	repo := &mockSettlementRepo{}
+	uc := settlementUsecase.NewSettlementUsecase(repo, nil)
+
+	now := time.Date(2024, time.March, 2, 0, 30, 0, 0, time.UTC)
+	assert.NoError(t, uc.SettleUC(now))
+	assert.Equal(t, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), repo.before)
+}
+
+func TestGetBatchesUC_InvalidStatus(t *testing.T) {
+	uc := settlementUsecase.NewSettlementUsecase(&mockSettlementRepo{}, nil)
+
+	_, _, err := uc.GetBatchesUC(settlementDto.GetBatchParams{Status: "paid"})
+	assert.ErrorIs(t, err, settlement.ErrInvalidStatus)
+}
+
+func TestReport(t *testing.T) {
+	batch := settlementDto.Batch{
+		MerchantName:     "Toko Maju",
+		BusinessDate:     "2024-03-01",
+		Status:           settlementDto.StatusPending,
+		TransactionCount: 2,
+		GrossAmount:      50000,
+		RefundAmount:     10000,
+		FeeAmount:        280,
+		NetAmount:        39720,
+	}
+	createdAt := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	entries := []settlementDto.Entry{
+		{TransactionId: "t1", Type: settlementDto.EntryTypePayment, BillReference: "INV-1", Description: "Merchant-Payment", Amount: 50000, CreatedAt: createdAt},
+		{TransactionId: "t2", Type: settlementDto.EntryTypeRefund, Description: "Merchant-Payment-Reversal", Amount: 10000, CreatedAt: createdAt.Add(time.Hour)},
+	}
+
+	report, err := settlementUsecase.Report(batch, entries)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(report)), "\n")
+	assert.Equal(t, "transaction_id,created_at,type,bill_reference,description,amount", lines[0])
+	assert.Equal(t, "t1,2024-03-01 10:00:00,payment,INV-1,Merchant-Payment,50000", lines[1])
+	assert.Equal(t, "t2,2024-03-01 11:00:00,refund,,Merchant-Payment-Reversal,-10000", lines[2])
+	assert.Equal(t, "net,39720", lines[len(lines)-1])
+}
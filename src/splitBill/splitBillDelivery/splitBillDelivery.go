@@ -0,0 +1,95 @@
+package splitBillDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/splitBillDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/splitBill"
+
+	"github.com/gin-gonic/gin"
+)
+
+type splitBillDelivery struct {
+	splitBillUC splitBill.SplitBillUsecase
+}
+
+func NewSplitBillDelivery(v1Group *gin.RouterGroup, splitBillUC splitBill.SplitBillUsecase) {
+	handler := splitBillDelivery{
+		splitBillUC: splitBillUC,
+	}
+
+	splitBillGroup := v1Group.Group("/user/split-bills")
+	{
+		splitBillGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.create)
+		splitBillGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getAll)
+		splitBillGroup.GET("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getById)
+		splitBillGroup.DELETE("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.cancel)
+	}
+}
+
+func (s *splitBillDelivery) create(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req splitBillDto.CreateSplitBillRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := s.splitBillUC.CreateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Split bill created", "01", "01")
+}
+
+func (s *splitBillDelivery) getAll(ctx *gin.Context) {
+	resp, err := s.splitBillUC.GetAllUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get split bills", "01", "01")
+}
+
+func (s *splitBillDelivery) getById(ctx *gin.Context) {
+	resp, err := s.splitBillUC.GetByIdUC(ctx.GetHeader("Authorization"), ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get split bill", "01", "01")
+}
+
+func (s *splitBillDelivery) cancel(ctx *gin.Context) {
+	if err := s.splitBillUC.CancelUC(ctx.GetHeader("Authorization"), ctx.Param("id")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Split bill cancelled", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, splitBill.ErrNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, splitBill.ErrInvalidShares),
+		errors.Is(err, splitBill.ErrParticipantNotFound),
+		errors.Is(err, splitBill.ErrDuplicateParticipant),
+		errors.Is(err, splitBill.ErrOrganizerIsParticipant):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,28 @@
+package splitBill
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/splitBillDto"
+)
+
+var (
+	ErrNotFound               = errors.New("split bill not found")
+	ErrInvalidShares          = errors.New("every share, the organizer's included, must be at least 5 and the shares cannot exceed the total amount")
+	ErrParticipantNotFound    = errors.New("participant not found")
+	ErrDuplicateParticipant   = errors.New("participant is listed more than once")
+	ErrOrganizerIsParticipant = errors.New("organizer cannot be a participant")
+)
+
+type SplitBillRepository interface {
+	Create(req splitBillDto.CreateSplitBillRequest) (splitBillDto.SplitBill, error)
+	GetByOrganizer(organizerId string) ([]splitBillDto.SplitBill, error)
+	GetById(organizerId, id string) (splitBillDto.SplitBill, error)
+	Cancel(organizerId, id string) error
+}
+
+type SplitBillUsecase interface {
+	CreateUC(req splitBillDto.CreateSplitBillRequest, authHeader string) (splitBillDto.SplitBill, error)
+	GetAllUC(authHeader string) ([]splitBillDto.SplitBill, error)
+	GetByIdUC(authHeader, id string) (splitBillDto.SplitBill, error)
+	CancelUC(authHeader, id string) error
+}
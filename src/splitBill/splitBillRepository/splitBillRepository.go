@@ -0,0 +1,189 @@
+package splitBillRepository
+
+import (
+	"database/sql"
+	"final-project-enigma/model/dto/splitBillDto"
+	"final-project-enigma/pkg/dbtx"
+	splitBillDomain "final-project-enigma/src/splitBill"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type splitBillRepository struct {
+	db *sql.DB
+}
+
+func NewSplitBillRepository(db *sql.DB) splitBillDomain.SplitBillRepository {
+	return &splitBillRepository{
+		db: db,
+	}
+}
+
+// Shares are payment requests, so a share is paid once its request has been
+// accepted. Anything else, including expired and declined requests, counts as
+// unpaid.
+const summaryQuery = `
+	SELECT sb.id, sb.title, sb.total_amount, sb.split_type, sb.organizer_share, sb.created_at,
+		COALESCE(SUM(pr.amount) FILTER (WHERE pr.status = 'accepted'), 0),
+		COALESCE(SUM(pr.amount) FILTER (WHERE pr.status <> 'accepted'), 0),
+		COUNT(pr.id) FILTER (WHERE pr.status = 'accepted'),
+		COUNT(pr.id) FILTER (WHERE pr.status <> 'accepted')
+	FROM split_bills sb
+	LEFT JOIN payment_requests pr ON pr.split_bill_id = sb.id
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSummary(row rowScanner) (splitBillDto.SplitBill, error) {
+	var s splitBillDto.SplitBill
+	err := row.Scan(&s.Id, &s.Title, &s.TotalAmount, &s.SplitType, &s.OrganizerShare, &s.CreatedAt,
+		&s.PaidAmount, &s.UnpaidAmount, &s.PaidShares, &s.UnpaidShares)
+	return s, err
+}
+
+// Create stores the split bill and sends every participant a payment request
+// for their share in the same transaction, so either everyone is asked or
+// nobody is.
+func (repo *splitBillRepository) Create(req splitBillDto.CreateSplitBillRequest) (splitBillDto.SplitBill, error) {
+	var id string
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		payerIds := make([]string, len(req.Participants))
+		payerQuery := `
+			SELECT id FROM users
+			WHERE phone_number = $1 AND status = 'active' AND deleted_at IS NULL
+		`
+		for i, participant := range req.Participants {
+			err := tx.QueryRow(payerQuery, participant.PhoneNumber).Scan(&payerIds[i])
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: %s", splitBillDomain.ErrParticipantNotFound, participant.PhoneNumber)
+			}
+			if err != nil {
+				return err
+			}
+			if payerIds[i] == req.OrganizerId {
+				return splitBillDomain.ErrOrganizerIsParticipant
+			}
+		}
+
+		now := time.Now()
+		billQuery := `
+			INSERT INTO split_bills (organizer_id, title, total_amount, organizer_share, split_type, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`
+		err := tx.QueryRow(billQuery, req.OrganizerId, req.Title, req.TotalAmount, req.OrganizerShare, req.SplitType, now).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("failed to create split bill: %w", err)
+		}
+
+		requestQuery := `
+			INSERT INTO payment_requests (requester_id, payer_id, amount, note, split_bill_id, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		for i, participant := range req.Participants {
+			_, err := tx.Exec(requestQuery, req.OrganizerId, payerIds[i], participant.Amount, req.Title, id, req.ExpiresAt, now)
+			if err != nil {
+				return fmt.Errorf("failed to create payment request: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Msg("failed to create split bill: " + err.Error())
+		return splitBillDto.SplitBill{}, err
+	}
+
+	return repo.GetById(req.OrganizerId, id)
+}
+
+func (repo *splitBillRepository) GetByOrganizer(organizerId string) ([]splitBillDto.SplitBill, error) {
+	query := summaryQuery + `
+		WHERE sb.organizer_id = $1
+		GROUP BY sb.id
+		ORDER BY sb.created_at DESC
+	`
+	rows, err := repo.db.Query(query, organizerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get split bills: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []splitBillDto.SplitBill{}
+	for rows.Next() {
+		bill, err := scanSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan split bill: %w", err)
+		}
+		resp = append(resp, bill)
+	}
+
+	return resp, rows.Err()
+}
+
+func (repo *splitBillRepository) GetById(organizerId, id string) (splitBillDto.SplitBill, error) {
+	query := summaryQuery + `
+		WHERE sb.id = $1 AND sb.organizer_id = $2
+		GROUP BY sb.id
+	`
+	bill, err := scanSummary(repo.db.QueryRow(query, id, organizerId))
+	if err == sql.ErrNoRows {
+		return splitBillDto.SplitBill{}, splitBillDomain.ErrNotFound
+	}
+	if err != nil {
+		return splitBillDto.SplitBill{}, fmt.Errorf("failed to get split bill: %w", err)
+	}
+
+	shareQuery := `
+		SELECT pr.id, u.fullname, u.phone_number, pr.amount,
+			CASE WHEN pr.status = 'pending' AND pr.expires_at <= $2 THEN 'expired' ELSE pr.status END,
+			COALESCE(pr.transaction_id::text, ''), pr.responded_at
+		FROM payment_requests pr
+		JOIN users u ON u.id = pr.payer_id
+		WHERE pr.split_bill_id = $1
+		ORDER BY u.fullname
+	`
+	rows, err := repo.db.Query(shareQuery, id, time.Now())
+	if err != nil {
+		return splitBillDto.SplitBill{}, fmt.Errorf("failed to get split bill shares: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var share splitBillDto.Share
+		if err := rows.Scan(&share.RequestId, &share.ParticipantName, &share.PhoneNumber, &share.Amount,
+			&share.Status, &share.TransactionId, &share.RespondedAt); err != nil {
+			return splitBillDto.SplitBill{}, fmt.Errorf("failed to scan split bill share: %w", err)
+		}
+		bill.Shares = append(bill.Shares, share)
+	}
+
+	return bill, rows.Err()
+}
+
+// Cancel withdraws the shares that are still pending. Shares that were
+// already paid stay paid.
+func (repo *splitBillRepository) Cancel(organizerId, id string) error {
+	var exists bool
+	err := repo.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM split_bills WHERE id = $1 AND organizer_id = $2)`, id, organizerId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to get split bill: %w", err)
+	}
+	if !exists {
+		return splitBillDomain.ErrNotFound
+	}
+
+	query := `
+		UPDATE payment_requests
+		SET status = 'cancelled', responded_at = $1
+		WHERE split_bill_id = $2 AND status = 'pending'
+	`
+	if _, err := repo.db.Exec(query, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to cancel split bill: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package splitBillUsecase
+
+import (
+	"final-project-enigma/model/dto/splitBillDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/paymentRequest"
+	"final-project-enigma/src/paymentRequest/paymentRequestUsecase"
+	"final-project-enigma/src/splitBill"
+	"time"
+)
+
+type splitBillUC struct {
+	splitBillRepo splitBill.SplitBillRepository
+}
+
+func NewSplitBillUsecase(splitBillRepo splitBill.SplitBillRepository) splitBill.SplitBillUsecase {
+	return &splitBillUC{
+		splitBillRepo: splitBillRepo,
+	}
+}
+
+func (usecase *splitBillUC) CreateUC(req splitBillDto.CreateSplitBillRequest, authHeader string) (splitBillDto.SplitBill, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return splitBillDto.SplitBill{}, err
+	}
+	req.OrganizerId = userId
+
+	seen := make(map[string]bool, len(req.Participants))
+	for _, participant := range req.Participants {
+		if seen[participant.PhoneNumber] {
+			return splitBillDto.SplitBill{}, splitBill.ErrDuplicateParticipant
+		}
+		seen[participant.PhoneNumber] = true
+	}
+
+	organizerShare, err := Split(&req)
+	if err != nil {
+		return splitBillDto.SplitBill{}, err
+	}
+	req.OrganizerShare = organizerShare
+	req.ExpiresAt = time.Now().Add(paymentRequestUsecase.TTL())
+
+	return usecase.splitBillRepo.Create(req)
+}
+
+func (usecase *splitBillUC) GetAllUC(authHeader string) ([]splitBillDto.SplitBill, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.splitBillRepo.GetByOrganizer(userId)
+}
+
+func (usecase *splitBillUC) GetByIdUC(authHeader, id string) (splitBillDto.SplitBill, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return splitBillDto.SplitBill{}, err
+	}
+	return usecase.splitBillRepo.GetById(userId, id)
+}
+
+func (usecase *splitBillUC) CancelUC(authHeader, id string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.splitBillRepo.Cancel(userId, id)
+}
+
+// Split fills in each participant's share and returns what is left for the
+// organizer, who already paid the bill. An equal split divides the total
+// between the participants and the organizer, and the organizer absorbs the
+// rupiah that do not divide evenly. A custom split keeps the amounts given.
+// Every share, the organizer's included, must be at least the minimum of a
+// payment request.
+func Split(req *splitBillDto.CreateSplitBillRequest) (money.Money, error) {
+	if req.SplitType == splitBillDto.SplitTypeEqual {
+		share := req.TotalAmount / money.Money(len(req.Participants)+1)
+		if share < paymentRequest.MinAmount {
+			return 0, splitBill.ErrInvalidShares
+		}
+		for i := range req.Participants {
+			req.Participants[i].Amount = share
+		}
+		return req.TotalAmount - share*money.Money(len(req.Participants)), nil
+	}
+
+	remaining := req.TotalAmount
+	for _, participant := range req.Participants {
+		if participant.Amount < paymentRequest.MinAmount {
+			return 0, splitBill.ErrInvalidShares
+		}
+		remaining = remaining.Sub(participant.Amount)
+	}
+	if remaining < paymentRequest.MinAmount {
+		return 0, splitBill.ErrInvalidShares
+	}
+	return remaining, nil
+}
@@ -0,0 +1,82 @@
+package splitBillUsecase_test
+
+import (
+	"final-project-enigma/model/dto/splitBillDto"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/src/splitBill"
+	"final-project-enigma/src/splitBill/splitBillUsecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func participants(amounts ...money.Money) []splitBillDto.Participant {
+	resp := make([]splitBillDto.Participant, len(amounts))
+	for i, amount := range amounts {
+		resp[i] = splitBillDto.Participant{PhoneNumber: string(rune('a' + i)), Amount: amount}
+	}
+	return resp
+}
+
+func TestSplit(t *testing.T) {
+	t.Run("equal split leaves the remainder to the organizer", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeEqual, TotalAmount: 100000, Participants: participants(0, 0)}
+
+		organizerShare, err := splitBillUsecase.Split(&req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, money.Money(33334), organizerShare)
+		assert.Equal(t, money.Money(33333), req.Participants[0].Amount)
+		assert.Equal(t, money.Money(33333), req.Participants[1].Amount)
+	})
+
+	t.Run("equal split too small to share", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeEqual, TotalAmount: 2, Participants: participants(0, 0)}
+
+		_, err := splitBillUsecase.Split(&req)
+
+		assert.ErrorIs(t, err, splitBill.ErrInvalidShares)
+	})
+
+	t.Run("custom split keeps the given amounts", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeCustom, TotalAmount: 100000, Participants: participants(40000, 25000)}
+
+		organizerShare, err := splitBillUsecase.Split(&req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, money.Money(35000), organizerShare)
+		assert.Equal(t, money.Money(40000), req.Participants[0].Amount)
+	})
+
+	t.Run("custom shares over the total", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeCustom, TotalAmount: 50000, Participants: participants(40000, 25000)}
+
+		_, err := splitBillUsecase.Split(&req)
+
+		assert.ErrorIs(t, err, splitBill.ErrInvalidShares)
+	})
+
+	t.Run("custom share below the payment request minimum", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeCustom, TotalAmount: 50000, Participants: participants(40000, 1)}
+
+		_, err := splitBillUsecase.Split(&req)
+
+		assert.ErrorIs(t, err, splitBill.ErrInvalidShares)
+	})
+
+	t.Run("organizer left with less than the minimum", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeCustom, TotalAmount: 50000, Participants: participants(40000, 9998)}
+
+		_, err := splitBillUsecase.Split(&req)
+
+		assert.ErrorIs(t, err, splitBill.ErrInvalidShares)
+	})
+
+	t.Run("custom share missing an amount", func(t *testing.T) {
+		req := splitBillDto.CreateSplitBillRequest{SplitType: splitBillDto.SplitTypeCustom, TotalAmount: 50000, Participants: participants(40000, 0)}
+
+		_, err := splitBillUsecase.Split(&req)
+
+		assert.ErrorIs(t, err, splitBill.ErrInvalidShares)
+	})
+}
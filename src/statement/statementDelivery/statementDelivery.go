@@ -0,0 +1,58 @@
+package statementDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/statementDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/statement"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+type statementDelivery struct {
+	statementUC statement.StatementUsecase
+}
+
+func NewStatementDelivery(v1Group *gin.RouterGroup, statementUC statement.StatementUsecase) {
+	handler := statementDelivery{
+		statementUC: statementUC,
+	}
+
+	statementGroup := v1Group.Group("/user/statements")
+	{
+		statementGroup.GET("/:period", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.get)
+	}
+}
+
+// get serves the statement of :period (YYYY-MM) as a download. ?format picks
+// pdf (the default) or csv and ?pocketId a pocket other than the main one.
+func (s *statementDelivery) get(ctx *gin.Context) {
+	fileName, report, err := s.statementUC.GetStatementUC(ctx.GetHeader("Authorization"), ctx.Param("period"), ctx.Query("pocketId"), ctx.Query("format"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	contentType := "application/pdf"
+	if path.Ext(fileName) == "."+statementDto.FormatCSV {
+		contentType = "text/csv"
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	ctx.Data(http.StatusOK, contentType, report)
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, statement.ErrInvalidPeriod):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "period", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, statement.ErrInvalidFormat):
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "format", Message: err.Error()}}, "bad request", "01", "02")
+	case errors.Is(err, statement.ErrPocketNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,20 @@
+package statement
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/statementDto"
+)
+
+var (
+	ErrPocketNotFound = errors.New("pocket not found")
+	ErrInvalidPeriod  = errors.New("period must be a past or current month as YYYY-MM")
+	ErrInvalidFormat  = errors.New("format must be pdf or csv")
+)
+
+type StatementRepository interface {
+	GetStatement(params statementDto.GetStatementParams) (statementDto.Statement, error)
+}
+
+type StatementUsecase interface {
+	GetStatementUC(authHeader, period, pocketId, format string) (string, []byte, error)
+}
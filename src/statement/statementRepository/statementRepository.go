@@ -0,0 +1,107 @@
+package statementRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/statementDto"
+	"final-project-enigma/pkg/ledger"
+	statementDomain "final-project-enigma/src/statement"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+type statementRepository struct {
+	db *sql.DB
+}
+
+func NewStatementRepository(db *sql.DB) statementDomain.StatementRepository {
+	return &statementRepository{
+		db: db,
+	}
+}
+
+// GetStatement reads the pocket's ledger entries between params.From and
+// params.To. Closed pockets are included so past months stay available. The
+// opening balance is the balance after the last entry before the period; a
+// pocket without earlier entries opens at zero.
+func (repo *statementRepository) GetStatement(params statementDto.GetStatementParams) (statementDto.Statement, error) {
+	resp := statementDto.Statement{From: params.From, To: params.To}
+
+	accountQuery := `
+		SELECT w.id, w.name, w.currency, u.fullname, u.phone_number
+		FROM wallets w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.user_id = $1 AND (($2 = '' AND w.is_main) OR w.id::text = $2)
+	`
+	err := repo.db.QueryRow(accountQuery, params.UserId, params.PocketId).
+		Scan(&resp.PocketId, &resp.PocketName, &resp.Currency, &resp.AccountName, &resp.PhoneNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return statementDto.Statement{}, statementDomain.ErrPocketNotFound
+	}
+	if err != nil {
+		log.Error().Msg("failed to get statement account: " + err.Error())
+		return statementDto.Statement{}, fmt.Errorf("failed to get statement account: %w", err)
+	}
+
+	openingQuery := `
+		SELECT balance_after
+		FROM ledger_entries
+		WHERE account_type = $1 AND account_id = $2 AND created_at < $3
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	err = repo.db.QueryRow(openingQuery, ledger.AccountWallet, resp.PocketId, params.From).Scan(&resp.OpeningBalance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return statementDto.Statement{}, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+
+	// The counterparty of a merchant payment is the merchant, unless the
+	// pocket is the merchant's own wallet, in which case it is the payer.
+	linesQuery := `
+		SELECT
+			t.id,
+			le.created_at,
+			COALESCE(t.description, ''),
+			COALESCE(
+				CASE WHEN m.wallet_id::text = le.account_id THEN payer.fullname ELSE m.merchant_name END,
+				pm.payment_name,
+				ba.bank_code || ' ' || ba.account_number,
+				CASE WHEN cw.user_id = $5 THEN cw.name || ' pocket' ELSE cu.fullname END,
+				''
+			),
+			le.entry_type,
+			le.amount,
+			le.balance_after
+		FROM ledger_entries le
+		JOIN transactions t ON t.id = le.transaction_id
+		LEFT JOIN users payer ON payer.id = t.user_id
+		LEFT JOIN merchant_transactions mt ON mt.transaction_id = t.id
+		LEFT JOIN merchant m ON m.id = mt.merchant_id
+		LEFT JOIN topup_transactions tt ON tt.transaction_id = t.id
+		LEFT JOIN payment_method pm ON pm.id = tt.payment_method_id
+		LEFT JOIN withdrawals wd ON wd.transaction_id = t.id
+		LEFT JOIN bank_accounts ba ON ba.id = wd.bank_account_id
+		LEFT JOIN wallet_transactions wt ON wt.transaction_id = t.id
+		LEFT JOIN wallets cw ON cw.id = CASE WHEN wt.from_wallet_id::text = le.account_id THEN wt.to_wallet_id ELSE wt.from_wallet_id END
+		LEFT JOIN users cu ON cu.id = cw.user_id
+		WHERE le.account_type = $1 AND le.account_id = $2 AND le.created_at >= $3 AND le.created_at < $4
+		ORDER BY le.created_at, le.id
+	`
+	rows, err := repo.db.Query(linesQuery, ledger.AccountWallet, resp.PocketId, params.From, params.To, params.UserId)
+	if err != nil {
+		return statementDto.Statement{}, fmt.Errorf("failed to get statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	resp.Lines = []statementDto.Line{}
+	for rows.Next() {
+		var line statementDto.Line
+		if err := rows.Scan(&line.TransactionId, &line.Date, &line.Description, &line.Counterparty, &line.EntryType, &line.Amount, &line.Balance); err != nil {
+			return statementDto.Statement{}, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		resp.Lines = append(resp.Lines, line)
+	}
+
+	return resp, rows.Err()
+}
@@ -0,0 +1,186 @@
+package statementUsecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"final-project-enigma/model/dto/statementDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/pdf"
+	"fmt"
+)
+
+const (
+	dateFormat     = "02 Jan 2006"
+	dateTimeFormat = "2006-01-02 15:04:05"
+)
+
+// CSV renders the statement with one row per line and the account summary
+// after them. Amounts are in the major unit of the pocket's currency without
+// thousands separators, so spreadsheets read them as numbers.
+func CSV(s statementDto.Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"date", "transaction_id", "description", "counterparty", "debit", "credit", "balance"},
+	}
+	for _, line := range s.Lines {
+		debit, credit := splitAmount(line, func(m money.Money) string { return m.Decimal(s.Currency) })
+		records = append(records, []string{
+			line.Date.Format(dateTimeFormat),
+			line.TransactionId,
+			line.Description,
+			line.Counterparty,
+			debit,
+			credit,
+			line.Balance.Decimal(s.Currency),
+		})
+	}
+	records = append(records,
+		[]string{},
+		[]string{"account_name", s.AccountName},
+		[]string{"phone_number", s.PhoneNumber},
+		[]string{"pocket", s.PocketName},
+		[]string{"pocket_id", s.PocketId},
+		[]string{"currency", s.Currency},
+		[]string{"period_start", s.From.Format("2006-01-02")},
+		[]string{"period_end", s.To.AddDate(0, 0, -1).Format("2006-01-02")},
+		[]string{"opening_balance", s.OpeningBalance.Decimal(s.Currency)},
+		[]string{"total_credit", s.TotalCredit.Decimal(s.Currency)},
+		[]string{"total_debit", s.TotalDebit.Decimal(s.Currency)},
+		[]string{"closing_balance", s.ClosingBalance.Decimal(s.Currency)},
+		[]string{"generated_at", s.GeneratedAt.Format(dateTimeFormat)},
+	)
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to write statement: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF column layout, in points from the left edge of an A4 page. Text columns
+// give their left edge, amount columns their right edge.
+const (
+	marginLeft   = 40.0
+	marginRight  = pdf.PageWidth - 40
+	marginBottom = 60.0
+
+	colDate         = marginLeft
+	colDescription  = 100.0
+	colCounterparty = 255.0
+	colDebit        = 420.0
+	colCredit       = 490.0
+	colBalance      = marginRight
+
+	fontSize  = 8.0
+	rowHeight = 13.0
+)
+
+// PDF renders the statement as a bank-style document: the account and its
+// summary on top, then every line with its running balance, continued over
+// as many pages as needed with the column headings repeated.
+func PDF(s statementDto.Statement) []byte {
+	doc := pdf.New()
+	format := func(m money.Money) string { return m.Format(s.Currency) }
+
+	page := doc.AddPage()
+	page.Text(marginLeft, 790, 16, true, "Account Statement")
+
+	y := 765.0
+	for _, row := range [][2]string{
+		{"Name", s.AccountName},
+		{"Phone number", s.PhoneNumber},
+		{"Pocket", s.PocketName + " (" + s.Currency + ")"},
+		{"Pocket ID", s.PocketId},
+		{"Period", s.From.Format(dateFormat) + " - " + s.To.AddDate(0, 0, -1).Format(dateFormat)},
+	} {
+		page.Text(marginLeft, y, 9, true, row[0])
+		page.Text(marginLeft+75, y, 9, false, row[1])
+		y -= 14
+	}
+
+	y = 765.0
+	for _, row := range [][2]string{
+		{"Opening balance", format(s.OpeningBalance)},
+		{"Total credits", format(s.TotalCredit)},
+		{"Total debits", format(s.TotalDebit)},
+		{"Closing balance", format(s.ClosingBalance)},
+	} {
+		page.Text(360, y, 9, true, row[0])
+		page.TextRight(colBalance, y, 9, false, row[1])
+		y -= 14
+	}
+
+	y = tableHeader(page, 680)
+	page.Text(colDate, y, fontSize, false, s.From.Format(dateFormat))
+	page.Text(colDescription, y, fontSize, true, "Opening balance")
+	page.TextRight(colBalance, y, fontSize, false, format(s.OpeningBalance))
+	y -= rowHeight
+
+	for _, line := range s.Lines {
+		if y < marginBottom {
+			page = doc.AddPage()
+			y = tableHeader(page, 790)
+		}
+		debit, credit := splitAmount(line, format)
+		page.Text(colDate, y, fontSize, false, line.Date.Format(dateFormat))
+		page.Text(colDescription, y, fontSize, false, truncate(line.Description, 30))
+		page.Text(colCounterparty, y, fontSize, false, truncate(line.Counterparty, 24))
+		page.TextRight(colDebit, y, fontSize, false, debit)
+		page.TextRight(colCredit, y, fontSize, false, credit)
+		page.TextRight(colBalance, y, fontSize, false, format(line.Balance))
+		y -= rowHeight
+	}
+	if len(s.Lines) == 0 {
+		page.Text(colDescription, y, fontSize, false, "No transactions in this period.")
+		y -= rowHeight
+	}
+
+	if y < marginBottom {
+		page = doc.AddPage()
+		y = tableHeader(page, 790)
+	}
+	page.Line(marginLeft, y+rowHeight-4, marginRight, y+rowHeight-4)
+	page.Text(colDate, y-2, fontSize, false, s.To.AddDate(0, 0, -1).Format(dateFormat))
+	page.Text(colDescription, y-2, fontSize, true, "Closing balance")
+	page.TextRight(colDebit, y-2, fontSize, true, format(s.TotalDebit))
+	page.TextRight(colCredit, y-2, fontSize, true, format(s.TotalCredit))
+	page.TextRight(colBalance, y-2, fontSize, true, format(s.ClosingBalance))
+
+	pages := doc.Pages()
+	for i, p := range pages {
+		p.Text(marginLeft, 30, 7, false, "Generated "+s.GeneratedAt.Format(dateTimeFormat))
+		p.TextRight(marginRight, 30, 7, false, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return doc.Bytes()
+}
+
+// tableHeader draws the column headings at y and returns where the first row
+// goes.
+func tableHeader(page *pdf.Page, y float64) float64 {
+	page.Text(colDate, y, fontSize, true, "Date")
+	page.Text(colDescription, y, fontSize, true, "Description")
+	page.Text(colCounterparty, y, fontSize, true, "Counterparty")
+	page.TextRight(colDebit, y, fontSize, true, "Debit")
+	page.TextRight(colCredit, y, fontSize, true, "Credit")
+	page.TextRight(colBalance, y, fontSize, true, "Balance")
+	page.Line(marginLeft, y-4, marginRight, y-4)
+	return y - rowHeight - 2
+}
+
+func splitAmount(line statementDto.Line, format func(money.Money) string) (debit, credit string) {
+	if line.EntryType == ledger.EntryCredit {
+		return "", format(line.Amount)
+	}
+	return format(line.Amount), ""
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,80 @@
+package statementUsecase
+
+import (
+	"final-project-enigma/model/dto/statementDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/statement"
+	"strings"
+	"time"
+)
+
+type statementUC struct {
+	statementRepo statement.StatementRepository
+}
+
+func NewStatementUsecase(statementRepo statement.StatementRepository) statement.StatementUsecase {
+	return &statementUC{
+		statementRepo: statementRepo,
+	}
+}
+
+// GetStatementUC renders the statement of one month, given as YYYY-MM, and
+// returns its file name and contents. The current month can be asked for and
+// runs up to now. An empty format means PDF.
+func (usecase *statementUC) GetStatementUC(authHeader, period, pocketId, format string) (string, []byte, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	format = strings.ToLower(format)
+	if format == "" {
+		format = statementDto.FormatPDF
+	}
+	if format != statementDto.FormatPDF && format != statementDto.FormatCSV {
+		return "", nil, statement.ErrInvalidFormat
+	}
+
+	now := time.Now()
+	from, err := time.ParseInLocation("2006-01", period, now.Location())
+	if err != nil || from.After(now) {
+		return "", nil, statement.ErrInvalidPeriod
+	}
+
+	resp, err := usecase.statementRepo.GetStatement(statementDto.GetStatementParams{
+		UserId:   userId,
+		PocketId: pocketId,
+		From:     from,
+		To:       from.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	summarize(&resp)
+	resp.GeneratedAt = now
+
+	fileName := "statement-" + from.Format("2006-01") + "." + format
+	if format == statementDto.FormatCSV {
+		report, err := CSV(resp)
+		if err != nil {
+			return "", nil, err
+		}
+		return fileName, report, nil
+	}
+	return fileName, PDF(resp), nil
+}
+
+// summarize totals the credits and debits of the statement and works out its
+// closing balance from the opening one.
+func summarize(s *statementDto.Statement) {
+	s.TotalCredit, s.TotalDebit = 0, 0
+	for _, line := range s.Lines {
+		if line.EntryType == ledger.EntryCredit {
+			s.TotalCredit += line.Amount
+		} else {
+			s.TotalDebit += line.Amount
+		}
+	}
+	s.ClosingBalance = s.OpeningBalance + s.TotalCredit - s.TotalDebit
+}
@@ -0,0 +1,117 @@
+package statementUsecase_test
+
+import (
+	"bytes"
+	"final-project-enigma/model/dto/statementDto"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/statement"
+	"final-project-enigma/src/statement/statementUsecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStatementRepo struct {
+	statement.StatementRepository
+	params    statementDto.GetStatementParams
+	statement statementDto.Statement
+}
+
+func (m *mockStatementRepo) GetStatement(params statementDto.GetStatementParams) (statementDto.Statement, error) {
+	m.params = params
+	resp := m.statement
+	resp.From, resp.To = params.From, params.To
+	return resp, nil
+}
+
+func authHeader(t *testing.T) string {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", "USER", 1)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func march() statementDto.Statement {
+	at := time.Date(2024, time.March, 5, 9, 30, 0, 0, time.Local)
+	return statementDto.Statement{
+		AccountName:    "Budi Santoso",
+		PhoneNumber:    "081234567890",
+		PocketId:       "wallet-1",
+		PocketName:     "Main",
+		Currency:       "IDR",
+		OpeningBalance: 100000,
+		Lines: []statementDto.Line{
+			{TransactionId: "t1", Date: at, Description: "Top Up", Counterparty: "BCA Virtual Account", EntryType: ledger.EntryCredit, Amount: 250000, Balance: 350000},
+			{TransactionId: "t2", Date: at.Add(time.Hour), Description: "Lunch, split", Counterparty: "Siti", EntryType: ledger.EntryDebit, Amount: 52500, Balance: 297500},
+		},
+	}
+}
+
+func TestGetStatementUC_CSV(t *testing.T) {
+	repo := &mockStatementRepo{statement: march()}
+	uc := statementUsecase.NewStatementUsecase(repo)
+
+	fileName, report, err := uc.GetStatementUC(authHeader(t), "2024-03", "pocket-2", "CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, "statement-2024-03.csv", fileName)
+
+	assert.Equal(t, "user-1", repo.params.UserId)
+	assert.Equal(t, "pocket-2", repo.params.PocketId)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local), repo.params.From)
+	assert.Equal(t, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local), repo.params.To)
+
+	lines := strings.Split(strings.TrimSpace(string(report)), "\n")
+	assert.Equal(t, "date,transaction_id,description,counterparty,debit,credit,balance", lines[0])
+	assert.Equal(t, "2024-03-05 09:30:00,t1,Top Up,BCA Virtual Account,,250000,350000", lines[1])
+	assert.Equal(t, `2024-03-05 10:30:00,t2,"Lunch, split",Siti,52500,,297500`, lines[2])
+	assert.Contains(t, lines, "period_start,2024-03-01")
+	assert.Contains(t, lines, "period_end,2024-03-31")
+	assert.Contains(t, lines, "opening_balance,100000")
+	assert.Contains(t, lines, "total_credit,250000")
+	assert.Contains(t, lines, "total_debit,52500")
+	assert.Contains(t, lines, "closing_balance,297500")
+}
+
+func TestGetStatementUC_CSVForeignCurrency(t *testing.T) {
+	s := march()
+	s.Currency = "USD"
+	uc := statementUsecase.NewStatementUsecase(&mockStatementRepo{statement: s})
+
+	_, report, err := uc.GetStatementUC(authHeader(t), "2024-03", "", "csv")
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), "\nclosing_balance,2975.00\n")
+}
+
+func TestGetStatementUC_PDF(t *testing.T) {
+	s := march()
+	line := s.Lines[1]
+	for i := 0; i < 80; i++ {
+		line.Balance -= line.Amount
+		s.Lines = append(s.Lines, line)
+	}
+	s.OpeningBalance = 10000000
+	uc := statementUsecase.NewStatementUsecase(&mockStatementRepo{statement: s})
+
+	fileName, report, err := uc.GetStatementUC(authHeader(t), "2024-03", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "statement-2024-03.pdf", fileName)
+	assert.True(t, bytes.HasPrefix(report, []byte("%PDF-")))
+	assert.Contains(t, string(report), "/Count 2")
+	assert.Contains(t, string(report), "(Page 2 of 2) Tj")
+	assert.Contains(t, string(report), "(10,000,000) Tj")
+	assert.Contains(t, string(report), "(01 Mar 2024 - 31 Mar 2024) Tj")
+}
+
+func TestGetStatementUC_InvalidRequest(t *testing.T) {
+	uc := statementUsecase.NewStatementUsecase(&mockStatementRepo{})
+
+	_, _, err := uc.GetStatementUC(authHeader(t), "2024-03", "", "xlsx")
+	assert.ErrorIs(t, err, statement.ErrInvalidFormat)
+
+	for _, period := range []string{"2024-13", "03-2024", "", time.Now().AddDate(0, 1, 0).Format("2006-01")} {
+		_, _, err = uc.GetStatementUC(authHeader(t), period, "", "pdf")
+		assert.ErrorIs(t, err, statement.ErrInvalidPeriod, period)
+	}
+}
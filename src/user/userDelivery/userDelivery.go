@@ -1,9 +1,16 @@
 package userDelivery
 
 import (
+	"errors"
 	"final-project-enigma/model/dto/json"
 	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
 	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/qris"
+	"final-project-enigma/pkg/txauth"
 	"final-project-enigma/pkg/validation"
 	"final-project-enigma/src/user"
 
@@ -11,25 +18,29 @@ import (
 )
 
 type userDelivery struct {
-	userUC user.UserUsecase
+	userUC      user.UserUsecase
+	idempotency gin.HandlerFunc
 }
 
-func NewUserDelivery(v1Group *gin.RouterGroup, userUC user.UserUsecase) {
+func NewUserDelivery(v1Group *gin.RouterGroup, userUC user.UserUsecase, idempotencyStore idempotency.Store) {
 	handler := userDelivery{
-		userUC: userUC,
+		userUC:      userUC,
+		idempotency: middleware.Idempotency(idempotencyStore, middleware.IdempotencyRetention()),
 	}
 
 	userGroup := v1Group.Group("/user")
 	{
-		userGroup.GET("/info", middleware.JwtAuthWithRoles("USER"), handler.getDataUser)
-		userGroup.POST("/info/upload-image", middleware.JwtAuthWithRoles("USER"), handler.uploadProfilImage)
-		userGroup.GET("/info/transactions", middleware.JwtAuthWithRoles("USER"), handler.getTransactionsDetail)
-		userGroup.GET("/balance", middleware.JwtAuthWithRoles("USER"), handler.getBalanceInfo)
-		userGroup.POST("/balance/topup", middleware.JwtAuthWithRoles("USER"), handler.topupTransactionRequest)
-		userGroup.POST("/balance/transfer", middleware.JwtAuthWithRoles("USER"), handler.walletTransactionRequest)
-		userGroup.POST("/balance/merchant-payment", middleware.JwtAuthWithRoles("USER"), handler.merchantTransactionRequest)
-		userGroup.PUT("/info/update", middleware.JwtAuthWithRoles("USER"), handler.updateDataUser)
-		userGroup.DELETE("/delete", middleware.JwtAuthWithRoles("USER"), handler.deletedUser)
+		userGroup.GET("/info", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getDataUser)
+		userGroup.POST("/info/upload-image", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.uploadProfilImage)
+		userGroup.GET("/info/transactions", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getTransactionsDetail)
+		userGroup.GET("/balance", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getBalanceInfo)
+		userGroup.POST("/balance/topup", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.topupTransactionRequest)
+		userGroup.POST("/balance/transfer", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.walletTransactionRequest)
+		userGroup.POST("/balance/merchant-payment", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.merchantTransactionRequest)
+		userGroup.POST("/balance/pay-qr", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.qrPaymentRequest)
+		userGroup.GET("/fees/quote", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.feeQuote)
+		userGroup.PUT("/info/update", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.updateDataUser)
+		userGroup.DELETE("/delete", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.deletedUser)
 	}
 }
 
@@ -141,6 +152,21 @@ func (u *userDelivery) topupTransactionRequest(ctx *gin.Context) {
 
 	resp, err := u.userUC.TopUpTransaction(req, authHeader)
 	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrPaymentMethodNotFound), errors.Is(err, user.ErrPaymentMethodDisabled):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+			return
+		case errors.Is(err, user.ErrTopUpBelowMinimum), errors.Is(err, user.ErrTopUpAboveMaximum):
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+			return
+		case errors.Is(err, ledger.ErrWalletFrozen):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "10")
+			return
+		}
+		if code, ok := limitErrorCode(err); ok {
+			json.NewResponseForbidden(ctx, err.Error(), "01", code)
+			return
+		}
 		json.NewResponseError(ctx, err.Error(), "01", "01")
 		return
 	}
@@ -163,7 +189,7 @@ func (u *userDelivery) walletTransactionRequest(ctx *gin.Context) {
 
 	resp, err := u.userUC.WalletTransaction(req, authHeader)
 	if err != nil {
-		json.NewResponseForbidden(ctx, err.Error(), "01", "01")
+		transactionErrorResponse(ctx, err)
 		return
 	}
 	json.NewResponSucces(ctx, resp, "Transfer succes", "01", "01")
@@ -185,12 +211,110 @@ func (u *userDelivery) merchantTransactionRequest(ctx *gin.Context) {
 
 	transactionId, err := u.userUC.MerchantTransaction(req, authHeader)
 	if err != nil {
-		json.NewResponseForbidden(ctx, err.Error(), "01", "01")
+		transactionErrorResponse(ctx, err)
 		return
 	}
 	json.NewResponSucces(ctx, transactionId, "Payment merchant success", "01", "01")
 }
 
+func (u *userDelivery) qrPaymentRequest(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req userDto.QRPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := u.userUC.QRPayment(req, authHeader)
+	if err != nil {
+		switch {
+		case errors.Is(err, qris.ErrInvalidPayload), errors.Is(err, qris.ErrChecksumMismatch),
+			errors.Is(err, qris.ErrUnsupportedMerchant), errors.Is(err, qris.ErrUnsupportedCurrency):
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "payload", Message: err.Error()}}, "bad request", "01", "02")
+		case errors.Is(err, money.ErrInvalidAmount), errors.Is(err, user.ErrAmountMismatch):
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+		case errors.Is(err, user.ErrBillAlreadyPaid):
+			json.NewResponseConflict(ctx, err.Error(), "01", "09")
+		default:
+			transactionErrorResponse(ctx, err)
+		}
+		return
+	}
+	json.NewResponSucces(ctx, resp, "Payment merchant success", "01", "01")
+}
+
+func (u *userDelivery) feeQuote(ctx *gin.Context) {
+	var params userDto.FeeQuoteParams
+	params.TransactionType = ctx.Query("transactionType")
+	params.PaymentMethodId = ctx.Query("paymentMethodId")
+
+	amount, err := money.Parse(ctx.Query("amount"))
+	if err != nil {
+		json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+		return
+	}
+	params.Amount = amount
+
+	resp, err := u.userUC.FeeQuoteUC(params)
+	if err != nil {
+		switch {
+		case errors.Is(err, limits.ErrUnknownType):
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "transactionType", Message: err.Error()}}, "bad request", "01", "02")
+		case errors.Is(err, money.ErrInvalidAmount):
+			json.NewResponBadRequest(ctx, []json.ValidationField{{FieldName: "amount", Message: err.Error()}}, "bad request", "01", "02")
+		default:
+			json.NewResponseError(ctx, err.Error(), "01", "01")
+		}
+		return
+	}
+	json.NewResponSucces(ctx, resp, "Success get fee quote", "01", "01")
+}
+
+// limitErrorCodes gives every limit violation its own error code so clients
+// can tell which cap was hit.
+var limitErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{limits.ErrPerTransactionLimit, "05"},
+	{limits.ErrDailyLimit, "06"},
+	{limits.ErrMonthlyLimit, "07"},
+	{limits.ErrMaxBalance, "08"},
+}
+
+func limitErrorCode(err error) (string, bool) {
+	for _, l := range limitErrorCodes {
+		if errors.Is(err, l.err) {
+			return l.code, true
+		}
+	}
+	return "", false
+}
+
+func transactionErrorResponse(ctx *gin.Context, err error) {
+	if code, ok := limitErrorCode(err); ok {
+		json.NewResponseForbidden(ctx, err.Error(), "01", code)
+		return
+	}
+
+	switch {
+	case errors.Is(err, txauth.ErrInvalidPin):
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrPinLocked):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, ledger.ErrWalletFrozen):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "10")
+	default:
+		json.NewResponseForbidden(ctx, err.Error(), "01", "01")
+	}
+}
+
 func (u *userDelivery) deletedUser(ctx *gin.Context) {
 	authHeader := ctx.GetHeader("Authorization")
 
@@ -1,6 +1,26 @@
 package user
 
-import "final-project-enigma/model/dto/userDto"
+import (
+	"errors"
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/paymentGateway"
+)
+
+// MinMerchantPaymentAmount is the smallest amount a merchant can be paid. It
+// matches the min=5 the payment requests are bound with, and also holds for
+// amounts that do not come from a request body, such as a QR's.
+const MinMerchantPaymentAmount money.Money = 5
+
+var (
+	ErrBillAlreadyPaid = errors.New("bill has already been paid")
+	ErrAmountMismatch  = errors.New("amount does not match the QR")
+
+	ErrPaymentMethodNotFound = errors.New("payment method not registered")
+	ErrPaymentMethodDisabled = errors.New("payment method is not available")
+	ErrTopUpBelowMinimum     = errors.New("top up amount is below the payment method minimum")
+	ErrTopUpAboveMaximum     = errors.New("top up amount is above the payment method maximum")
+)
 
 type UserRepository interface {
 	UserUploadImage(req userDto.UploadImagesRequest) (userDto.UploadImagesResponse, error)
@@ -8,13 +28,13 @@ type UserRepository interface {
 	GetDataUserRepo(id string) (userDto.UserGetDataResponse, error)
 	GetBalanceInfoRepo(id string) (resp userDto.UserGetDataResponse, err error)
 	GetTransactionRepo(params userDto.GetTransactionParams) ([]userDto.GetTransactionResponse, int, error)
-	CreateTopUpTransaction(req userDto.TopUpTransactionRequest) (string, error)
-	GetPaymentMethodName(id string) (metdhodName string, err error)
+	CreateTopUpTransaction(req userDto.TopUpTransactionRequest) (userDto.TopUpTransactionResponse, error)
+	GetPaymentMethod(id string) (paymentGateway.Method, error)
 	GetUserFullname(id string) (userFullname string, err error)
-	PaymentGateway(payload userDto.MidtransSnapReq) (userDto.MidtransSnapResp, error)
 	InsertPaymentURL(transactionId, url string) error
-	CreateWalletTransaction(req userDto.WalletTransactionRequest) (userDto.WalletTransactionResponse, string, error)
-	CreateMerchantTransaction(req userDto.MerchantTransactionRequest) (string, error)
+	CreateWalletTransaction(req userDto.WalletTransactionRequest) (userDto.WalletTransactionResponse, error)
+	CreateMerchantTransaction(req userDto.MerchantTransactionRequest) (userDto.MerchantTransactionResponse, error)
+	GetFeeQuote(params userDto.FeeQuoteParams) (userDto.FeeQuoteResponse, error)
 	EditUserData(req userDto.UserUpdateReq) error
 	DeleteUser(id string) error
 }
@@ -25,9 +45,11 @@ type UserUsecase interface {
 		UserGetDataResponse, error)
 	GetBalanceInfoUC(authHeader string) (resp userDto.UserGetDataResponse, err error)
 	GetTransactionUC(authHeader string, params userDto.GetTransactionParams) ([]userDto.GetTransactionResponse, string, error)
-	TopUpTransaction(req userDto.TopUpTransactionRequest, authHeader string) (userDto.MidtransSnapResp, error)
+	TopUpTransaction(req userDto.TopUpTransactionRequest, authHeader string) (userDto.TopUpCheckoutResponse, error)
 	WalletTransaction(req userDto.WalletTransactionRequest, authHeader string) (userDto.WalletTransactionResponse, error)
 	MerchantTransaction(req userDto.MerchantTransactionRequest, authHeader string) (resp userDto.MerchantTransactionResponse, err error)
+	QRPayment(req userDto.QRPaymentRequest, authHeader string) (userDto.MerchantTransactionResponse, error)
+	FeeQuoteUC(params userDto.FeeQuoteParams) (userDto.FeeQuoteResponse, error)
 	EditDataUserUC(authHeader string, req userDto.UserUpdateReq) error
 	DeleteUser(authHeader string) error
 }
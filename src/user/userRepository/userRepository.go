@@ -3,10 +3,16 @@ package userRepository
 import (
 	"context"
 	"database/sql"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"final-project-enigma/model/dto/pocketDto"
 	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/paymentGateway"
 	"final-project-enigma/src/user"
 	"fmt"
 	"os"
@@ -16,6 +22,7 @@ import (
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/go-resty/resty/v2"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
@@ -135,14 +142,44 @@ func (repo *userRepository) GetDataUserRepo(id string) (resp userDto.UserGetData
 	return resp, nil
 }
 
+// GetBalanceInfoRepo returns the total IDR balance across the user's open
+// pockets along with the balance of each one, main pocket first.
 func (repo *userRepository) GetBalanceInfoRepo(id string) (resp userDto.UserGetDataResponse, err error) {
 
-	query := "SELECT balance FROM wallets WHERE user_id = $1 AND deleted_at IS NULL;"
-	if err := repo.db.QueryRow(query, id).Scan(&resp.Balance); err != nil {
+	query := `
+		SELECT id, name, is_main, currency, balance, created_at
+		FROM wallets
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY is_main DESC, created_at
+	`
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
 		log.Error().Msg("fail to get data db")
 		return resp, errors.New("fail to get data db")
 	}
+	defer rows.Close()
+
+	var total money.Money
+	for rows.Next() {
+		var p pocketDto.Pocket
+		if err := rows.Scan(&p.Id, &p.Name, &p.IsMain, &p.Currency, &p.Balance, &p.CreatedAt); err != nil {
+			log.Error().Msg("fail to get data db")
+			return resp, errors.New("fail to get data db")
+		}
+		if p.Currency == money.Currency {
+			total += p.Balance
+		}
+		resp.Pockets = append(resp.Pockets, p)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, err
+	}
+	if len(resp.Pockets) == 0 {
+		log.Error().Msg("wallet not found")
+		return resp, errors.New("fail to get data db")
+	}
 
+	resp.Balance = total.String()
 	return resp, nil
 }
 
@@ -151,6 +188,7 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 		SELECT
 			t.id,
 			t.amount,
+			t.currency,
 			t.description,
 			t.created_at,
 			t.status
@@ -164,6 +202,7 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 		SELECT
 			t.id,
 			t.amount,
+			t.currency,
 			t.description,
 			t.created_at,
 			t.status
@@ -208,6 +247,7 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 		SELECT
 			id,
 			amount,
+			currency,
 			description,
 			created_at,
 			status
@@ -252,7 +292,7 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 
 	for rows.Next() {
 		var transaction userDto.GetTransactionResponse
-		if err := rows.Scan(&transaction.TransactionId, &transaction.Amount, &transaction.Description, &transaction.TransactionDate, &transaction.Status); err != nil {
+		if err := rows.Scan(&transaction.TransactionId, &transaction.Amount, &transaction.Currency, &transaction.Description, &transaction.TransactionDate, &transaction.Status); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan transaction data: %w", err)
 		}
 
@@ -329,6 +369,27 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 			transaction.Detail.MerchantName = merchantName.String
 		}
 
+		reversalQuery := `
+			SELECT
+				t.reversal_of,
+				r.id,
+				COALESCE(t.reversal_reason, r.reversal_reason)
+			FROM
+				transactions t
+			LEFT JOIN
+				transactions r ON r.reversal_of = t.id
+			WHERE
+				t.id = $1
+		`
+		var reversalOf, reversedBy, reversalReason sql.NullString
+		err = repo.db.QueryRow(reversalQuery, transaction.TransactionId).Scan(&reversalOf, &reversedBy, &reversalReason)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, 0, fmt.Errorf("failed to query transaction reversal: %w", err)
+		}
+		transaction.Detail.ReversalOf = reversalOf.String
+		transaction.Detail.ReversedBy = reversedBy.String
+		transaction.Detail.ReversalReason = reversalReason.String
+
 		resp = append(resp, transaction)
 	}
 
@@ -343,15 +404,19 @@ func (repo *userRepository) GetTransactionRepo(params userDto.GetTransactionPara
 	return resp, totalData, nil
 }
 
-func (repo *userRepository) GetPaymentMethodName(id string) (metdhodName string, err error) {
-
-	query := "SELECT payment_name FROM payment_method WHERE id = $1;"
-	if err := repo.db.QueryRow(query, id).Scan(&metdhodName); err != nil {
-		log.Error().Msg("fail to get payment method name")
-		return "", errors.New("fail to get payment method name")
+func (repo *userRepository) GetPaymentMethod(id string) (paymentGateway.Method, error) {
+	var method paymentGateway.Method
+	var config []byte
+	query := "SELECT id, payment_name, gateway_code, expiry_minutes, gateway_config FROM payment_method WHERE id = $1;"
+	if err := repo.db.QueryRow(query, id).Scan(&method.Id, &method.Name, &method.Code, &method.ExpiryMinutes, &config); err != nil {
+		log.Error().Msg("fail to get payment method")
+		return paymentGateway.Method{}, errors.New("fail to get payment method")
+	}
+	if err := json.Unmarshal(config, &method.Config); err != nil {
+		return paymentGateway.Method{}, fmt.Errorf("invalid gateway config of payment method %s: %w", id, err)
 	}
 
-	return metdhodName, nil
+	return method, nil
 }
 
 func (repo *userRepository) GetUserFullname(id string) (userFullname string, err error) {
@@ -365,37 +430,83 @@ func (repo *userRepository) GetUserFullname(id string) (userFullname string, err
 	return userFullname, nil
 }
 
-func (repo *userRepository) CreateTopUpTransaction(req userDto.TopUpTransactionRequest) (string, error) {
+func (repo *userRepository) CreateTopUpTransaction(req userDto.TopUpTransactionRequest) (userDto.TopUpTransactionResponse, error) {
 	tx, err := repo.db.Begin()
 	if err != nil {
-		return "", err
+		return userDto.TopUpTransactionResponse{}, err
 	}
 
-	var validPaymentMethod bool
-	checkPaymentMethodQuery := `
-		SELECT EXISTS (
-			SELECT 1
-			FROM payment_method
-			WHERE id = $1
-		)
+	var enabled bool
+	var minAmount, maxAmount *money.Money
+	paymentMethodQuery := `
+		SELECT enabled, min_amount, max_amount
+		FROM payment_method
+		WHERE id::text = $1 AND deleted_at IS NULL
 	`
-	err = tx.QueryRow(checkPaymentMethodQuery, req.PaymentMethodId).Scan(&validPaymentMethod)
-	if err != nil {
+	err = tx.QueryRow(paymentMethodQuery, req.PaymentMethodId).Scan(&enabled, &minAmount, &maxAmount)
+	if err == sql.ErrNoRows {
 		tx.Rollback()
 		log.Error().Msg("payment method not registered")
-		return "", errors.New("payment method not registered")
+		return userDto.TopUpTransactionResponse{}, user.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, err
+	}
+	switch {
+	case !enabled:
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, user.ErrPaymentMethodDisabled
+	case minAmount != nil && req.Amount < *minAmount:
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, fmt.Errorf("%w of %s", user.ErrTopUpBelowMinimum, minAmount)
+	case maxAmount != nil && req.Amount > *maxAmount:
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, fmt.Errorf("%w of %s", user.ErrTopUpAboveMaximum, maxAmount)
+	}
+
+	var walletId, walletStatus string
+	getWalletIdQuery := `SELECT id, status FROM wallets WHERE user_id = $1 AND is_main`
+	err = tx.QueryRow(getWalletIdQuery, req.UserId).Scan(&walletId, &walletStatus)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Msg("wallet not found")
+		return userDto.TopUpTransactionResponse{}, ledger.ErrWalletNotFound
+	}
+	if !ledger.CanMove(walletStatus, ledger.EntryCredit) {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, ledger.ErrWalletFrozen
+	}
+
+	if _, err := ledger.LockWallets(tx, walletId); err != nil {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, err
+	}
+	if err := limits.Check(tx, req.UserId, limits.TypeTopUp, req.Amount); err != nil {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, err
+	}
+	if err := limits.CheckBalance(tx, walletId, req.Amount); err != nil {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, err
+	}
+
+	fee, err := fees.Quote(tx, limits.TypeTopUp, req.PaymentMethodId, req.Amount)
+	if err != nil {
+		tx.Rollback()
+		return userDto.TopUpTransactionResponse{}, err
 	}
 
 	transactionQuery := `
-		INSERT INTO transactions (user_id, transaction_type, amount, description, created_at, status)
-		VALUES ($1, 'credit', $2, $3, $4, 'pending')
+		INSERT INTO transactions (user_id, transaction_type, amount, fee, description, created_at, status)
+		VALUES ($1, 'credit', $2, $3, $4, $5, 'pending')
 		RETURNING id
 	`
 	var transactionID string
-	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, req.Description, time.Now()).Scan(&transactionID)
+	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, fee, req.Description, time.Now()).Scan(&transactionID)
 	if err != nil {
 		tx.Rollback()
-		return "", err
+		return userDto.TopUpTransactionResponse{}, err
 	}
 
 	topupTransactionQuery := `
@@ -405,37 +516,14 @@ func (repo *userRepository) CreateTopUpTransaction(req userDto.TopUpTransactionR
 	_, err = tx.Exec(topupTransactionQuery, transactionID, req.PaymentMethodId, time.Now())
 	if err != nil {
 		tx.Rollback()
-		return "", err
+		return userDto.TopUpTransactionResponse{}, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return "", err
-	}
-
-	return transactionID, nil
-}
-
-func (repo *userRepository) PaymentGateway(payload userDto.MidtransSnapReq) (userDto.MidtransSnapResp, error) {
-	url := "https://app.sandbox.midtrans.com/snap/v1/transactions"
-	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
-	encodeKey := base64.StdEncoding.EncodeToString([]byte(serverKey))
-
-	resp, err := repo.client.R().SetHeader("Authorization", "Basic "+encodeKey).SetBody(payload).Post(url)
-
-	if err != nil {
-		return userDto.MidtransSnapResp{}, err
-	}
-
-	var snapResp userDto.MidtransSnapResp
-	err = json.Unmarshal(resp.Body(), &snapResp)
-	if err != nil {
-		return userDto.MidtransSnapResp{}, err
+		return userDto.TopUpTransactionResponse{}, err
 	}
 
-	redirectUrl := fmt.Sprintf("https://app.sandbox.midtrans.com/snap/v2/vtweb/%s", snapResp.Token)
-	snapResp.RedirectUrl = redirectUrl
-
-	return snapResp, nil
+	return userDto.TopUpTransactionResponse{TransactionId: transactionID, Fee: fee}, nil
 }
 
 func (repo *userRepository) InsertPaymentURL(transactionId, url string) (err error) {
@@ -452,81 +540,88 @@ func (repo *userRepository) InsertPaymentURL(transactionId, url string) (err err
 	return nil
 }
 
-func (repo *userRepository) CreateWalletTransaction(req userDto.WalletTransactionRequest) (userDto.WalletTransactionResponse, string, error) {
-	tx, err := repo.db.Begin()
-	if err != nil {
-		return userDto.WalletTransactionResponse{}, "", err
-	}
+func (repo *userRepository) CreateWalletTransaction(req userDto.WalletTransactionRequest) (userDto.WalletTransactionResponse, error) {
+	var resp userDto.WalletTransactionResponse
 
-	getWalletIdQuery := `SELECT id FROM wallets WHERE user_id = $1`
-	err = tx.QueryRow(getWalletIdQuery, req.UserId).Scan(&req.FromWalletId)
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var err error
+		resp, err = WalletTransfer(tx, req)
+		return err
+	})
 	if err != nil {
-		tx.Rollback()
-		log.Error().Msg("disana: %v" + err.Error())
-		return userDto.WalletTransactionResponse{}, "", fmt.Errorf("disana: %v", err)
+		return userDto.WalletTransactionResponse{}, err
 	}
 
-	validatePinQuery := `SELECT pin FROM users WHERE id = $1 AND deleted_at IS NULL`
-	var storedPin string
-	err = tx.QueryRow(validatePinQuery, req.UserId).Scan(&storedPin)
+	return resp, nil
+}
+
+// WalletTransfer moves req.Amount (plus its fee) from req.UserId's wallet to
+// the wallet of req.RecipientPhoneNumber inside tx, enforcing balance, limits
+// and fees. Flows that must commit a transfer together with their own rows,
+// such as accepting a payment request, call it from their own transaction.
+func WalletTransfer(tx *sql.Tx, req userDto.WalletTransactionRequest) (userDto.WalletTransactionResponse, error) {
+	var senderStatus, recipientStatus string
+	getWalletIdQuery := `SELECT id, status FROM wallets WHERE user_id = $1 AND is_main`
+	err := tx.QueryRow(getWalletIdQuery, req.UserId).Scan(&req.FromWalletId, &senderStatus)
 	if err != nil {
-		tx.Rollback()
-		log.Error().Msg("user not found")
-		return userDto.WalletTransactionResponse{}, "", errors.New("user not found")
+		log.Error().Msg("sender wallet not found: " + err.Error())
+		return userDto.WalletTransactionResponse{}, errors.New("sender wallet not found")
+	}
+	if !ledger.CanMove(senderStatus, ledger.EntryDebit) {
+		return userDto.WalletTransactionResponse{}, ledger.ErrWalletFrozen
 	}
 
 	getRecipientWalletIdQuery := `
-		SELECT w.id 
+		SELECT w.id, w.status
 		FROM wallets w
 		JOIN users u ON u.id = w.user_id
-		WHERE u.phone_number = $1 AND u.status = 'active' AND u.deleted_at IS NULL
+		WHERE u.phone_number = $1 AND u.status = 'active' AND u.deleted_at IS NULL AND w.is_main
 	`
-	err = tx.QueryRow(getRecipientWalletIdQuery, req.RecipientPhoneNumber).Scan(&req.ToWalletId)
+	err = tx.QueryRow(getRecipientWalletIdQuery, req.RecipientPhoneNumber).Scan(&req.ToWalletId, &recipientStatus)
 	if err != nil {
-		tx.Rollback()
 		log.Error().Msg("recipient not found")
-		return userDto.WalletTransactionResponse{}, "", errors.New("recipient not found")
+		return userDto.WalletTransactionResponse{}, errors.New("recipient not found")
+	}
+	if !ledger.CanMove(recipientStatus, ledger.EntryCredit) {
+		return userDto.WalletTransactionResponse{}, fmt.Errorf("recipient %w", ledger.ErrWalletFrozen)
 	}
 
 	if req.FromWalletId == req.ToWalletId {
-		tx.Rollback()
 		log.Error().Msg("sender and recipient cannot be the same")
-		return userDto.WalletTransactionResponse{}, "", errors.New("sender and recipient cannot be the same")
+		return userDto.WalletTransactionResponse{}, errors.New("sender and recipient cannot be the same")
 	}
 
-	var senderBalance float64
-	balanceQuery := `SELECT balance FROM wallets WHERE id = $1`
-	err = tx.QueryRow(balanceQuery, req.FromWalletId).Scan(&senderBalance)
+	fee, err := fees.Quote(tx, limits.TypeTransfer, "", req.Amount)
 	if err != nil {
-		tx.Rollback()
-		log.Error().Msg("invalid amount: %v" + err.Error())
-		return userDto.WalletTransactionResponse{}, "", fmt.Errorf("invalid amount: %v", err)
+		return userDto.WalletTransactionResponse{}, err
 	}
 
-	if senderBalance < req.Amount {
-		tx.Rollback()
+	balances, err := ledger.LockWallets(tx, append([]string{req.FromWalletId, req.ToWalletId}, fees.WalletIds(fee)...)...)
+	if err != nil {
+		return userDto.WalletTransactionResponse{}, err
+	}
+
+	if balances[req.FromWalletId] < req.Amount+fee {
 		log.Error().Msg("insufficient balance")
-		return userDto.WalletTransactionResponse{}, "", errors.New("insufficient balance")
+		return userDto.WalletTransactionResponse{}, ledger.ErrInsufficientBalance
 	}
 
-	var recipientBalance float64
-	err = tx.QueryRow(balanceQuery, req.ToWalletId).Scan(&recipientBalance)
-	if err != nil {
-		tx.Rollback()
-		log.Error().Msg("recipient wallet not found")
-		return userDto.WalletTransactionResponse{}, "", errors.New("recipient wallet not found")
+	if err := limits.Check(tx, req.UserId, limits.TypeTransfer, req.Amount); err != nil {
+		return userDto.WalletTransactionResponse{}, err
+	}
+	if err := limits.CheckBalance(tx, req.ToWalletId, req.Amount); err != nil {
+		return userDto.WalletTransactionResponse{}, err
 	}
 
 	transactionQuery := `
-		INSERT INTO transactions (user_id, transaction_type, amount, description, created_at, status)
-		VALUES ($1, 'debit', $2, $3, $4, 'success')
+		INSERT INTO transactions (user_id, transaction_type, amount, fee, description, created_at, status)
+		VALUES ($1, 'debit', $2, $3, $4, $5, 'success')
 		RETURNING id
 	`
 	var transactionID string
-	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, req.Description, time.Now()).Scan(&transactionID)
+	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, fee, req.Description, time.Now()).Scan(&transactionID)
 	if err != nil {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", err
+		return userDto.WalletTransactionResponse{}, err
 	}
 
 	walletTransactionQuery := `
@@ -535,136 +630,128 @@ func (repo *userRepository) CreateWalletTransaction(req userDto.WalletTransactio
 	`
 	_, err = tx.Exec(walletTransactionQuery, transactionID, req.FromWalletId, req.ToWalletId, time.Now())
 	if err != nil {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", err
+		return userDto.WalletTransactionResponse{}, err
 	}
 
-	currentTime := time.Now()
+	entries := append([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, req.FromWalletId, req.Amount+fee),
+		ledger.Credit(ledger.AccountWallet, req.ToWalletId, req.Amount),
+	}, fees.Entries(fee)...)
+	if err := ledger.Post(tx, transactionID, entries...); err != nil {
+		return userDto.WalletTransactionResponse{}, err
+	}
 
-	updateSenderBalanceQuery := `
-		UPDATE wallets
-		SET balance = balance - $1, updated_at = $2
-		WHERE id = $3 AND balance >= $1
-	`
+	return userDto.WalletTransactionResponse{TransactionId: transactionID, Fee: fee}, nil
+}
 
-	res, err := tx.Exec(updateSenderBalanceQuery, req.Amount, currentTime, req.FromWalletId)
-	if err != nil {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", err
-	}
+func (repo *userRepository) CreateMerchantTransaction(req userDto.MerchantTransactionRequest) (userDto.MerchantTransactionResponse, error) {
+	var resp userDto.MerchantTransactionResponse
 
-	rowsAffected, err := res.RowsAffected()
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var err error
+		resp, err = MerchantPayment(tx, req)
+		return err
+	})
 	if err != nil {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", err
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	if rowsAffected < 1 {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", errors.New("insufficient balance after check")
-	}
+	return resp, nil
+}
 
-	updateRecipientBalanceQuery := `
-		UPDATE wallets
-		SET balance = balance + $1, updated_at = $2
-		WHERE id = $3
-	`
-	_, err = tx.Exec(updateRecipientBalanceQuery, req.Amount, currentTime, req.ToWalletId)
-	if err != nil {
-		tx.Rollback()
-		return userDto.WalletTransactionResponse{}, "", err
+// MerchantPayment debits req.Amount (plus its fee) from req.UserId's wallet
+// and credits the merchant's wallet inside tx, like WalletTransfer does for
+// transfers. Merchant-initiated charges call it when the customer approves.
+func MerchantPayment(tx *sql.Tx, req userDto.MerchantTransactionRequest) (userDto.MerchantTransactionResponse, error) {
+	if req.Amount < user.MinMerchantPaymentAmount {
+		return userDto.MerchantTransactionResponse{}, money.ErrInvalidAmount
 	}
 
-	if err := tx.Commit(); err != nil {
-		return userDto.WalletTransactionResponse{}, "", err
+	var merchantWalletId string
+	checkMerchantQuery := `
+		SELECT wallet_id
+		FROM merchant
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	err := tx.QueryRow(checkMerchantQuery, req.MerchantId).Scan(&merchantWalletId)
+	if err == sql.ErrNoRows {
+		log.Error().Msg("invalid merchant")
+		return userDto.MerchantTransactionResponse{}, errors.New("invalid merchant")
 	}
-
-	return userDto.WalletTransactionResponse{TransactionId: transactionID}, storedPin, nil
-}
-
-func (repo *userRepository) CreateMerchantTransaction(req userDto.MerchantTransactionRequest) (string, error) {
-	tx, err := repo.db.Begin()
 	if err != nil {
-		return "", err
+		log.Error().Msg("failed to check merchant")
+		return userDto.MerchantTransactionResponse{}, errors.New("failed to check merchant")
 	}
 
-	var validMerchant bool
-	checkMerchantQuery := `
-      SELECT EXISTS (
-            SELECT 1
-            FROM merchant
-            WHERE id = $1
-      )
-   `
-	err = tx.QueryRow(checkMerchantQuery, req.MerchantId).Scan(&validMerchant)
+	var walletId string
+	var walletStatus string
+	getWalletIdQuery := `SELECT id, status FROM wallets WHERE user_id = $1 AND is_main`
+	err = tx.QueryRow(getWalletIdQuery, req.UserId).Scan(&walletId, &walletStatus)
 	if err != nil {
-		tx.Rollback()
-		log.Error().Msg("payment method not registered")
-		return "", errors.New("payment method not registered")
+		log.Error().Msg("wallet not found")
+		return userDto.MerchantTransactionResponse{}, ledger.ErrWalletNotFound
+	}
+	if !ledger.CanMove(walletStatus, ledger.EntryDebit) {
+		return userDto.MerchantTransactionResponse{}, ledger.ErrWalletFrozen
 	}
 
-	if !validMerchant {
-		tx.Rollback()
-		log.Error().Msg("invalid merchant")
-		return "", errors.New("invalid merchant")
+	fee, err := fees.Quote(tx, limits.TypeMerchantPayment, "", req.Amount)
+	if err != nil {
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	var currentBalance float64
-	checkBalanceQuery := `
-      SELECT balance
-      FROM wallets
-      WHERE user_id = $1
-   `
-	err = tx.QueryRow(checkBalanceQuery, req.UserId).Scan(&currentBalance)
+	balances, err := ledger.LockWallets(tx, append([]string{walletId, merchantWalletId}, fees.WalletIds(fee)...)...)
 	if err != nil {
-		tx.Rollback()
-		return "", err
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	if currentBalance < req.Amount {
-		tx.Rollback()
+	if balances[walletId] < req.Amount+fee {
 		log.Error().Msg("insufficient balance")
-		return "", errors.New("insufficient balance")
+		return userDto.MerchantTransactionResponse{}, ledger.ErrInsufficientBalance
+	}
+
+	if err := limits.Check(tx, req.UserId, limits.TypeMerchantPayment, req.Amount); err != nil {
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
 	transactionQuery := `
-      INSERT INTO transactions (user_id, transaction_type, amount, description, created_at, status)
-      VALUES ($1, 'debit', $2, $3, $4, 'success')
-      RETURNING id
-   `
+		INSERT INTO transactions (user_id, transaction_type, amount, fee, description, created_at, status)
+		VALUES ($1, 'debit', $2, $3, $4, $5, 'success')
+		RETURNING id
+	`
 	var transactionID string
-	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, req.Description, time.Now()).Scan(&transactionID)
+	err = tx.QueryRow(transactionQuery, req.UserId, req.Amount, fee, req.Description, time.Now()).Scan(&transactionID)
 	if err != nil {
-		tx.Rollback()
-		return "", err
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
+	var billReference sql.NullString
+	if req.BillReference != "" {
+		billReference = sql.NullString{String: req.BillReference, Valid: true}
+	}
 	merchantTransactionQuery := `
-      INSERT INTO merchant_transactions (transaction_id, merchant_id, created_at)
-      VALUES ($1, $2, $3)
-   `
-	_, err = tx.Exec(merchantTransactionQuery, transactionID, req.MerchantId, time.Now())
-	if err != nil {
-		tx.Rollback()
-		return "", err
+		INSERT INTO merchant_transactions (transaction_id, merchant_id, bill_reference, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err = tx.Exec(merchantTransactionQuery, transactionID, req.MerchantId, billReference, time.Now())
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		log.Error().Msg("bill " + req.BillReference + " already paid")
+		return userDto.MerchantTransactionResponse{}, user.ErrBillAlreadyPaid
 	}
-
-	updateBalanceQuery := `
-      UPDATE wallets
-      SET balance = balance - $1
-      WHERE user_id = $2
-   `
-	_, err = tx.Exec(updateBalanceQuery, req.Amount, req.UserId)
 	if err != nil {
-		tx.Rollback()
-		return "", err
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return "", err
+	entries := append([]ledger.Entry{
+		ledger.Debit(ledger.AccountWallet, walletId, req.Amount+fee),
+		ledger.Credit(ledger.AccountWallet, merchantWalletId, req.Amount),
+	}, fees.Entries(fee)...)
+	if err := ledger.Post(tx, transactionID, entries...); err != nil {
+		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	return transactionID, nil
+	return userDto.MerchantTransactionResponse{TransactionId: transactionID, Fee: fee}, nil
 }
 
 func (repo *userRepository) DeleteUser(id string) error {
@@ -719,3 +806,17 @@ func (repo *userRepository) DeleteUser(id string) error {
 
 	return nil
 }
+
+func (repo *userRepository) GetFeeQuote(params userDto.FeeQuoteParams) (userDto.FeeQuoteResponse, error) {
+	fee, err := fees.Quote(repo.db, params.TransactionType, params.PaymentMethodId, params.Amount)
+	if err != nil {
+		return userDto.FeeQuoteResponse{}, err
+	}
+
+	return userDto.FeeQuoteResponse{
+		TransactionType: params.TransactionType,
+		Amount:          params.Amount,
+		Fee:             fee,
+		Total:           params.Amount + fee,
+	}, nil
+}
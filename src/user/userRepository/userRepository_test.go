@@ -179,12 +179,17 @@ func TestGetBalanceInfoRepo(t *testing.T) {
 	userId := "1"
 	expectedBalance := 100.0
 
-	mock.ExpectQuery("SELECT balance FROM wallets WHERE user_id = \\$1 AND deleted_at IS NULL").
+	mock.ExpectQuery("SELECT id, name, is_main, currency, balance, created_at FROM wallets WHERE user_id = \\$1 AND deleted_at IS NULL").
 		WithArgs(userId).
-		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(fmt.Sprintf("%.2f", expectedBalance)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "is_main", "currency", "balance", "created_at"}).
+			AddRow("w1", "Main", true, "IDR", fmt.Sprintf("%.2f", 60.0), time.Now()).
+			AddRow("w2", "Savings", false, "IDR", fmt.Sprintf("%.2f", 40.0), time.Now()).
+			AddRow("w3", "Trip", false, "USD", fmt.Sprintf("%.2f", 500.0), time.Now()))
 
 	resp, err := repo.GetBalanceInfoRepo(userId)
 	assert.NoError(t, err)
+	assert.Len(t, resp.Pockets, 3)
+	assert.True(t, resp.Pockets[0].IsMain)
 
 	// Convert the balance from string to float64
 	balance, err := strconv.ParseFloat(resp.Balance, 64)
@@ -224,12 +229,12 @@ func TestGetTransactionRepo(t *testing.T) {
 		Status:          "success",
 	}
 
-	mock.ExpectQuery("SELECT id, amount, description, created_at, status FROM \\(SELECT t.id, t.amount, t.description, t.created_at, t.status FROM transactions t WHERE t.user_id = \\$1 UNION SELECT t.id, t.amount, t.description, t.created_at, t.status FROM transactions t JOIN wallet_transactions wt ON t.id = wt.transaction_id JOIN wallets w ON wt.from_wallet_id = w.id OR wt.to_wallet_id = w.id WHERE w.user_id = \\$1\\) sub WHERE 1=1 LIMIT 10 OFFSET 0").
+	mock.ExpectQuery("SELECT id, amount, currency, description, created_at, status FROM \\(SELECT t.id, t.amount, t.currency, t.description, t.created_at, t.status FROM transactions t WHERE t.user_id = \\$1 UNION SELECT t.id, t.amount, t.currency, t.description, t.created_at, t.status FROM transactions t JOIN wallet_transactions wt ON t.id = wt.transaction_id JOIN wallets w ON wt.from_wallet_id = w.id OR wt.to_wallet_id = w.id WHERE w.user_id = \\$1\\) sub WHERE 1=1 LIMIT 10 OFFSET 0").
 		WithArgs(params.UserId).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "description", "created_at", "status"}).
-			AddRow(expectedTransaction.TransactionId, expectedTransaction.Amount, expectedTransaction.Description, expectedTransaction.TransactionDate, expectedTransaction.Status))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "currency", "description", "created_at", "status"}).
+			AddRow(expectedTransaction.TransactionId, expectedTransaction.Amount, "IDR", expectedTransaction.Description, expectedTransaction.TransactionDate, expectedTransaction.Status))
 
-	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \\(SELECT t.id, t.amount, t.description, t.created_at, t.status FROM transactions t WHERE t.user_id = \\$1 UNION SELECT t.id, t.amount, t.description, t.created_at, t.status FROM transactions t JOIN wallet_transactions wt ON t.id = wt.transaction_id JOIN wallets w ON wt.from_wallet_id = w.id OR wt.to_wallet_id = w.id WHERE w.user_id = \\$1\\) sub WHERE 1=1").
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \\(SELECT t.id, t.amount, t.currency, t.description, t.created_at, t.status FROM transactions t WHERE t.user_id = \\$1 UNION SELECT t.id, t.amount, t.currency, t.description, t.created_at, t.status FROM transactions t JOIN wallet_transactions wt ON t.id = wt.transaction_id JOIN wallets w ON wt.from_wallet_id = w.id OR wt.to_wallet_id = w.id WHERE w.user_id = \\$1\\) sub WHERE 1=1").
 		WithArgs(params.UserId).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
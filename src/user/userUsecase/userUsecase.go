@@ -1,22 +1,25 @@
 package userUsecase
 
 import (
-	"errors"
 	"final-project-enigma/model/dto/userDto"
-	"final-project-enigma/pkg/helper/hashingPassword"
+	"final-project-enigma/pkg/limits"
 	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/money"
+	"final-project-enigma/pkg/paymentGateway"
+	"final-project-enigma/pkg/qris"
+	"final-project-enigma/pkg/txauth"
 	"final-project-enigma/src/user"
 	"strconv"
-
-	"github.com/rs/zerolog/log"
 )
 
 type userUC struct {
-	userRepo user.UserRepository
+	userRepo     user.UserRepository
+	txAuthorizer txauth.Authorizer
+	gateway      paymentGateway.PaymentGateway
 }
 
-func NewUserUsecase(userRepo user.UserRepository) user.UserUsecase {
-	return &userUC{userRepo}
+func NewUserUsecase(userRepo user.UserRepository, txAuthorizer txauth.Authorizer, gateway paymentGateway.PaymentGateway) user.UserUsecase {
+	return &userUC{userRepo, txAuthorizer, gateway}
 }
 
 func (usecase *userUC) EditDataUserUC(authHeader string, req userDto.UserUpdateReq) error {
@@ -118,6 +121,9 @@ func (usecase *userUC) GetTransactionUC(authHeader string, params userDto.GetTra
 			transaction.TransactionType = "credit"
 		} else if transaction.Detail.PaymentMethod != "" {
 			transaction.TransactionType = "credit"
+		} else if transaction.Detail.MerchantName != "" && transaction.Detail.ReversalOf != "" {
+			// A reversed merchant payment refunds the payer.
+			transaction.TransactionType = "credit"
 		} else if transaction.Detail.MerchantName != "" {
 			transaction.TransactionType = "debit"
 		} else {
@@ -142,112 +148,63 @@ func (usecase *userUC) GetTransactionUC(authHeader string, params userDto.GetTra
 	return manipulatedTransactions, totalDataStr, nil
 }
 
-func (usecase *userUC) TopUpTransaction(req userDto.TopUpTransactionRequest, authHeader string) (userDto.MidtransSnapResp, error) {
+func (usecase *userUC) TopUpTransaction(req userDto.TopUpTransactionRequest, authHeader string) (userDto.TopUpCheckoutResponse, error) {
 	userId, err := middleware.GetIdFromToken(authHeader)
 	if err != nil {
-		return userDto.MidtransSnapResp{}, err
+		return userDto.TopUpCheckoutResponse{}, err
 	}
 
 	req.UserId = userId
 	req.Description = "Balance Top Up"
 
-	transactionId, err := usecase.userRepo.CreateTopUpTransaction(req)
+	topUp, err := usecase.userRepo.CreateTopUpTransaction(req)
 	if err != nil {
-		return userDto.MidtransSnapResp{}, err
+		return userDto.TopUpCheckoutResponse{}, err
 	}
+	transactionId := topUp.TransactionId
 
-	methodName, err := usecase.userRepo.GetPaymentMethodName(req.PaymentMethodId)
+	method, err := usecase.userRepo.GetPaymentMethod(req.PaymentMethodId)
 	if err != nil {
-		return userDto.MidtransSnapResp{}, err
+		return userDto.TopUpCheckoutResponse{}, err
 	}
 	userFullname, err := usecase.userRepo.GetUserFullname(req.UserId)
 	if err != nil {
-		return userDto.MidtransSnapResp{}, err
+		return userDto.TopUpCheckoutResponse{}, err
 	}
-	var request userDto.MidtransSnapReq
-	request.TransactionDetail.OrderID = transactionId
-	request.TransactionDetail.GrossAmt = req.Amount
-
-	request.PaymentType = methodName
-
-	request.Customer = userFullname
 
-	items := []userDto.Item{
+	items := []paymentGateway.Item{
 		{
-			ID:       "usertopup",
+			Id:       "usertopup",
 			Name:     "TopUp Balance",
 			Price:    req.Amount,
 			Quantity: 1,
 		},
 	}
-
-	request.Items = items
-
-	resp, err := usecase.userRepo.PaymentGateway(request)
-
-	qrisPath := "#/other-qris"
-	bcaVaPath := "#/bank-transfer/bca-va"
-	mandiriVaPath := "#/bank-transfer/mandiri-va"
-	bniVaPath := "#/bank-transfer/bni-va"
-	briVaPath := "#/bank-transfer/bri-va"
-	permataVaPath := "#/bank-transfer/permata-va"
-	cimbnVaPath := "#/bank-transfer/cimb-va"
-	gopayPath := "#/gopay-qris"
-	debitCreditCardPath := "#/credit-card"
-	spaySpayLaterPath := "#/shopeepay-qris"
-	alfamartPath := "#/alfamart"
-	indomaretPath := "#/indomaret"
-	akulakuPath := "#/akulaku"
-	kredivoPath := "#/kredivo"
-
-	if req.PaymentMethodId == "089e8004-2428-41f9-bf06-856082bb83d3" {
-		resp.RedirectUrl += qrisPath
-	}
-	if req.PaymentMethodId == "cf51fa64-1686-4fee-a4e1-ea13c939f99b" {
-		resp.RedirectUrl += bcaVaPath
-	}
-	if req.PaymentMethodId == "087f9751-1dfc-474d-bdee-07ce44b1fe7a" {
-		resp.RedirectUrl += mandiriVaPath
-	}
-	if req.PaymentMethodId == "2bed0329-499e-43b5-9b99-583b203ea102" {
-		resp.RedirectUrl += bniVaPath
-	}
-	if req.PaymentMethodId == "3863b99e-9909-486c-8ec1-b7a3162c9f97" {
-		resp.RedirectUrl += briVaPath
-	}
-	if req.PaymentMethodId == "76954351-6cb3-496d-8866-d7f5772a04fe" {
-		resp.RedirectUrl += permataVaPath
-	}
-	if req.PaymentMethodId == "0fafc78f-ebbf-421d-bc89-3246ce6198ad" {
-		resp.RedirectUrl += cimbnVaPath
-	}
-	if req.PaymentMethodId == "f9569b06-a389-4685-b3cc-89b13a111214" {
-		resp.RedirectUrl += gopayPath
-	}
-	if req.PaymentMethodId == "9fa520e0-d10b-4be1-a6d7-e8b6fc635c5c" {
-		resp.RedirectUrl += debitCreditCardPath
-	}
-	if req.PaymentMethodId == "91b75dee-155e-4ac3-9bfd-f8bed82b6189" {
-		resp.RedirectUrl += spaySpayLaterPath
-	}
-	if req.PaymentMethodId == "b25a226e-82ab-4d29-a68e-6957fb7e21a9" {
-		resp.RedirectUrl += alfamartPath
-	}
-	if req.PaymentMethodId == "0eaad501-e44d-46e2-902a-9325c6c6c5eb" {
-		resp.RedirectUrl += indomaretPath
-	}
-	if req.PaymentMethodId == "29690f9f-c6c4-4fda-acac-be91555b1f94" {
-		resp.RedirectUrl += akulakuPath
+	if topUp.Fee > 0 {
+		items = append(items, paymentGateway.Item{
+			Id:       "topupfee",
+			Name:     "TopUp Fee",
+			Price:    topUp.Fee,
+			Quantity: 1,
+		})
 	}
-	if req.PaymentMethodId == "220309af-cd3b-40e5-b353-6754c66f3831" {
-		resp.RedirectUrl += kredivoPath
+
+	checkout, err := usecase.gateway.CreateCheckout(paymentGateway.CheckoutRequest{
+		OrderId:     transactionId,
+		GrossAmount: req.Amount + topUp.Fee,
+		Customer:    userFullname,
+		Items:       items,
+		Method:      method,
+	})
+	if err != nil {
+		return userDto.TopUpCheckoutResponse{}, err
 	}
 
-	if err := usecase.userRepo.InsertPaymentURL(transactionId, resp.RedirectUrl); err != nil {
-		return userDto.MidtransSnapResp{}, err
+	if err := usecase.userRepo.InsertPaymentURL(transactionId, checkout.RedirectUrl); err != nil {
+		return userDto.TopUpCheckoutResponse{}, err
 	}
 
-	return resp, err
+	return userDto.TopUpCheckoutResponse{Token: checkout.Token, RedirectUrl: checkout.RedirectUrl}, nil
 }
 
 func (usecase *userUC) WalletTransaction(req userDto.WalletTransactionRequest, authHeader string) (userDto.WalletTransactionResponse, error) {
@@ -257,17 +214,11 @@ func (usecase *userUC) WalletTransaction(req userDto.WalletTransactionRequest, a
 	}
 	req.UserId = fromId
 
-	resp, storedPin, err := usecase.userRepo.CreateWalletTransaction(req)
-	if err != nil {
+	if err := usecase.txAuthorizer.Authorize(req.UserId, req.PIN); err != nil {
 		return userDto.WalletTransactionResponse{}, err
 	}
 
-	err = hashingPassword.ComparePassword(storedPin, req.PIN)
-	if err != nil {
-		log.Error().Msg("invalid PIN")
-		return userDto.WalletTransactionResponse{}, errors.New("invalid PIN")
-	}
-	return resp, nil
+	return usecase.userRepo.CreateWalletTransaction(req)
 }
 
 func (usecase *userUC) DeleteUser(authHeader string) error {
@@ -287,12 +238,60 @@ func (usecase *userUC) MerchantTransaction(req userDto.MerchantTransactionReques
 	req.UserId = userId
 	req.Description = "Merchant-Payment"
 
-	transactionId, err := usecase.userRepo.CreateMerchantTransaction(req)
+	if err := usecase.txAuthorizer.Authorize(req.UserId, req.PIN); err != nil {
+		return userDto.MerchantTransactionResponse{}, err
+	}
+
+	return usecase.userRepo.CreateMerchantTransaction(req)
+}
+
+// QRPayment pays the merchant in a scanned QR through the same path as
+// MerchantTransaction. A dynamic QR fixes the amount and its bill number, so
+// the same bill cannot be paid twice.
+func (usecase *userUC) QRPayment(req userDto.QRPaymentRequest, authHeader string) (userDto.MerchantTransactionResponse, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
 	if err != nil {
 		return userDto.MerchantTransactionResponse{}, err
 	}
 
-	resp.TransactionId = transactionId
+	payload, err := qris.Decode(req.Payload)
+	if err != nil {
+		return userDto.MerchantTransactionResponse{}, err
+	}
 
-	return resp, nil
+	amount := req.Amount
+	if payload.IsDynamic() {
+		if amount != 0 && amount != payload.Amount {
+			return userDto.MerchantTransactionResponse{}, user.ErrAmountMismatch
+		}
+		amount = payload.Amount
+	}
+	if amount < user.MinMerchantPaymentAmount {
+		return userDto.MerchantTransactionResponse{}, money.ErrInvalidAmount
+	}
+
+	if err := usecase.txAuthorizer.Authorize(userId, req.PIN); err != nil {
+		return userDto.MerchantTransactionResponse{}, err
+	}
+
+	return usecase.userRepo.CreateMerchantTransaction(userDto.MerchantTransactionRequest{
+		UserId:        userId,
+		Amount:        amount,
+		Description:   "QR-Payment",
+		MerchantId:    payload.MerchantId,
+		BillReference: payload.BillNumber,
+	})
+}
+
+func (usecase *userUC) FeeQuoteUC(params userDto.FeeQuoteParams) (userDto.FeeQuoteResponse, error) {
+	switch params.TransactionType {
+	case limits.TypeTopUp, limits.TypeTransfer, limits.TypeMerchantPayment, limits.TypeWithdrawal:
+	default:
+		return userDto.FeeQuoteResponse{}, limits.ErrUnknownType
+	}
+	if !params.Amount.IsPositive() {
+		return userDto.FeeQuoteResponse{}, money.ErrInvalidAmount
+	}
+
+	return usecase.userRepo.GetFeeQuote(params)
 }
@@ -0,0 +1,57 @@
+package userUsecase_test
+
+import (
+	"final-project-enigma/model/dto/userDto"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/user"
+	"final-project-enigma/src/user/userUsecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUserRepo struct {
+	user.UserRepository
+	transactions []userDto.GetTransactionResponse
+}
+
+func (m *mockUserRepo) GetTransactionRepo(params userDto.GetTransactionParams) ([]userDto.GetTransactionResponse, int, error) {
+	return m.transactions, len(m.transactions), nil
+}
+
+func authHeader(t *testing.T) string {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", "USER", 1)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestGetTransactionUC_Direction(t *testing.T) {
+	repo := &mockUserRepo{transactions: []userDto.GetTransactionResponse{
+		{TransactionId: "topup", Detail: userDto.TransactionDetail{PaymentMethod: "BCA Virtual Account"}},
+		{TransactionId: "sent", Detail: userDto.TransactionDetail{SenderId: "user-1", RecipientId: "user-2"}},
+		{TransactionId: "received", Detail: userDto.TransactionDetail{SenderId: "user-2", RecipientId: "user-1"}},
+		{TransactionId: "purchase", Detail: userDto.TransactionDetail{MerchantName: "Warung Budi"}},
+		{TransactionId: "refund", Detail: userDto.TransactionDetail{MerchantName: "Warung Budi", ReversalOf: "purchase"}},
+	}}
+	uc := userUsecase.NewUserUsecase(repo, nil, nil)
+
+	resp, total, err := uc.GetTransactionUC(authHeader(t), userDto.GetTransactionParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", total)
+
+	types := map[string]string{}
+	for _, transaction := range resp {
+		types[transaction.TransactionId] = transaction.TransactionType
+	}
+	assert.Equal(t, map[string]string{
+		"topup":    "credit",
+		"sent":     "debit",
+		"received": "credit",
+		"purchase": "debit",
+		"refund":   "credit",
+	}, types)
+
+	resp, total, err = uc.GetTransactionUC(authHeader(t), userDto.GetTransactionParams{TrxType: "credit"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", total)
+}
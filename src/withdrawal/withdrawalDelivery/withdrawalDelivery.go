@@ -0,0 +1,224 @@
+package withdrawalDelivery
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/json"
+	"final-project-enigma/model/dto/withdrawalDto"
+	"final-project-enigma/pkg/disbursement"
+	"final-project-enigma/pkg/idempotency"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/pkg/validation"
+	"final-project-enigma/src/withdrawal"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+type withdrawalDelivery struct {
+	withdrawalUC withdrawal.WithdrawalUsecase
+	idempotency  gin.HandlerFunc
+}
+
+func NewWithdrawalDelivery(v1Group *gin.RouterGroup, withdrawalUC withdrawal.WithdrawalUsecase, idempotencyStore idempotency.Store) {
+	handler := withdrawalDelivery{
+		withdrawalUC: withdrawalUC,
+		idempotency:  middleware.Idempotency(idempotencyStore, middleware.IdempotencyRetention()),
+	}
+
+	bankAccountGroup := v1Group.Group("/user/bank-accounts")
+	{
+		bankAccountGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.createBankAccount)
+		bankAccountGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getBankAccounts)
+		bankAccountGroup.DELETE("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.deleteBankAccount)
+	}
+
+	withdrawalGroup := v1Group.Group("/user/withdrawals")
+	{
+		withdrawalGroup.POST("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.idempotency, handler.create)
+		withdrawalGroup.GET("", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getAll)
+		withdrawalGroup.GET("/:id", middleware.JwtAuthWithRoles("USER", "MERCHANT"), handler.getById)
+	}
+
+	adminGroup := v1Group.Group("/admin/withdrawals")
+	{
+		adminGroup.GET("/unsent", middleware.JwtAuthWithRoles("ADMIN"), handler.getUnsent)
+		adminGroup.PUT("/:id", middleware.JwtAuthWithRoles("ADMIN"), handler.resolve)
+	}
+
+	v1Group.POST("/payment/status/disbursement", handler.callback)
+}
+
+func (w *withdrawalDelivery) createBankAccount(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req withdrawalDto.CreateBankAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := w.withdrawalUC.CreateBankAccountUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Bank account registered", "01", "01")
+}
+
+func (w *withdrawalDelivery) getBankAccounts(ctx *gin.Context) {
+	resp, err := w.withdrawalUC.GetBankAccountsUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get bank accounts", "01", "01")
+}
+
+func (w *withdrawalDelivery) deleteBankAccount(ctx *gin.Context) {
+	if err := w.withdrawalUC.DeleteBankAccountUC(ctx.Param("id"), ctx.GetHeader("Authorization")); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Bank account deleted", "01", "01")
+}
+
+func (w *withdrawalDelivery) create(ctx *gin.Context) {
+	authHeader := ctx.GetHeader("Authorization")
+	var req withdrawalDto.CreateWithdrawalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	resp, err := w.withdrawalUC.CreateUC(req, authHeader)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Withdrawal requested", "01", "01")
+}
+
+func (w *withdrawalDelivery) getAll(ctx *gin.Context) {
+	resp, err := w.withdrawalUC.GetAllUC(ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get withdrawals", "01", "01")
+}
+
+func (w *withdrawalDelivery) getById(ctx *gin.Context) {
+	resp, err := w.withdrawalUC.GetByIdUC(ctx.Param("id"), ctx.GetHeader("Authorization"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get withdrawal", "01", "01")
+}
+
+func (w *withdrawalDelivery) getUnsent(ctx *gin.Context) {
+	resp, err := w.withdrawalUC.GetUnsentUC()
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, resp, "Success get unsent withdrawals", "01", "01")
+}
+
+// resolve lets an admin finish a withdrawal whose callback never came, after
+// checking the payout with the provider.
+func (w *withdrawalDelivery) resolve(ctx *gin.Context) {
+	var req withdrawalDto.ResolveWithdrawalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		validationError := validation.GetValidationError(err)
+		if len(validationError) > 0 {
+			json.NewResponBadRequest(ctx, validationError, "bad request", "01", "02")
+			return
+		}
+		json.NewResponseError(ctx, "json request body required", "01", "02")
+		return
+	}
+
+	if err := w.withdrawalUC.ResolveUC(ctx.Param("id"), req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Withdrawal resolved", "01", "01")
+}
+
+// callback receives payout status notifications. The provider signs the raw
+// body, so it is read as is instead of being bound.
+func (w *withdrawalDelivery) callback(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		json.NewResponseError(ctx, "failed to read request body", "01", "02")
+		return
+	}
+
+	if err := w.withdrawalUC.CallbackUC(ctx.Request.Header, body); err != nil {
+		switch {
+		case errors.Is(err, disbursement.ErrInvalidSignature):
+			json.NewResponseUnauthorized(ctx, err.Error(), "01", "02")
+		case errors.Is(err, disbursement.ErrInvalidCallback):
+			json.NewResponseError(ctx, err.Error(), "01", "02")
+		case errors.Is(err, withdrawal.ErrWithdrawalNotFound):
+			json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+		case errors.Is(err, withdrawal.ErrWithdrawalClosed):
+			json.NewResponseConflict(ctx, err.Error(), "01", "09")
+		default:
+			json.NewResponseError(ctx, err.Error(), "01", "01")
+		}
+		return
+	}
+
+	json.NewResponSucces(ctx, nil, "Disbursement status updated", "01", "01")
+}
+
+func errorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, withdrawal.ErrWithdrawalNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "02")
+	case errors.Is(err, withdrawal.ErrBankAccountNotFound):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrInvalidPin):
+		json.NewResponseUnauthorized(ctx, err.Error(), "01", "03")
+	case errors.Is(err, txauth.ErrPinLocked):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "04")
+	case errors.Is(err, limits.ErrPerTransactionLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "05")
+	case errors.Is(err, limits.ErrDailyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "06")
+	case errors.Is(err, limits.ErrMonthlyLimit):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "07")
+	case errors.Is(err, ledger.ErrInsufficientBalance):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "08")
+	case errors.Is(err, ledger.ErrWalletFrozen):
+		json.NewResponseForbidden(ctx, err.Error(), "01", "10")
+	case errors.Is(err, withdrawal.ErrWithdrawalClosed):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	case errors.Is(err, withdrawal.ErrDuplicateBankAccount):
+		json.NewResponseConflict(ctx, err.Error(), "01", "09")
+	default:
+		json.NewResponseError(ctx, err.Error(), "01", "01")
+	}
+}
@@ -0,0 +1,40 @@
+package withdrawal
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/withdrawalDto"
+	"net/http"
+)
+
+var (
+	ErrBankAccountNotFound  = errors.New("bank account not found")
+	ErrDuplicateBankAccount = errors.New("bank account is already registered")
+	ErrWithdrawalNotFound   = errors.New("withdrawal not found")
+	ErrWithdrawalClosed     = errors.New("withdrawal is already completed or failed")
+)
+
+type WithdrawalRepository interface {
+	CreateBankAccount(req withdrawalDto.CreateBankAccountRequest) (withdrawalDto.BankAccount, error)
+	GetBankAccounts(userId string) ([]withdrawalDto.BankAccount, error)
+	DeleteBankAccount(userId, id string) error
+	Create(req withdrawalDto.CreateWithdrawalRequest) (withdrawalDto.Withdrawal, error)
+	GetByUser(userId string) ([]withdrawalDto.Withdrawal, error)
+	GetById(userId, id string) (withdrawalDto.Withdrawal, error)
+	GetUnsent() ([]withdrawalDto.Withdrawal, error)
+	GetIdByReference(provider, referenceNo string) (string, error)
+	MarkProcessing(id, referenceNo string) error
+	Complete(id string) error
+	Release(id, reason string) error
+}
+
+type WithdrawalUsecase interface {
+	CreateBankAccountUC(req withdrawalDto.CreateBankAccountRequest, authHeader string) (withdrawalDto.BankAccount, error)
+	GetBankAccountsUC(authHeader string) ([]withdrawalDto.BankAccount, error)
+	DeleteBankAccountUC(id, authHeader string) error
+	CreateUC(req withdrawalDto.CreateWithdrawalRequest, authHeader string) (withdrawalDto.Withdrawal, error)
+	GetAllUC(authHeader string) ([]withdrawalDto.Withdrawal, error)
+	GetByIdUC(id, authHeader string) (withdrawalDto.Withdrawal, error)
+	CallbackUC(header http.Header, body []byte) error
+	GetUnsentUC() ([]withdrawalDto.Withdrawal, error)
+	ResolveUC(id string, req withdrawalDto.ResolveWithdrawalRequest) error
+}
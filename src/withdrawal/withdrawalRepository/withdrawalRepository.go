@@ -0,0 +1,379 @@
+package withdrawalRepository
+
+import (
+	"database/sql"
+	"errors"
+	"final-project-enigma/model/dto/withdrawalDto"
+	"final-project-enigma/pkg/dbtx"
+	"final-project-enigma/pkg/fees"
+	"final-project-enigma/pkg/ledger"
+	"final-project-enigma/pkg/limits"
+	"final-project-enigma/pkg/money"
+	withdrawalDomain "final-project-enigma/src/withdrawal"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+type withdrawalRepository struct {
+	db *sql.DB
+}
+
+func NewWithdrawalRepository(db *sql.DB) withdrawalDomain.WithdrawalRepository {
+	return &withdrawalRepository{
+		db: db,
+	}
+}
+
+const withdrawalColumns = `
+	w.id, w.transaction_id, b.id, b.bank_code, b.account_number, b.account_name, b.created_at,
+	w.amount, w.fee, w.status, COALESCE(w.reference_no, ''), COALESCE(w.failure_reason, ''), w.created_at, w.updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWithdrawal(row rowScanner) (withdrawalDto.Withdrawal, error) {
+	var w withdrawalDto.Withdrawal
+	err := row.Scan(&w.Id, &w.TransactionId, &w.BankAccount.Id, &w.BankAccount.BankCode, &w.BankAccount.AccountNumber,
+		&w.BankAccount.AccountName, &w.BankAccount.CreatedAt, &w.Amount, &w.Fee, &w.Status, &w.ReferenceNo,
+		&w.FailureReason, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+func (repo *withdrawalRepository) CreateBankAccount(req withdrawalDto.CreateBankAccountRequest) (withdrawalDto.BankAccount, error) {
+	resp := withdrawalDto.BankAccount{
+		BankCode:      req.BankCode,
+		AccountNumber: req.AccountNumber,
+		AccountName:   req.AccountName,
+	}
+	query := `
+		INSERT INTO bank_accounts (user_id, bank_code, account_number, account_name, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := repo.db.QueryRow(query, req.UserId, req.BankCode, req.AccountNumber, req.AccountName, time.Now()).Scan(&resp.Id, &resp.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return withdrawalDto.BankAccount{}, withdrawalDomain.ErrDuplicateBankAccount
+	}
+	if err != nil {
+		log.Error().Msg("failed to create bank account: " + err.Error())
+		return withdrawalDto.BankAccount{}, fmt.Errorf("failed to create bank account: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (repo *withdrawalRepository) GetBankAccounts(userId string) ([]withdrawalDto.BankAccount, error) {
+	query := `
+		SELECT id, bank_code, account_number, account_name, created_at
+		FROM bank_accounts
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+	rows, err := repo.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank accounts: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []withdrawalDto.BankAccount{}
+	for rows.Next() {
+		var account withdrawalDto.BankAccount
+		if err := rows.Scan(&account.Id, &account.BankCode, &account.AccountNumber, &account.AccountName, &account.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bank account: %w", err)
+		}
+		resp = append(resp, account)
+	}
+
+	return resp, rows.Err()
+}
+
+// DeleteBankAccount only hides the account; past withdrawals keep pointing
+// at it.
+func (repo *withdrawalRepository) DeleteBankAccount(userId, id string) error {
+	query := `UPDATE bank_accounts SET deleted_at = $1 WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL`
+	result, err := repo.db.Exec(query, time.Now(), id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete bank account: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return withdrawalDomain.ErrBankAccountNotFound
+	}
+
+	return nil
+}
+
+// Create records a pending withdrawal and moves its amount and fee from the
+// user's wallet into the withdrawal's hold account, so the funds cannot be
+// spent while the payout is in flight.
+func (repo *withdrawalRepository) Create(req withdrawalDto.CreateWithdrawalRequest) (withdrawalDto.Withdrawal, error) {
+	var id string
+	err := dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		var bankAccountExists bool
+		bankAccountQuery := `SELECT EXISTS (SELECT 1 FROM bank_accounts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`
+		if err := tx.QueryRow(bankAccountQuery, req.BankAccountId, req.UserId).Scan(&bankAccountExists); err != nil {
+			return fmt.Errorf("failed to check bank account: %w", err)
+		}
+		if !bankAccountExists {
+			return withdrawalDomain.ErrBankAccountNotFound
+		}
+
+		var walletId, walletStatus string
+		err := tx.QueryRow(`SELECT id, status FROM wallets WHERE user_id = $1 AND is_main`, req.UserId).Scan(&walletId, &walletStatus)
+		if err != nil {
+			log.Error().Msg("wallet not found")
+			return ledger.ErrWalletNotFound
+		}
+		if !ledger.CanMove(walletStatus, ledger.EntryDebit) {
+			return ledger.ErrWalletFrozen
+		}
+
+		balances, err := ledger.LockWallets(tx, walletId)
+		if err != nil {
+			return err
+		}
+
+		fee, err := fees.Quote(tx, limits.TypeWithdrawal, "", req.Amount)
+		if err != nil {
+			return err
+		}
+
+		if balances[walletId] < req.Amount+fee {
+			log.Error().Msg("insufficient balance")
+			return ledger.ErrInsufficientBalance
+		}
+
+		if err := limits.Check(tx, req.UserId, limits.TypeWithdrawal, req.Amount); err != nil {
+			return err
+		}
+
+		currentTime := time.Now()
+		var transactionId string
+		transactionQuery := `
+			INSERT INTO transactions (user_id, transaction_type, amount, fee, description, created_at, status)
+			VALUES ($1, 'debit', $2, $3, 'Withdrawal', $4, 'pending')
+			RETURNING id
+		`
+		if err := tx.QueryRow(transactionQuery, req.UserId, req.Amount, fee, currentTime).Scan(&transactionId); err != nil {
+			return err
+		}
+
+		withdrawalQuery := `
+			INSERT INTO withdrawals (transaction_id, user_id, bank_account_id, amount, fee, provider, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+			RETURNING id
+		`
+		err = tx.QueryRow(withdrawalQuery, transactionId, req.UserId, req.BankAccountId, req.Amount, fee, req.Provider, currentTime).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		return ledger.Post(tx, transactionId,
+			ledger.Debit(ledger.AccountWallet, walletId, req.Amount+fee),
+			ledger.Credit(ledger.AccountHold, id, req.Amount+fee),
+		)
+	})
+	if err != nil {
+		return withdrawalDto.Withdrawal{}, err
+	}
+
+	return repo.GetById(req.UserId, id)
+}
+
+func (repo *withdrawalRepository) GetByUser(userId string) ([]withdrawalDto.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + `
+		FROM withdrawals w
+		JOIN bank_accounts b ON b.id = w.bank_account_id
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC
+	`
+	rows, err := repo.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []withdrawalDto.Withdrawal{}
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal: %w", err)
+		}
+		resp = append(resp, withdrawal)
+	}
+
+	return resp, rows.Err()
+}
+
+func (repo *withdrawalRepository) GetById(userId, id string) (withdrawalDto.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + `
+		FROM withdrawals w
+		JOIN bank_accounts b ON b.id = w.bank_account_id
+		WHERE w.id = $1 AND w.user_id = $2
+	`
+	withdrawal, err := scanWithdrawal(repo.db.QueryRow(query, id, userId))
+	if err == sql.ErrNoRows {
+		return withdrawalDto.Withdrawal{}, withdrawalDomain.ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return withdrawalDto.Withdrawal{}, fmt.Errorf("failed to get withdrawal: %w", err)
+	}
+	return withdrawal, nil
+}
+
+// GetUnsent lists the withdrawals whose payout request got no answer, so
+// they hold funds without a provider reference, oldest first.
+func (repo *withdrawalRepository) GetUnsent() ([]withdrawalDto.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + `
+		FROM withdrawals w
+		JOIN bank_accounts b ON b.id = w.bank_account_id
+		WHERE w.status = 'pending' AND w.reference_no IS NULL
+		ORDER BY w.created_at
+	`
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	resp := []withdrawalDto.Withdrawal{}
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal: %w", err)
+		}
+		resp = append(resp, withdrawal)
+	}
+
+	return resp, rows.Err()
+}
+
+// GetIdByReference finds the withdrawal a callback is about. A withdrawal
+// whose payout request got no answer has no reference_no yet, so it is
+// matched on its own id, which is the reference we sent the provider.
+func (repo *withdrawalRepository) GetIdByReference(provider, referenceNo string) (string, error) {
+	var id string
+	query := `
+		SELECT id FROM withdrawals
+		WHERE provider = $1 AND (reference_no = $2 OR (reference_no IS NULL AND id::text = $2))
+	`
+	err := repo.db.QueryRow(query, provider, referenceNo).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", withdrawalDomain.ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get withdrawal: %w", err)
+	}
+	return id, nil
+}
+
+func (repo *withdrawalRepository) MarkProcessing(id, referenceNo string) error {
+	query := `
+		UPDATE withdrawals
+		SET reference_no = $1, status = 'processing', updated_at = $2
+		WHERE id = $3 AND status = 'pending'
+	`
+	if _, err := repo.db.Exec(query, referenceNo, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to update withdrawal: %w", err)
+	}
+	return nil
+}
+
+type heldWithdrawal struct {
+	transactionId string
+	userId        string
+	amount        money.Money
+	fee           money.Money
+	provider      string
+	status        string
+}
+
+// lockHeld locks withdrawal id. done is true when it already reached
+// wantStatus, so a repeated callback is a no-op.
+func lockHeld(tx *sql.Tx, id, wantStatus string) (held heldWithdrawal, done bool, err error) {
+	lockQuery := `SELECT transaction_id, user_id, amount, fee, provider, status FROM withdrawals WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRow(lockQuery, id).Scan(&held.transactionId, &held.userId, &held.amount, &held.fee, &held.provider, &held.status)
+	if err == sql.ErrNoRows {
+		return held, false, withdrawalDomain.ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return held, false, err
+	}
+	if held.status == wantStatus {
+		return held, true, nil
+	}
+	if held.status != withdrawalDto.StatusPending && held.status != withdrawalDto.StatusProcessing {
+		log.Error().Msg("withdrawal " + id + " is " + held.status + ", cannot mark it " + wantStatus)
+		return held, false, withdrawalDomain.ErrWithdrawalClosed
+	}
+	return held, false, nil
+}
+
+// Complete pays the held amount out to the provider and the fee to the
+// platform wallet.
+func (repo *withdrawalRepository) Complete(id string) error {
+	return dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		held, done, err := lockHeld(tx, id, withdrawalDto.StatusCompleted)
+		if err != nil || done {
+			return err
+		}
+
+		entries := append([]ledger.Entry{
+			ledger.Debit(ledger.AccountHold, id, held.amount+held.fee),
+			ledger.Credit(ledger.AccountDisbursement, held.provider, held.amount),
+		}, fees.Entries(held.fee)...)
+		if err := ledger.Post(tx, held.transactionId, entries...); err != nil {
+			return err
+		}
+
+		return finish(tx, id, held.transactionId, withdrawalDto.StatusCompleted, "success", "")
+	})
+}
+
+// Release returns the held amount and fee to the user's wallet, even one that
+// was frozen while the withdrawal was pending.
+func (repo *withdrawalRepository) Release(id, reason string) error {
+	return dbtx.WithRetry(repo.db, func(tx *sql.Tx) error {
+		held, done, err := lockHeld(tx, id, withdrawalDto.StatusFailed)
+		if err != nil || done {
+			return err
+		}
+
+		var walletId string
+		if err := tx.QueryRow(`SELECT id FROM wallets WHERE user_id = $1 AND is_main`, held.userId).Scan(&walletId); err != nil {
+			return ledger.ErrWalletNotFound
+		}
+
+		err = ledger.Post(tx, held.transactionId,
+			ledger.Debit(ledger.AccountHold, id, held.amount+held.fee),
+			ledger.CreditOwed(ledger.AccountWallet, walletId, held.amount+held.fee),
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+		return finish(tx, id, held.transactionId, withdrawalDto.StatusFailed, "failed", reason)
+	})
+}
+
+func finish(tx *sql.Tx, id, transactionId, status, transactionStatus, reason string) error {
+	var failureReason sql.NullString
+	if reason != "" {
+		failureReason = sql.NullString{String: reason, Valid: true}
+	}
+	withdrawalQuery := `UPDATE withdrawals SET status = $1, failure_reason = $2, updated_at = $3 WHERE id = $4`
+	if _, err := tx.Exec(withdrawalQuery, status, failureReason, time.Now(), id); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`UPDATE transactions SET status = $1 WHERE id = $2`, transactionStatus, transactionId)
+	return err
+}
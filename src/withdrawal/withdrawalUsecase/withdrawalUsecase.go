@@ -0,0 +1,155 @@
+package withdrawalUsecase
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/withdrawalDto"
+	"final-project-enigma/pkg/disbursement"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/pkg/txauth"
+	"final-project-enigma/src/withdrawal"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+type withdrawalUC struct {
+	withdrawalRepo withdrawal.WithdrawalRepository
+	txAuthorizer   txauth.Authorizer
+	provider       disbursement.Disbursement
+}
+
+func NewWithdrawalUsecase(withdrawalRepo withdrawal.WithdrawalRepository, txAuthorizer txauth.Authorizer, provider disbursement.Disbursement) withdrawal.WithdrawalUsecase {
+	return &withdrawalUC{
+		withdrawalRepo: withdrawalRepo,
+		txAuthorizer:   txAuthorizer,
+		provider:       provider,
+	}
+}
+
+func (usecase *withdrawalUC) CreateBankAccountUC(req withdrawalDto.CreateBankAccountRequest, authHeader string) (withdrawalDto.BankAccount, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return withdrawalDto.BankAccount{}, err
+	}
+	req.UserId = userId
+
+	return usecase.withdrawalRepo.CreateBankAccount(req)
+}
+
+func (usecase *withdrawalUC) GetBankAccountsUC(authHeader string) ([]withdrawalDto.BankAccount, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.withdrawalRepo.GetBankAccounts(userId)
+}
+
+func (usecase *withdrawalUC) DeleteBankAccountUC(id, authHeader string) error {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return err
+	}
+	return usecase.withdrawalRepo.DeleteBankAccount(userId, id)
+}
+
+// CreateUC holds the funds and then asks the provider for the payout. Only a
+// payout the provider explicitly rejected is released at once; on any other
+// error the outcome is unknown, so the funds stay held until a callback
+// referring to the withdrawal id arrives or an admin resolves it.
+func (usecase *withdrawalUC) CreateUC(req withdrawalDto.CreateWithdrawalRequest, authHeader string) (withdrawalDto.Withdrawal, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return withdrawalDto.Withdrawal{}, err
+	}
+	req.UserId = userId
+	req.Provider = usecase.provider.Name()
+
+	if err := usecase.txAuthorizer.Authorize(req.UserId, req.PIN); err != nil {
+		return withdrawalDto.Withdrawal{}, err
+	}
+
+	held, err := usecase.withdrawalRepo.Create(req)
+	if err != nil {
+		return withdrawalDto.Withdrawal{}, err
+	}
+
+	payout, err := usecase.provider.CreatePayout(disbursement.PayoutRequest{
+		Reference:     held.Id,
+		BankCode:      held.BankAccount.BankCode,
+		AccountNumber: held.BankAccount.AccountNumber,
+		AccountName:   held.BankAccount.AccountName,
+		Amount:        held.Amount,
+		Notes:         "Withdrawal " + held.Id,
+	})
+	switch {
+	case errors.Is(err, disbursement.ErrPayoutRejected):
+		log.Error().Msg("payout of withdrawal " + held.Id + " rejected: " + err.Error())
+		if err := usecase.withdrawalRepo.Release(held.Id, err.Error()); err != nil {
+			return withdrawalDto.Withdrawal{}, err
+		}
+	case err != nil:
+		log.Error().Msg("payout of withdrawal " + held.Id + " failed: " + err.Error())
+	default:
+		if err := usecase.withdrawalRepo.MarkProcessing(held.Id, payout.ReferenceNo); err != nil {
+			return withdrawalDto.Withdrawal{}, err
+		}
+	}
+
+	return usecase.withdrawalRepo.GetById(req.UserId, held.Id)
+}
+
+func (usecase *withdrawalUC) GetAllUC(authHeader string) ([]withdrawalDto.Withdrawal, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.withdrawalRepo.GetByUser(userId)
+}
+
+func (usecase *withdrawalUC) GetByIdUC(id, authHeader string) (withdrawalDto.Withdrawal, error) {
+	userId, err := middleware.GetIdFromToken(authHeader)
+	if err != nil {
+		return withdrawalDto.Withdrawal{}, err
+	}
+	return usecase.withdrawalRepo.GetById(userId, id)
+}
+
+// CallbackUC finalizes or releases a withdrawal from the provider's status
+// notification. Intermediate statuses are acknowledged and ignored.
+func (usecase *withdrawalUC) CallbackUC(header http.Header, body []byte) error {
+	callback, err := usecase.provider.ParseCallback(header, body)
+	if err != nil {
+		log.Error().Msg("invalid disbursement callback: " + err.Error())
+		return err
+	}
+
+	id, err := usecase.withdrawalRepo.GetIdByReference(usecase.provider.Name(), callback.ReferenceNo)
+	if err != nil {
+		return err
+	}
+
+	switch callback.Status {
+	case disbursement.StatusCompleted:
+		return usecase.withdrawalRepo.Complete(id)
+	case disbursement.StatusFailed:
+		return usecase.withdrawalRepo.Release(id, callback.Error)
+	default:
+		return nil
+	}
+}
+
+// GetUnsentUC lists the withdrawals still waiting for an answer to their
+// payout request, for an admin to check with the provider.
+func (usecase *withdrawalUC) GetUnsentUC() ([]withdrawalDto.Withdrawal, error) {
+	return usecase.withdrawalRepo.GetUnsent()
+}
+
+// ResolveUC completes or releases a withdrawal by hand, as its callback would
+// have.
+func (usecase *withdrawalUC) ResolveUC(id string, req withdrawalDto.ResolveWithdrawalRequest) error {
+	log.Info().Msg("withdrawal " + id + " resolved by hand as " + req.Status)
+	if req.Status == withdrawalDto.StatusCompleted {
+		return usecase.withdrawalRepo.Complete(id)
+	}
+	return usecase.withdrawalRepo.Release(id, req.Reason)
+}
@@ -0,0 +1,156 @@
+package withdrawalUsecase_test
+
+import (
+	"errors"
+	"final-project-enigma/model/dto/withdrawalDto"
+	"final-project-enigma/pkg/disbursement"
+	"final-project-enigma/pkg/middleware"
+	"final-project-enigma/src/withdrawal"
+	"final-project-enigma/src/withdrawal/withdrawalUsecase"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWithdrawalRepo struct {
+	withdrawal.WithdrawalRepository
+	status    string
+	reference string
+	released  string
+	completed bool
+}
+
+func (m *mockWithdrawalRepo) Create(req withdrawalDto.CreateWithdrawalRequest) (withdrawalDto.Withdrawal, error) {
+	m.status = withdrawalDto.StatusPending
+	return withdrawalDto.Withdrawal{
+		Id:          "w1",
+		BankAccount: withdrawalDto.BankAccount{Id: req.BankAccountId, BankCode: "bca", AccountNumber: "1234567890", AccountName: "Budi"},
+		Amount:      req.Amount,
+		Status:      m.status,
+	}, nil
+}
+
+func (m *mockWithdrawalRepo) GetById(userId, id string) (withdrawalDto.Withdrawal, error) {
+	return withdrawalDto.Withdrawal{Id: id, Status: m.status, ReferenceNo: m.reference}, nil
+}
+
+func (m *mockWithdrawalRepo) MarkProcessing(id, referenceNo string) error {
+	m.status = withdrawalDto.StatusProcessing
+	m.reference = referenceNo
+	return nil
+}
+
+func (m *mockWithdrawalRepo) Release(id, reason string) error {
+	m.status = withdrawalDto.StatusFailed
+	m.released = reason
+	return nil
+}
+
+func (m *mockWithdrawalRepo) Complete(id string) error {
+	m.status = withdrawalDto.StatusCompleted
+	m.completed = true
+	return nil
+}
+
+func (m *mockWithdrawalRepo) GetIdByReference(provider, referenceNo string) (string, error) {
+	if provider != disbursement.ProviderFake {
+		return "", withdrawal.ErrWithdrawalNotFound
+	}
+	if referenceNo == m.reference || (m.reference == "" && referenceNo == "w1") {
+		return "w1", nil
+	}
+	return "", withdrawal.ErrWithdrawalNotFound
+}
+
+type mockAuthorizer struct{}
+
+func (mockAuthorizer) Authorize(userId, pin string) error {
+	return nil
+}
+
+func authHeader(t *testing.T) string {
+	token, err := middleware.GenerateTokenJwt("user-1", "user", "USER", 1)
+	assert.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestCreateUC(t *testing.T) {
+	req := withdrawalDto.CreateWithdrawalRequest{BankAccountId: "b1", Amount: 100000, PIN: "123456"}
+
+	t.Run("queued payout is processing", func(t *testing.T) {
+		repo := &mockWithdrawalRepo{}
+		provider := disbursement.NewFake()
+		uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, provider)
+
+		resp, err := uc.CreateUC(req, authHeader(t))
+		assert.NoError(t, err)
+		assert.Equal(t, withdrawalDto.StatusProcessing, resp.Status)
+		assert.Equal(t, "fake-1", resp.ReferenceNo)
+		assert.Equal(t, "1234567890", provider.Payouts[0].AccountNumber)
+	})
+
+	t.Run("rejected payout releases the funds", func(t *testing.T) {
+		repo := &mockWithdrawalRepo{}
+		provider := disbursement.NewFake()
+		provider.Err = disbursement.ErrPayoutRejected
+		uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, provider)
+
+		resp, err := uc.CreateUC(req, authHeader(t))
+		assert.NoError(t, err)
+		assert.Equal(t, withdrawalDto.StatusFailed, resp.Status)
+		assert.Equal(t, disbursement.ErrPayoutRejected.Error(), repo.released)
+	})
+
+	t.Run("unknown outcome keeps the funds held", func(t *testing.T) {
+		repo := &mockWithdrawalRepo{}
+		provider := disbursement.NewFake()
+		provider.Err = errors.New("timeout")
+		uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, provider)
+
+		resp, err := uc.CreateUC(req, authHeader(t))
+		assert.NoError(t, err)
+		assert.Equal(t, withdrawalDto.StatusPending, resp.Status)
+		assert.Empty(t, repo.released)
+	})
+}
+
+func TestCallbackUC(t *testing.T) {
+	repo := &mockWithdrawalRepo{status: withdrawalDto.StatusProcessing, reference: "fake-1"}
+	uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, disbursement.NewFake())
+
+	assert.NoError(t, uc.CallbackUC(http.Header{}, []byte(`{"referenceNo":"fake-1","status":"processing"}`)))
+	assert.Equal(t, withdrawalDto.StatusProcessing, repo.status)
+
+	assert.NoError(t, uc.CallbackUC(http.Header{}, []byte(`{"referenceNo":"fake-1","status":"failed","error":"account closed"}`)))
+	assert.Equal(t, "account closed", repo.released)
+
+	err := uc.CallbackUC(http.Header{}, []byte(`{"referenceNo":"fake-2","status":"completed"}`))
+	assert.ErrorIs(t, err, withdrawal.ErrWithdrawalNotFound)
+	assert.False(t, repo.completed)
+
+	assert.ErrorIs(t, uc.CallbackUC(http.Header{}, []byte(`{}`)), disbursement.ErrInvalidCallback)
+}
+
+func TestCallbackUC_Unsent(t *testing.T) {
+	repo := &mockWithdrawalRepo{status: withdrawalDto.StatusPending}
+	uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, disbursement.NewFake())
+
+	assert.NoError(t, uc.CallbackUC(http.Header{}, []byte(`{"referenceNo":"w1","status":"completed"}`)))
+	assert.True(t, repo.completed)
+}
+
+func TestResolveUC(t *testing.T) {
+	repo := &mockWithdrawalRepo{status: withdrawalDto.StatusPending}
+	uc := withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, disbursement.NewFake())
+
+	assert.NoError(t, uc.ResolveUC("w1", withdrawalDto.ResolveWithdrawalRequest{Status: withdrawalDto.StatusFailed, Reason: "not received by the provider"}))
+	assert.Equal(t, "not received by the provider", repo.released)
+	assert.False(t, repo.completed)
+
+	repo = &mockWithdrawalRepo{status: withdrawalDto.StatusPending}
+	uc = withdrawalUsecase.NewWithdrawalUsecase(repo, mockAuthorizer{}, disbursement.NewFake())
+	assert.NoError(t, uc.ResolveUC("w1", withdrawalDto.ResolveWithdrawalRequest{Status: withdrawalDto.StatusCompleted}))
+	assert.True(t, repo.completed)
+	assert.Empty(t, repo.released)
+}